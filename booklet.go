@@ -0,0 +1,118 @@
+package sudoku
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// BookletOptions configures the PDF booklet WriteBooklet makes
+type BookletOptions struct {
+	Title      string  // Written at the top of the pages of puzzles, and as the document's title
+	PageWidth  float64 // Width of the pages in points, 72 to the inch, defaults to A4's
+	PageHeight float64 // Height of the pages in points, defaults to A4's
+}
+
+// Size of an A4 page in points
+const (
+	a4Width  = 595.28
+	a4Height = 841.89
+)
+
+// Room around the pages of a booklet, and for the header and the page number at the top and bottom of them, in points
+const (
+	bookletMargin = 36
+	bookletBand   = 24
+)
+
+// ErrNoPuzzles is returned by WriteBooklet when given no puzzles
+var ErrNoPuzzles = errors.New("sudoku: a booklet needs puzzles")
+
+// bookletPuzzle A puzzle of a booklet along with what is worked out of it
+type bookletPuzzle struct {
+	samurai    *SamuraiSudoku
+	solution   Grid
+	difficulty Difficulty
+}
+
+//WriteBooklet writes the puzzles as a PDF document to w, ready to print: two puzzles to a page, each under its number and
+//difficulty, followed by an answer key of their solutions, four to a page. Pages are numbered at the bottom.
+//The puzzles are rated by the techniques SolveLogically needs, their grids are left as they are.
+//It returns the errors of NewGame for puzzles without a unique solution, along with the puzzle's number, ErrNoPuzzles if there
+//are none, and ctx's error once ctx is done
+func WriteBooklet(ctx context.Context, w io.Writer, puzzles []*SamuraiSudoku, options BookletOptions) error {
+	if len(puzzles) == 0 {
+		return ErrNoPuzzles
+	}
+	booklet := make([]bookletPuzzle, len(puzzles))
+	for i, samurai := range puzzles {
+		solution, err := uniqueSolution(ctx, samurai)
+		if err != nil {
+			return fmt.Errorf("puzzle %d: %w", i+1, err)
+		}
+		logical, err := SolveLogically(ctx, samurai)
+		if err != nil {
+			return fmt.Errorf("puzzle %d: %w", i+1, err)
+		}
+		booklet[i] = bookletPuzzle{samurai: samurai, solution: solution, difficulty: logical.Difficulty()}
+	}
+
+	d := &pdfDocument{title: options.Title, width: options.PageWidth, height: options.PageHeight}
+	if d.width <= 0 {
+		d.width = a4Width
+	}
+	if d.height <= 0 {
+		d.height = a4Height
+	}
+	var page *pdfPage
+	for i, puzzle := range booklet {
+		if i%2 == 0 {
+			page = d.newPage()
+			if options.Title != "" {
+				page.text(point{d.width / 2, bookletMargin + 10}, options.Title, 10, false, imageInkColour, true)
+			}
+		}
+		top, left, width, height := d.slot(i%2, 0, 2, 1)
+		page.text(point{left, top + 11}, fmt.Sprintf("Puzzle %d", i+1), 11, true, imageInkColour, false)
+		difficulty := puzzle.difficulty.String()
+		page.text(point{left + textWidth(fmt.Sprintf("Puzzle %d  ", i+1), 11, true), top + 11},
+			strings.ToUpper(difficulty[:1])+difficulty[1:], 11, false, imageInkColour, false)
+		d.picture(page, puzzle.samurai, nil, top+18, left, width, height-18)
+	}
+	for i, puzzle := range booklet {
+		if i%4 == 0 {
+			page = d.newPage()
+			page.text(point{d.width / 2, bookletMargin + 10}, "Answers", 10, true, imageInkColour, true)
+		}
+		top, left, width, height := d.slot(i%4/2, i%2, 2, 2)
+		page.text(point{left, top + 9}, fmt.Sprintf("Puzzle %d", i+1), 9, true, imageInkColour, false)
+		d.picture(page, puzzle.samurai, puzzle.solution, top+14, left, width, height-14)
+	}
+	for i, page := range d.pages {
+		page.text(point{d.width / 2, d.height - bookletMargin - 4}, fmt.Sprint(i+1), 9, false, imageInkColour, true)
+	}
+	return d.write(w)
+}
+
+//slot returns the top left corner and size of the part of a page between its header and page number that is row y and column x
+//of rows by columns equal parts, a gap being left between them
+func (d *pdfDocument) slot(y int, x int, rows int, columns int) (float64, float64, float64, float64) {
+	const gap = 18
+	top, left := float64(bookletMargin+bookletBand), float64(bookletMargin)
+	width := (d.width - 2*bookletMargin - float64(columns-1)*gap) / float64(columns)
+	height := (d.height - 2*(bookletMargin+bookletBand) - float64(rows-1)*gap) / float64(rows)
+	return top + float64(y)*(height+gap), left + float64(x)*(width+gap), width, height
+}
+
+//picture draws the puzzle as large as fits the given part of page, centred across it, with solution's digits in its empty cells
+//if solution isn't nil
+func (d *pdfDocument) picture(page *pdfPage, samurai *SamuraiSudoku, solution Grid, top float64, left float64, width float64, height float64) {
+	rows, columns := samurai.Layout().Size()
+	w, h := float64(columns)+2*imageMargin, float64(rows)+2*imageMargin
+	scale := math.Min(width/w, height/h)
+	p := &pdfPen{page: page, left: left + (width-w*scale)/2, top: top, scale: scale}
+	drawPicture(p, samurai, samurai.Grid(), solution)
+}
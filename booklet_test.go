@@ -0,0 +1,142 @@
+package sudoku
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+//pdfContents returns the decompressed content streams of the pages of a PDF document written by WriteBooklet,
+//after checking its cross-reference table points at its objects
+func pdfContents(t *testing.T, pdf []byte) []string {
+	t.Helper()
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatalf("want a PDF document")
+	}
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	if startxref == nil {
+		t.Fatalf("want a startxref")
+	}
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	if !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+		t.Fatalf("want startxref to point at the cross-reference table")
+	}
+	for i, entry := range regexp.MustCompile(`(\d{10}) 00000 n \n`).FindAllSubmatch(pdf[xref:], -1) {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(pdf[offset:], []byte(want)) {
+			t.Fatalf("want object %d at offset %d", i+1, offset)
+		}
+	}
+
+	var contents []string
+	for _, stream := range regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindAllSubmatch(pdf, -1) {
+		z, err := zlib.NewReader(bytes.NewReader(stream[1]))
+		if err != nil {
+			t.Fatalf("want compressed content: %v", err)
+		}
+		content, err := io.ReadAll(z)
+		if err != nil {
+			t.Fatalf("want compressed content: %v", err)
+		}
+		contents = append(contents, string(content))
+	}
+	return contents
+}
+
+func TestWriteBooklet(t *testing.T) {
+	puzzles := []*SamuraiSudoku{newTestSamurai(), newTestSamurai(), newTestSamurai()}
+	givens := copyGrid(puzzles[0].Grid())
+	var buf bytes.Buffer
+	if err := WriteBooklet(context.Background(), &buf, puzzles, BookletOptions{Title: "Samurai – vol. (1)"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(puzzles[0].Grid(), givens) {
+		t.Fatalf("want the puzzles' grids left as they are")
+	}
+	pdf := buf.String()
+	// two pages of puzzles and one of answers
+	for _, want := range []string{"/Type /Pages /Kids [6 0 R 8 0 R 10 0 R] /Count 3", "/MediaBox [0 0 595.28 841.89]", "/Title (Samurai \x96 vol. \\(1\\))"} {
+		if !strings.Contains(pdf, want) {
+			t.Errorf("want the document to contain %q", want)
+		}
+	}
+
+	contents := pdfContents(t, buf.Bytes())
+	if len(contents) != 3 {
+		t.Fatalf("want 3 pages, got %d", len(contents))
+	}
+	testCases := []struct {
+		page int
+		want []string
+	}{
+		{0, []string{"(Samurai \x96 vol. \\(1\\)) Tj", "(Puzzle 1) Tj", "(Medium) Tj", "(Puzzle 2) Tj", "(1) Tj"}},
+		{1, []string{"(Puzzle 3) Tj", "(2) Tj"}},
+		{2, []string{"/F2 10 Tf 276.8 795.89 Td (Answers) Tj", "(Puzzle 1) Tj", "(Puzzle 3) Tj", "(3) Tj"}},
+	}
+	for _, tc := range testCases {
+		for _, want := range tc.want {
+			if !strings.Contains(contents[tc.page], want) {
+				t.Errorf("want page %d to contain %q", tc.page+1, want)
+			}
+		}
+	}
+	// the puzzle pages hold the givens, in bold, the answers the solved digits too, in the solution's colour
+	solved := "0.25 0.38 0.56 rg"
+	if strings.Contains(contents[0], solved) || !strings.Contains(contents[2], solved) {
+		t.Errorf("want the solved digits only drawn in the answers")
+	}
+	if !strings.Contains(contents[0], "/F2 9.43 Tf") {
+		t.Errorf("want the givens drawn in bold")
+	}
+}
+
+func TestWriteBookletErrors(t *testing.T) {
+	twin, err := ParseLayout("twin-4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var notUnique SamuraiSudoku
+	notUnique.SetLayout(twin)
+	notUnique.SetGrid(twin.NewGrid())
+	invalid := newTestSamurai()
+	invalid.Grid()[0][0] = 5
+
+	testCases := []struct {
+		name    string
+		puzzles []*SamuraiSudoku
+		want    error
+		message string
+	}{
+		{"no puzzles", nil, ErrNoPuzzles, ""},
+		{"not unique", []*SamuraiSudoku{newTestSamurai(), &notUnique}, ErrNotUnique, "puzzle 2: "},
+		{"invalid", []*SamuraiSudoku{invalid}, nil, "puzzle 1: "},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := WriteBooklet(context.Background(), io.Discard, tc.puzzles, BookletOptions{})
+			if err == nil {
+				t.Fatal("want an error")
+			}
+			if tc.want != nil && !errors.Is(err, tc.want) {
+				t.Fatalf("want %v, got %v", tc.want, err)
+			}
+			if !strings.HasPrefix(err.Error(), tc.message) {
+				t.Fatalf("want the error to start with %q, got %v", tc.message, err)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := WriteBooklet(ctx, io.Discard, []*SamuraiSudoku{newTestSamurai()}, BookletOptions{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want %v, got %v", context.Canceled, err)
+	}
+}
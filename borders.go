@@ -0,0 +1,136 @@
+package sudoku
+
+// Border is the kind of line drawn along a side of a canvas cell
+type Border int
+
+const (
+	NoBorder   Border = iota // Between two gaps, or a gap and the outside of the canvas
+	CellBorder               // Between two cells of the same boxes
+	BoxBorder                // Between cells of different boxes, or jigsaw regions, of the same sub-sudokus
+	GridBorder               // Along the edge of a sub-sudoku, between cells of different sub-sudokus or a cell and a gap
+)
+
+// Borders holds the lines along the sides of every cell of a puzzle's canvas
+type Borders struct {
+	Left [][]Border // Left[y][x] is the line left of cell y,x, Left[y][columns] the one right of the last cell of row y
+	Top  [][]Border // Top[y][x] is the line above cell y,x, Top[rows][x] the one below the last cell of column x
+}
+
+//Borders returns the lines to draw along the sides of the cells of the puzzle's canvas, to show its sub-sudokus
+//and their boxes, or jigsaw regions
+func (s *SamuraiSudoku) Borders() Borders {
+	l := s.Layout()
+	rows, columns := l.Size()
+	between := func(y0 int, x0 int, y1 int, x1 int) Border {
+		a, b := l.placements(y0, x0), l.placements(y1, x1)
+		switch {
+		case a == nil && b == nil:
+			return NoBorder
+		case len(a) != len(b):
+			return GridBorder
+		}
+		for i := range a {
+			if a[i].position != b[i].position {
+				return GridBorder
+			}
+		}
+		for i := range a {
+			if s.region(a[i]) != s.region(b[i]) {
+				return BoxBorder
+			}
+		}
+		return CellBorder
+	}
+
+	var borders Borders
+	borders.Left = make([][]Border, rows)
+	for y := range borders.Left {
+		borders.Left[y] = make([]Border, columns+1)
+		for x := range borders.Left[y] {
+			borders.Left[y][x] = between(y, x-1, y, x)
+		}
+	}
+	borders.Top = make([][]Border, rows+1)
+	for y := range borders.Top {
+		borders.Top[y] = make([]Border, columns)
+		for x := range borders.Top[y] {
+			borders.Top[y][x] = between(y-1, x, y, x)
+		}
+	}
+	return borders
+}
+
+//Lines tells which rows of the canvas have a line between boxes or sub-sudokus above them, and which columns have one left
+//of them, the last of each for the line below, or right of, the canvas. Text only gives room to these lines
+func (b Borders) Lines() ([]bool, []bool) {
+	rows := make([]bool, len(b.Top))
+	for y, row := range b.Top {
+		for _, kind := range row {
+			rows[y] = rows[y] || kind >= BoxBorder
+		}
+	}
+	var columns []bool
+	for _, row := range b.Left {
+		if columns == nil {
+			columns = make([]bool, len(row))
+		}
+		for x, kind := range row {
+			columns[x] = columns[x] || kind >= BoxBorder
+		}
+	}
+	return rows, columns
+}
+
+//Corner returns the sides the lines between boxes or sub-sudokus meeting at the corner above row y and left of column x go to,
+//as a mask of 1 for up, 2 for right, 4 for down and 8 for left, and whether any of them is the edge of a sub-sudoku
+func (b Borders) Corner(y int, x int) (int, bool) {
+	var sides [4]Border
+	if y > 0 {
+		sides[0] = b.Left[y-1][x]
+	}
+	if y < len(b.Top) && x < len(b.Top[y]) {
+		sides[1] = b.Top[y][x]
+	}
+	if y < len(b.Left) {
+		sides[2] = b.Left[y][x]
+	}
+	if x > 0 {
+		sides[3] = b.Top[y][x-1]
+	}
+	mask, heavy := 0, false
+	for i, kind := range sides {
+		if kind >= BoxBorder {
+			mask |= 1 << i
+		}
+		heavy = heavy || kind == GridBorder
+	}
+	return mask, heavy
+}
+
+//CornerChar returns the box-drawing character of the corner above row y and left of column x, heavy if any of its lines is
+//the edge of a sub-sudoku, a space if no line between boxes or sub-sudokus meets there
+func (b Borders) CornerChar(y int, x int) string {
+	mask, heavy := b.Corner(y, x)
+	if heavy {
+		return unicodeLines.heavy[mask]
+	}
+	return unicodeLines.light[mask]
+}
+
+//Char returns the box-drawing character of a line of kind b, light between boxes and heavy around sub-sudokus,
+//a space for the others
+func (b Border) Char(vertical bool) string {
+	if vertical {
+		return unicodeLines.vertical[b]
+	}
+	return unicodeLines.horizontal[b]
+}
+
+//region returns the box, or jigsaw region, of the sub-sudoku p is in that holds the cell, counted from 0
+func (s *SamuraiSudoku) region(p placement) int {
+	if regions := s.variant.regions(p.position); regions != nil {
+		return regions[p.row][p.column] - 1
+	}
+	box := s.Layout().box
+	return p.row/box.Rows*box.Rows + p.column/box.Columns
+}
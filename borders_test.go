@@ -0,0 +1,104 @@
+package sudoku
+
+import "testing"
+
+func TestBorders(t *testing.T) {
+	var samurai SamuraiSudoku
+	samurai.SetGrid(NewSamuraiGrid())
+	borders := samurai.Borders()
+	if len(borders.Left) != 21 || len(borders.Left[0]) != 22 || len(borders.Top) != 22 || len(borders.Top[0]) != 21 {
+		t.Fatalf("want a line on every side of the 21*21 cells")
+	}
+
+	testCases := []struct {
+		name    string
+		borders [][]Border
+		y, x    int
+		want    Border
+	}{
+		{"canvas edge", borders.Left, 0, 0, GridBorder},
+		{"same box", borders.Left, 0, 1, CellBorder},
+		{"next box", borders.Left, 0, 3, BoxBorder},
+		{"gap", borders.Left, 0, 9, GridBorder},
+		{"between gaps", borders.Left, 0, 10, NoBorder},
+		{"right edge", borders.Left, 0, 21, GridBorder},
+		{"bottom edge", borders.Top, 21, 0, GridBorder},
+		{"into the overlap", borders.Top, 6, 6, GridBorder},
+		{"within the overlap", borders.Left, 7, 7, CellBorder},
+		{"out of the overlap", borders.Left, 7, 9, GridBorder},
+		{"centre boxes", borders.Top, 12, 10, BoxBorder},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.borders[tc.y][tc.x]; got != tc.want {
+				t.Fatalf("want %d, got %d", tc.want, got)
+			}
+		})
+	}
+
+	// the 4th row of the centre's regions is 444456666
+	if err := samurai.SetVariant(&Variant{Jigsaws: []Jigsaw{testJigsaw}}); err != nil {
+		t.Fatal(err)
+	}
+	borders = samurai.Borders()
+	if borders.Left[9][9] != CellBorder || borders.Left[9][10] != BoxBorder {
+		t.Fatalf("want the lines between regions rather than boxes, got %d and %d", borders.Left[9][9], borders.Left[9][10])
+	}
+}
+
+func TestBordersLines(t *testing.T) {
+	var samurai SamuraiSudoku
+	samurai.SetGrid(NewSamuraiGrid())
+	rows, columns := samurai.Borders().Lines()
+	if len(rows) != 22 || len(columns) != 22 {
+		t.Fatalf("want a line above every row and below the last, got %d and %d", len(rows), len(columns))
+	}
+	// lines run between the boxes, every third row and column
+	for i := range rows {
+		if rows[i] != (i%3 == 0) || columns[i] != (i%3 == 0) {
+			t.Fatalf("want lines every third row and column, got %v and %v", rows, columns)
+		}
+	}
+}
+
+func TestBordersCorner(t *testing.T) {
+	var samurai SamuraiSudoku
+	samurai.SetGrid(NewSamuraiGrid())
+	borders := samurai.Borders()
+
+	testCases := []struct {
+		name  string
+		y, x  int
+		mask  int
+		heavy bool
+		char  string
+	}{
+		{"top left", 0, 0, 6, true, "┏"},
+		{"between boxes", 3, 3, 15, false, "┼"},
+		{"within a box", 1, 1, 0, false, " "},
+		{"by a gap", 0, 9, 12, true, "┓"},
+		{"into the overlap", 6, 6, 15, true, "╋"},
+		{"between gaps", 10, 1, 0, false, " "},
+		{"bottom right", 21, 21, 9, true, "┛"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if mask, heavy := borders.Corner(tc.y, tc.x); mask != tc.mask || heavy != tc.heavy {
+				t.Fatalf("want %d and %v, got %d and %v", tc.mask, tc.heavy, mask, heavy)
+			}
+			if got := borders.CornerChar(tc.y, tc.x); got != tc.char {
+				t.Fatalf("want %q, got %q", tc.char, got)
+			}
+		})
+	}
+
+	for _, tc := range []struct {
+		border   Border
+		vertical bool
+		want     string
+	}{{CellBorder, true, " "}, {BoxBorder, true, "│"}, {GridBorder, false, "━"}} {
+		if got := tc.border.Char(tc.vertical); got != tc.want {
+			t.Errorf("want %d drawn %q, got %q", tc.border, tc.want, got)
+		}
+	}
+}
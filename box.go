@@ -0,0 +1,81 @@
+package sudoku
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Box is the shape of the boxes of a layout's sub-sudokus.
+// A sub-sudoku is as many boxes high as a box is wide, and the other way round,
+// so sub-sudokus and their rows, columns and boxes all hold Rows*Columns cells and digits
+type Box struct {
+	Rows    int
+	Columns int
+}
+
+// ClassicBox is the 3x3 box of 9x9 sudoku
+var ClassicBox = Box{3, 3}
+
+// maxDigits is the largest number of digits a sub-sudoku can hold, one per letter for the largest ones
+const maxDigits = 26
+
+//Size returns the number of cells of the box, which is the number of digits of its sub-sudokus
+func (b Box) Size() int {
+	return b.Rows * b.Columns
+}
+
+func (b Box) String() string {
+	return fmt.Sprintf("%dx%d", b.Rows, b.Columns)
+}
+
+//BoxOfSize returns the squarest box holding size cells, with no more rows than columns, e.g. 2x3 for 6.
+//It returns an error for sizes that can only be made into a single row, such as primes, and for sizes above 26
+func BoxOfSize(size int) (Box, error) {
+	if size < 4 || size > maxDigits {
+		return Box{}, fmt.Errorf("%w: sub-sudokus of %d digits aren't supported", ErrInvalidLayout, size)
+	}
+	box := Box{1, size}
+	for rows := 2; rows*rows <= size; rows++ {
+		if size%rows == 0 {
+			box = Box{rows, size / rows}
+		}
+	}
+	if box.Rows == 1 {
+		return Box{}, fmt.Errorf("%w: %d cells can't be made into boxes", ErrInvalidLayout, size)
+	}
+	return box, nil
+}
+
+const (
+	digitSymbols  = "123456789"
+	letterSymbols = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+)
+
+//symbolsFor returns the characters digits 1 to size are written with, in order:
+//decimal digits up to 9, then letters from A up to 16 (1-9A-G), and only letters above 16 (A-Y for 25)
+func symbolsFor(size int) string {
+	switch {
+	case size <= len(digitSymbols):
+		return digitSymbols[:size]
+	case size <= 16:
+		return digitSymbols + letterSymbols[:size-len(digitSymbols)]
+	}
+	return letterSymbols[:size]
+}
+
+//symbol returns the character digit num is written with in symbols, '?' if there is none
+func symbol(symbols string, num int) string {
+	if num < 1 || num > len(symbols) {
+		return "?"
+	}
+	return symbols[num-1 : num]
+}
+
+//parseSymbol returns the digit written with char in symbols, letters in either case
+func parseSymbol(symbols string, char rune) (int, bool) {
+	i := strings.IndexRune(symbols, char)
+	if i < 0 {
+		i = strings.IndexRune(symbols, rune(strings.ToUpper(string(char))[0]))
+	}
+	return i + 1, i >= 0
+}
@@ -0,0 +1,61 @@
+package sudoku
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBoxOfSize(t *testing.T) {
+	testCases := []struct {
+		size int
+		want Box
+	}{
+		{4, Box{2, 2}},
+		{6, Box{2, 3}},
+		{8, Box{2, 4}},
+		{9, ClassicBox},
+		{12, Box{3, 4}},
+		{16, Box{4, 4}},
+		{25, Box{5, 5}},
+	}
+	for _, tc := range testCases {
+		if got, err := BoxOfSize(tc.size); err != nil || got != tc.want {
+			t.Fatalf("%d: want %s, got %s, %v", tc.size, tc.want, got, err)
+		}
+	}
+	for _, size := range []int{0, 3, 7, 13, 36} {
+		if _, err := BoxOfSize(size); !errors.Is(err, ErrInvalidLayout) {
+			t.Fatalf("%d: want ErrInvalidLayout, got %v", size, err)
+		}
+	}
+}
+
+func TestSymbols(t *testing.T) {
+	testCases := []struct {
+		size int
+		want string
+	}{
+		{4, "1234"},
+		{9, "123456789"},
+		{16, "123456789ABCDEFG"},
+		{25, "ABCDEFGHIJKLMNOPQRSTUVWXY"},
+	}
+	for _, tc := range testCases {
+		symbols := symbolsFor(tc.size)
+		if symbols != tc.want {
+			t.Fatalf("%d: want %q, got %q", tc.size, tc.want, symbols)
+		}
+		for n := 1; n <= tc.size; n++ {
+			char := rune(symbol(symbols, n)[0])
+			if got, ok := parseSymbol(symbols, char); !ok || got != n {
+				t.Fatalf("%d: want %q parsed back to %d, got %d", tc.size, char, n, got)
+			}
+		}
+	}
+	if got, ok := parseSymbol(symbolsFor(16), 'g'); !ok || got != 16 {
+		t.Fatalf("want lower case letters to parse, got %d", got)
+	}
+	if _, ok := parseSymbol(symbolsFor(25), '1'); ok {
+		t.Fatalf("want digits not to parse in the A-Y alphabet")
+	}
+}
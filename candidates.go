@@ -0,0 +1,176 @@
+package sudoku
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Candidates holds the digits every empty cell of a puzzle's grid may still hold, its pencil marks. They follow the rules of
+// the puzzle, its variant's included, cells shared by overlapping sub-sudokus losing the digits placed in the units of all of them.
+// They are kept up to date as digits are placed, and candidates ruled out by a player stay eliminated
+type Candidates struct {
+	engine *engine
+	index  [][]int  // Index of every canvas cell in the engine's cells, -1 for the gaps
+	masks  []uint32 // Candidates of each cell, bit n set for digit n, 0 for filled cells
+}
+
+//NewCandidates returns the candidates of every empty cell of the puzzle's grid.
+//It returns the errors of Validate for grids breaking a rule
+func NewCandidates(samurai *SamuraiSudoku) (*Candidates, error) {
+	if err := samurai.Validate(); err != nil {
+		return nil, err
+	}
+	e, err := newEngine(context.Background(), samurai.Layout(), samurai.variant, samurai.Grid())
+	if err != nil {
+		return nil, err
+	}
+	c := &Candidates{engine: e, masks: make([]uint32, len(e.cells))}
+	c.index = make([][]int, len(e.grid))
+	for y, row := range e.grid {
+		c.index[y] = make([]int, len(row))
+		for x := range row {
+			c.index[y][x] = -1
+		}
+	}
+	for i, d := range e.cells {
+		c.index[d.Row][d.Column] = i
+		if e.grid[d.Row][d.Column] == 0 {
+			c.masks[i] = e.candidates(i)
+		}
+	}
+	return c, nil
+}
+
+//cell returns the index of the canvas cell d in the engine's cells, false for gaps and cells off the canvas
+func (c *Candidates) cell(d Cell) (int, bool) {
+	if !c.engine.layout.Contains(d.Row, d.Column) {
+		return 0, false
+	}
+	return c.index[d.Row][d.Column], true
+}
+
+//Digits returns the candidates of cell d in increasing order, none for filled cells and gaps
+func (c *Candidates) Digits(d Cell) []int {
+	i, ok := c.cell(d)
+	if !ok {
+		return nil
+	}
+	return maskDigits(c.masks[i])
+}
+
+//Has tells if n is a candidate of cell d
+func (c *Candidates) Has(d Cell, n int) bool {
+	i, ok := c.cell(d)
+	return ok && n >= 1 && n <= c.engine.size && c.masks[i]&(1<<n) != 0
+}
+
+//Eliminate rules digits out of the candidates of cell d, and tells if any of them was a candidate
+func (c *Candidates) Eliminate(d Cell, digits ...int) bool {
+	i, ok := c.cell(d)
+	if !ok {
+		return false
+	}
+	before := c.masks[i]
+	for _, n := range digits {
+		if n >= 1 && n <= c.engine.size {
+			c.masks[i] &^= 1 << n
+		}
+	}
+	return c.masks[i] != before
+}
+
+//Place fills cell d with n and rules out the candidates of the other cells that n no longer allows: n in the cells sharing a unit
+//with d in any sub-sudoku, and digits breaking the cages, markers and constraints d is in.
+//It returns an error if d isn't an empty cell or n isn't one of its candidates
+func (c *Candidates) Place(d Cell, n int) error {
+	i, ok := c.cell(d)
+	if !ok || c.engine.grid[d.Row][d.Column] != 0 {
+		return fmt.Errorf("sudoku: %s isn't an empty cell", formatCell(d))
+	}
+	if !c.Has(d, n) {
+		return fmt.Errorf("sudoku: %d isn't a candidate of %s", n, formatCell(d))
+	}
+	e := c.engine
+	e.place(i, n)
+	c.masks[i] = 0
+	for _, j := range c.affected(i) {
+		if c.masks[j] != 0 {
+			c.masks[j] &= e.candidates(j)
+		}
+	}
+	return nil
+}
+
+//affected returns the cells whose candidates a digit placed in the i-th cell may rule out, some of them more than once
+func (c *Candidates) affected(i int) []int {
+	e := c.engine
+	var cells []int
+	for _, u := range e.cellUnits[i] {
+		cells = append(cells, e.unitCells[u]...)
+	}
+	if e.cellCages != nil {
+		for _, k := range e.cellCages[i] {
+			cells = append(cells, e.cages[k].cells...)
+		}
+	}
+	if e.relations != nil {
+		for _, r := range e.relations[i] {
+			cells = append(cells, r.cell)
+		}
+	}
+	if e.cellConstraints != nil {
+		for _, k := range e.cellConstraints[i] {
+			for _, d := range e.constraints[k].Cells() {
+				cells = append(cells, c.index[d.Row][d.Column])
+			}
+		}
+	}
+	return cells
+}
+
+//Grid returns a copy of the grid, with the digits placed so far
+func (c *Candidates) Grid() Grid {
+	return copyGrid(c.engine.grid)
+}
+
+//String draws every cell of the grid as a mini-grid shaped like a box of the layout, 3x3 for 9x9 sub-sudokus, holding each
+//candidate in the place of its digit and a dot for the others. Filled cells hold their digit in the middle, gaps are left blank.
+//Cells are a space apart, boxes two, and rows of cells are followed by a blank line
+func (c *Candidates) String() string {
+	e := c.engine
+	box, symbols := e.layout.box, e.layout.symbols
+	var b strings.Builder
+	for y, row := range e.grid {
+		for line := 0; line < box.Rows; line++ {
+			var text strings.Builder
+			for x, num := range row {
+				if x > 0 {
+					text.WriteString(" ")
+					if x%box.Columns == 0 {
+						text.WriteString(" ")
+					}
+				}
+				for column := 0; column < box.Columns; column++ {
+					n := line*box.Columns + column + 1
+					switch {
+					case num == -1:
+						text.WriteString(" ")
+					case num > 0 && line == box.Rows/2 && column == box.Columns/2:
+						text.WriteString(symbol(symbols, num))
+					case num > 0:
+						text.WriteString(" ")
+					case c.masks[c.index[y][x]]&(1<<n) != 0:
+						text.WriteString(symbol(symbols, n))
+					default:
+						text.WriteString(".")
+					}
+				}
+			}
+			b.WriteString(strings.TrimRight(text.String(), " "))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
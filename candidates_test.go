@@ -0,0 +1,140 @@
+package sudoku
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCandidates(t *testing.T) {
+	for _, variant := range []*Variant{nil, {Constraints: testLines}} {
+		samurai := newTestSamurai()
+		if err := samurai.SetVariant(variant); err != nil {
+			t.Fatal(err)
+		}
+		candidates, err := NewCandidates(samurai)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// every candidate is a digit possible() allows, checked from the first sub-sudoku of the cell
+		grid := samurai.Grid()
+		for y, row := range grid {
+			for x, num := range row {
+				var want []int
+				if position, sy, sx, ok := samurai.Layout().locate(y, x); ok && num == 0 {
+					for n := 1; n <= 9; n++ {
+						if possible(samurai.GetSubSudoku(position), sy, sx, n, position, samurai) {
+							want = append(want, n)
+						}
+					}
+				}
+				if got := candidates.Digits(Cell{y, x}); !reflect.DeepEqual(got, want) {
+					t.Fatalf("(%d, %d): want %v, got %v", y, x, want, got)
+				}
+			}
+		}
+	}
+}
+
+func TestCandidatesPlace(t *testing.T) {
+	samurai := newTestSamurai()
+	solution, err := GlobalSolveSamuraiSudokuContext(context.Background(), newTestSamurai())
+	if err != nil {
+		t.Fatal(err)
+	}
+	candidates, err := NewCandidates(samurai)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// (6, 7) is in the top left sub-sudoku's box shared with the centre one, and in the centre's row and column through it
+	placed := Cell{6, 7}
+	n := solution[6][7]
+	if !candidates.Has(placed, n) {
+		t.Fatalf("want %d a candidate of the solution's cell", n)
+	}
+	if err := candidates.Place(placed, n); err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []Cell{{6, 1}, {2, 7}, {6, 10}, {11, 7}, {8, 8}} {
+		if candidates.Has(d, n) {
+			t.Fatalf("want %d ruled out of %v", n, d)
+		}
+	}
+	if candidates.Digits(placed) != nil || candidates.Grid()[6][7] != n {
+		t.Fatalf("want the cell filled with %d", n)
+	}
+
+	// placing keeps the candidates a new computation finds
+	samurai.SetGrid(candidates.Grid())
+	fresh, err := NewCandidates(samurai)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y, row := range samurai.Grid() {
+		for x := range row {
+			if got, want := candidates.Digits(Cell{y, x}), fresh.Digits(Cell{y, x}); !reflect.DeepEqual(got, want) {
+				t.Fatalf("(%d, %d): want %v, got %v", y, x, want, got)
+			}
+		}
+	}
+
+	if err := candidates.Place(placed, n); err == nil {
+		t.Fatalf("want an error placing a digit in a filled cell")
+	}
+	if err := candidates.Place(Cell{0, 9}, n); err == nil {
+		t.Fatalf("want an error placing a digit in a gap")
+	}
+	if err := candidates.Place(Cell{6, 1}, n); err == nil {
+		t.Fatalf("want an error placing a digit that isn't a candidate")
+	}
+}
+
+func TestCandidatesEliminate(t *testing.T) {
+	var samurai SamuraiSudoku
+	samurai.SetGrid(NewSamuraiGrid())
+	candidates, err := NewCandidates(&samurai)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !candidates.Eliminate(Cell{0, 1}, 2, 3) || candidates.Eliminate(Cell{0, 1}, 2) {
+		t.Fatalf("want only candidates reported eliminated")
+	}
+	if err := candidates.Place(Cell{0, 0}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := candidates.Digits(Cell{0, 1}), []int{4, 5, 6, 7, 8, 9}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("want eliminations kept once a digit is placed, got %v", got)
+	}
+}
+
+func TestCandidatesString(t *testing.T) {
+	twin4, err := ParseLayout("twin-4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var samurai SamuraiSudoku
+	samurai.SetLayout(twin4)
+	grid := twin4.NewGrid()
+	grid[0][0] = 1
+	samurai.SetGrid(grid)
+	candidates, err := NewCandidates(&samurai)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(candidates.String(), "\n")
+	// the filled cell holds its digit in its middle, the rest of its row, column and box lose 1
+	if want := "   .2  .2 .2"; !strings.HasPrefix(lines[0], want) {
+		t.Fatalf("want the first line to start with %q, got %q", want, lines[0])
+	}
+	if want := " 1 34  34 34"; !strings.HasPrefix(lines[1], want) {
+		t.Fatalf("want the second line to start with %q, got %q", want, lines[1])
+	}
+	if lines[2] != "" {
+		t.Fatalf("want a blank line after a row of cells, got %q", lines[2])
+	}
+}
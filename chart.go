@@ -0,0 +1,143 @@
+package sudoku
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/wcharczuk/go-chart/v2"
+)
+
+// ChartFormat is the image format a chart is rendered in
+type ChartFormat int
+
+const (
+	ChartPNG ChartFormat = iota
+	ChartSVG
+)
+
+func (f ChartFormat) String() string {
+	switch f {
+	case ChartPNG:
+		return "png"
+	case ChartSVG:
+		return "svg"
+	}
+	return "unknown"
+}
+
+// ChartOptions configures how a chart is rendered.
+// Zero values fall back to a PNG using go-chart's default title and size.
+type ChartOptions struct {
+	Format ChartFormat
+	Title  string
+	Width  int
+	Height int
+}
+
+// ErrEmptyTrace is returned when charting a sudoku that has no recorded moves
+var ErrEmptyTrace = errors.New("sudoku: no moves have been recorded")
+
+const defaultChartTitle = "Time (ms) and number of moves"
+
+//WriteGraph renders the number of moves made over time by all threads to w
+func WriteGraph(w io.Writer, samurai *SamuraiSudoku, options ChartOptions) error {
+	stats := samurai.trace()
+	if len(stats) == 0 {
+		return ErrEmptyTrace
+	}
+
+	var xValues []float64
+	var yValues []float64
+	startingTime := stats[0].time
+
+	for i, stat := range stats {
+		xValues = append(xValues, float64(stat.time.Sub(startingTime).Nanoseconds()))
+		yValues = append(yValues, float64(i))
+	}
+
+	timeGraph := newTimeGraph(options, []chart.Series{
+		chart.ContinuousSeries{
+			XValues: xValues,
+			YValues: yValues,
+		},
+	})
+	return renderChart(w, timeGraph, options.Format)
+}
+
+//WriteMultiThreadedGraph renders the number of moves made over time to w, with a series per thread
+func WriteMultiThreadedGraph(w io.Writer, samurai *SamuraiSudoku, options ChartOptions) error {
+	stats := samurai.trace()
+	if len(stats) == 0 {
+		return ErrEmptyTrace
+	}
+
+	type threadSeries struct {
+		position Position
+		xValues  []float64
+		yValues  []float64
+	}
+	threads := make(map[int]*threadSeries)
+	startingTime := stats[0].time
+
+	for _, stat := range stats {
+		series, ok := threads[stat.thread]
+		if !ok {
+			series = &threadSeries{position: stat.position}
+			threads[stat.thread] = series
+		}
+		series.xValues = append(series.xValues, float64(stat.time.Sub(startingTime).Nanoseconds()))
+		series.yValues = append(series.yValues, float64(len(series.yValues)))
+	}
+
+	ids := make([]int, 0, len(threads))
+	for id := range threads {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var series []chart.Series
+	for _, id := range ids {
+		thread := threads[id]
+		series = append(series, chart.ContinuousSeries{
+			Name:    fmt.Sprintf("%s (thread %d)", thread.position, id%10),
+			XValues: thread.xValues,
+			YValues: thread.yValues,
+		})
+	}
+
+	timeGraph := newTimeGraph(options, series)
+	timeGraph.Elements = []chart.Renderable{chart.Legend(&timeGraph)}
+	return renderChart(w, timeGraph, options.Format)
+}
+
+//newTimeGraph builds a chart of moves over time, with the x axis formatted in milliseconds
+func newTimeGraph(options ChartOptions, series []chart.Series) chart.Chart {
+	title := options.Title
+	if title == "" {
+		title = defaultChartTitle
+	}
+	return chart.Chart{
+		Title:  title,
+		Width:  options.Width,
+		Height: options.Height,
+		XAxis: chart.XAxis{
+			ValueFormatter: func(v interface{}) string {
+				return strconv.FormatInt(int64(v.(float64)/1000000), 10)
+			},
+		},
+		Series: series,
+	}
+}
+
+func renderChart(w io.Writer, c chart.Chart, format ChartFormat) error {
+	switch format {
+	case ChartPNG:
+		return c.Render(chart.PNG, w)
+	case ChartSVG:
+		return c.Render(chart.SVG, w)
+	}
+	return fmt.Errorf("sudoku: unsupported chart format %d", format)
+}
@@ -0,0 +1,57 @@
+package sudoku
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestWriteGraph(t *testing.T) {
+	samurai := newTestSamurai()
+	SolveSamuraiSudoku(samurai)
+
+	testCases := []struct {
+		name   string
+		write  func(buf *bytes.Buffer, options ChartOptions) error
+		format ChartFormat
+		prefix []byte
+	}{
+		{"png", func(buf *bytes.Buffer, options ChartOptions) error {
+			return WriteGraph(buf, samurai, options)
+		}, ChartPNG, []byte("\x89PNG")},
+		{"svg", func(buf *bytes.Buffer, options ChartOptions) error {
+			return WriteGraph(buf, samurai, options)
+		}, ChartSVG, []byte("<svg")},
+		{"multi-threaded png", func(buf *bytes.Buffer, options ChartOptions) error {
+			return WriteMultiThreadedGraph(buf, samurai, options)
+		}, ChartPNG, []byte("\x89PNG")},
+		{"multi-threaded svg", func(buf *bytes.Buffer, options ChartOptions) error {
+			return WriteMultiThreadedGraph(buf, samurai, options)
+		}, ChartSVG, []byte("<svg")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			options := ChartOptions{Format: tc.format, Title: "moves", Width: 640, Height: 480}
+			if err := tc.write(&buf, options); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.HasPrefix(buf.Bytes(), tc.prefix) {
+				t.Fatalf("want output starting with %q, got %q", tc.prefix, buf.String())
+			}
+		})
+	}
+}
+
+func TestWriteGraphEmptyTrace(t *testing.T) {
+	var samurai SamuraiSudoku
+	var buf bytes.Buffer
+
+	if err := WriteGraph(&buf, &samurai, ChartOptions{}); !errors.Is(err, ErrEmptyTrace) {
+		t.Fatalf("want %v, got %v", ErrEmptyTrace, err)
+	}
+	if err := WriteMultiThreadedGraph(&buf, &samurai, ChartOptions{}); !errors.Is(err, ErrEmptyTrace) {
+		t.Fatalf("want %v, got %v", ErrEmptyTrace, err)
+	}
+}
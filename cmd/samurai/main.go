@@ -0,0 +1,922 @@
+// Command samurai solves, checks, generates, converts, prints and plays samurai sudoku puzzles.
+//
+// Usage:
+//
+//	samurai <command> [flags] [file]
+//
+// Puzzles are read from file, or from stdin when file is omitted or "-".
+// Run "samurai help" for the list of commands.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	sudoku "github.com/alielbashir/samurai-sudoku-go"
+	"github.com/alielbashir/samurai-sudoku-go/live"
+	"github.com/alielbashir/samurai-sudoku-go/tui"
+	"golang.org/x/term"
+)
+
+// Exit codes
+const (
+	exitOK        = 0
+	exitError     = 1 // Reading or writing failed
+	exitUsage     = 2 // Unknown command or bad flags
+	exitInvalid   = 3 // The puzzle is malformed, breaks the rules or has no solution
+	exitTimeout   = 4 // The solver ran out of time
+	exitNotUnique = 5 // The puzzle has more than one solution
+	exitStuck     = 6 // The logical solver got stuck, the puzzle needs guessing
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(env *env, args []string) int
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"solve", "solve a puzzle and print its solution", runSolve},
+		{"validate", "check a puzzle follows the rules and has a unique solution", runValidate},
+		{"count-solutions", "print the number of solutions of a puzzle", runCountSolutions},
+		{"hint", "explain the simplest logical deduction left in a puzzle", runHint},
+		{"logic", "solve a puzzle without guessing and print the steps taken", runLogic},
+		{"generate", "generate a puzzle with a unique solution", runGenerate},
+		{"render", "print a puzzle as a grid", runRender},
+		{"image", "draw a puzzle for print as a PNG or SVG image", runImage},
+		{"tikz", "write a puzzle as a TikZ picture for LaTeX documents", runTikZ},
+		{"booklet", "write a PDF booklet of puzzles and their solutions", runBooklet},
+		{"convert", "convert a puzzle between formats", runConvert},
+		{"trace", "solve a puzzle and print the moves made as CSV", runTrace},
+		{"chart", "solve a puzzle and draw the moves made over time", runChart},
+		{"live", "serve a web page showing a puzzle being solved live", runLive},
+		{"play", "play a puzzle in the terminal", runPlay},
+		{"watch", "show a puzzle being solved in the terminal", runWatch},
+	}
+}
+
+// env holds the streams a command reads and writes
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+//run runs the command named by args[0] and returns the process' exit code
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	sudoku.SetLogOutput(io.Discard)
+	e := &env{stdin: stdin, stdout: stdout, stderr: stderr}
+
+	if len(args) == 0 {
+		usage(stderr)
+		return exitUsage
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+		return exitOK
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(e, args[1:])
+		}
+	}
+	fmt.Fprintf(stderr, "samurai: unknown command %q\n", args[0])
+	usage(stderr)
+	return exitUsage
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: samurai <command> [flags] [file]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-16s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(w, "\nPuzzles are read from file, or from stdin when file is omitted or \"-\".\n")
+	fmt.Fprintf(w, "Run \"samurai <command> -h\" for the flags of a command.\n")
+}
+
+//newFlagSet returns a flag set for the named command that reports errors to e.stderr
+func (e *env) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("samurai "+name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	return fs
+}
+
+//parse parses args into fs, returning the exit code to stop with if they couldn't be parsed
+func (e *env) parse(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	if fs.NArg() > 1 {
+		fmt.Fprintf(e.stderr, "%s: too many arguments\n", fs.Name())
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+// inputFlags are the flags of the commands that read a puzzle
+type inputFlags struct {
+	in     *string
+	layout *string
+}
+
+func addInputFlags(fs *flag.FlagSet) inputFlags {
+	return inputFlags{
+		in:     fs.String("in", "text", "input format: text, line or json"),
+		layout: addLayoutFlag(fs),
+	}
+}
+
+func addLayoutFlag(fs *flag.FlagSet) *string {
+	return fs.String("layout", "samurai", "puzzle layout: samurai, twin, butterfly, flower, cross, sohei, windmill or super-samurai, "+
+		"with -N for sub-sudokus of N digits, e.g. samurai-16")
+}
+
+//layout returns the layout named name, reporting it if there is none
+func (e *env) layout(fs *flag.FlagSet, name string) (*sudoku.Layout, int) {
+	layout, err := sudoku.ParseLayout(name)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "%s: %v\n", fs.Name(), err)
+		return nil, exitUsage
+	}
+	return layout, exitOK
+}
+
+//parsePositions parses a flag's comma separated sub-sudokus of layout, "all" standing for every one, nil for an empty flag
+func parsePositions(layout *sudoku.Layout, value string) ([]sudoku.Position, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if value == "all" {
+		return layout.Positions(), nil
+	}
+	var positions []sudoku.Position
+	for _, name := range strings.Split(value, ",") {
+		position, err := sudoku.ParsePosition(name)
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, position)
+	}
+	return positions, nil
+}
+
+//readPuzzle reads the puzzle named by the first argument left in fs, or stdin, and checks it follows the rules
+func (e *env) readPuzzle(fs *flag.FlagSet, flags inputFlags) (*sudoku.SamuraiSudoku, int) {
+	samurai, code := e.readGrid(fs, flags)
+	if code != exitOK {
+		return nil, code
+	}
+	if err := samurai.Validate(); err != nil {
+		return nil, e.fail(fs, err)
+	}
+	return samurai, exitOK
+}
+
+//readGrid reads the puzzle named by the first argument left in fs, or stdin, without checking it follows the rules
+func (e *env) readGrid(fs *flag.FlagSet, flags inputFlags) (*sudoku.SamuraiSudoku, int) {
+	return e.readGridFile(fs, flags, fs.Arg(0))
+}
+
+//readGridFile reads the puzzle in the file at path, or stdin if path is empty or "-", without checking it follows the rules
+func (e *env) readGridFile(fs *flag.FlagSet, flags inputFlags, path string) (*sudoku.SamuraiSudoku, int) {
+	gridFormat, err := sudoku.ParseGridFormat(*flags.in)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "%s: %v\n", fs.Name(), err)
+		return nil, exitUsage
+	}
+	layout, code := e.layout(fs, *flags.layout)
+	if code != exitOK {
+		return nil, code
+	}
+
+	var r io.Reader = e.stdin
+	if path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(e.stderr, "%s: %v\n", fs.Name(), err)
+			return nil, exitError
+		}
+		defer f.Close()
+		r = f
+	}
+
+	samurai, err := sudoku.ReadPuzzle(r, layout, gridFormat)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "%s: %v\n", fs.Name(), err)
+		if errors.Is(err, sudoku.ErrInvalidGrid) {
+			return nil, exitInvalid
+		}
+		return nil, exitError
+	}
+	return samurai, exitOK
+}
+
+//writeGrid writes the puzzle's grid to stdout in the named format, after the header holding its variant if it has one
+func (e *env) writeGrid(fs *flag.FlagSet, samurai *sudoku.SamuraiSudoku, format string) int {
+	gridFormat, err := sudoku.ParseGridFormat(format)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "%s: %v\n", fs.Name(), err)
+		return exitUsage
+	}
+	if err := sudoku.WritePuzzle(e.stdout, samurai, gridFormat); err != nil {
+		fmt.Fprintf(e.stderr, "%s: %v\n", fs.Name(), err)
+		return exitError
+	}
+	return exitOK
+}
+
+//fail reports err and returns the exit code matching it
+func (e *env) fail(fs *flag.FlagSet, err error) int {
+	fmt.Fprintf(e.stderr, "%s: %v\n", fs.Name(), err)
+	var conflict *sudoku.ConflictError
+	var cage *sudoku.CageError
+	var marker *sudoku.MarkerError
+	var constraint *sudoku.ConstraintError
+	var restriction *sudoku.RestrictionError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return exitTimeout
+	case errors.Is(err, sudoku.ErrNotUnique):
+		return exitNotUnique
+	case errors.Is(err, sudoku.ErrUnsolvable), errors.Is(err, sudoku.ErrInvalidGrid), errors.As(err, &conflict), errors.As(err, &cage), errors.As(err, &marker), errors.As(err, &constraint),
+		errors.As(err, &restriction):
+		return exitInvalid
+	}
+	return exitError
+}
+
+//writeOutput calls write with the file at path, created for it, or with stdout if path is empty. The file is removed if write,
+//or closing it, fails, so that no truncated output is left behind
+func (e *env) writeOutput(fs *flag.FlagSet, path string, write func(w io.Writer) error) int {
+	if path == "" {
+		if err := write(e.stdout); err != nil {
+			return e.fail(fs, err)
+		}
+		return exitOK
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return e.fail(fs, err)
+	}
+	err = write(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return e.fail(fs, err)
+	}
+	return exitOK
+}
+
+// solveFlags are the flags shared by the commands that run a solver
+type solveFlags struct {
+	solver  *string
+	timeout *time.Duration
+	input   inputFlags
+}
+
+func addSolveFlags(fs *flag.FlagSet) solveFlags {
+	return solveFlags{
+		solver:  fs.String("solver", "global", "solver to use: global, sequential, concurrent or double"),
+		timeout: fs.Duration("timeout", 0, "give up after this long, 0 for no limit"),
+		input:   addInputFlags(fs),
+	}
+}
+
+//solve reads the puzzle and solves it with the chosen solver
+func (e *env) solve(fs *flag.FlagSet, flags solveFlags) (*sudoku.SamuraiSudoku, int) {
+	solve, ok := sudoku.Solvers[*flags.solver]
+	if !ok {
+		fmt.Fprintf(e.stderr, "%s: unknown solver %q\n", fs.Name(), *flags.solver)
+		return nil, exitUsage
+	}
+	samurai, code := e.readPuzzle(fs, flags.input)
+	if code != exitOK {
+		return nil, code
+	}
+
+	ctx, cancel := withTimeout(*flags.timeout)
+	defer cancel()
+
+	if _, err := solve(ctx, samurai); err != nil {
+		return nil, e.fail(fs, err)
+	}
+	return samurai, exitOK
+}
+
+func withTimeout(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+func runSolve(e *env, args []string) int {
+	fs := e.newFlagSet("solve")
+	flags := addSolveFlags(fs)
+	out := fs.String("out", "text", "output format: text, line or json")
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+
+	samurai, code := e.solve(fs, flags)
+	if code != exitOK {
+		return code
+	}
+	return e.writeGrid(fs, samurai, *out)
+}
+
+func runValidate(e *env, args []string) int {
+	fs := e.newFlagSet("validate")
+	input := addInputFlags(fs)
+	timeout := fs.Duration("timeout", 0, "give up counting solutions after this long, 0 for no limit")
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+
+	samurai, code := e.readPuzzle(fs, input)
+	if code != exitOK {
+		return code
+	}
+
+	ctx, cancel := withTimeout(*timeout)
+	defer cancel()
+	count, err := samurai.CountSolutions(ctx, 2)
+	if err != nil {
+		return e.fail(fs, err)
+	}
+	switch count {
+	case 0:
+		return e.fail(fs, sudoku.ErrUnsolvable)
+	case 1:
+		fmt.Fprintln(e.stdout, "valid")
+		return exitOK
+	}
+	fmt.Fprintln(e.stderr, "validate: the puzzle has more than one solution")
+	return exitNotUnique
+}
+
+func runCountSolutions(e *env, args []string) int {
+	fs := e.newFlagSet("count-solutions")
+	input := addInputFlags(fs)
+	limit := fs.Int("limit", 0, "stop counting after this many solutions, 0 for no limit")
+	timeout := fs.Duration("timeout", 0, "give up after this long, 0 for no limit")
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+
+	samurai, code := e.readPuzzle(fs, input)
+	if code != exitOK {
+		return code
+	}
+
+	ctx, cancel := withTimeout(*timeout)
+	defer cancel()
+	count, err := samurai.CountSolutions(ctx, *limit)
+	if err != nil {
+		return e.fail(fs, err)
+	}
+	fmt.Fprintln(e.stdout, count)
+	return exitOK
+}
+
+func runHint(e *env, args []string) int {
+	fs := e.newFlagSet("hint")
+	input := addInputFlags(fs)
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+
+	samurai, code := e.readPuzzle(fs, input)
+	if code != exitOK {
+		return code
+	}
+
+	d, err := sudoku.Hint(samurai)
+	if err != nil {
+		return e.fail(fs, err)
+	}
+	fmt.Fprintf(e.stdout, "%s: %s\n", d.Technique, d.Explanation)
+	return exitOK
+}
+
+//runLogic prints the steps of the logical solver, followed by the partly filled grid and its candidates if it gets stuck
+func runLogic(e *env, args []string) int {
+	fs := e.newFlagSet("logic")
+	input := addInputFlags(fs)
+	timeout := fs.Duration("timeout", 0, "give up after this long, 0 for no limit")
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+
+	samurai, code := e.readPuzzle(fs, input)
+	if code != exitOK {
+		return code
+	}
+
+	ctx, cancel := withTimeout(*timeout)
+	defer cancel()
+	solution, err := sudoku.SolveLogically(ctx, samurai)
+	if err != nil {
+		return e.fail(fs, err)
+	}
+	for i, step := range solution.Steps {
+		fmt.Fprintf(e.stdout, "%d. %s: %s\n", i+1, step.Technique, step.Explanation)
+	}
+	if solution.Solved {
+		return exitOK
+	}
+	fmt.Fprintf(e.stdout, "\nstuck after %d steps, candidates left:\n\n%s", len(solution.Steps), solution.Candidates)
+	fmt.Fprintln(e.stderr, "logic: no technique applies, the puzzle needs guessing")
+	return exitStuck
+}
+
+func runGenerate(e *env, args []string) int {
+	fs := e.newFlagSet("generate")
+	seed := fs.Int64("seed", 0, "seed of the random source, 0 for a random seed")
+	minClues := fs.Int("min-clues", 0, "stop removing clues once the puzzle is down to this many")
+	timeout := fs.Duration("timeout", 0, "give up after this long, 0 for no limit")
+	out := fs.String("out", "text", "output format: text, line or json")
+	layoutName := addLayoutFlag(fs)
+	diagonals := fs.String("diagonals", "", "comma separated sub-sudokus whose diagonals hold every digit once, e.g. centre,top-left, or all")
+	windows := fs.String("windows", "", "comma separated sub-sudokus whose four windows hold every digit once, e.g. centre, or all")
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 0 {
+		fmt.Fprintf(e.stderr, "%s: too many arguments\n", fs.Name())
+		return exitUsage
+	}
+	layout, code := e.layout(fs, *layoutName)
+	if code != exitOK {
+		return code
+	}
+	samurai := &sudoku.SamuraiSudoku{}
+	samurai.SetLayout(layout)
+	if *diagonals != "" || *windows != "" {
+		variant := &sudoku.Variant{}
+		var err error
+		if variant.Diagonals, err = parsePositions(layout, *diagonals); err == nil {
+			variant.Windows, err = parsePositions(layout, *windows)
+		}
+		if err == nil {
+			err = samurai.SetVariant(variant)
+		}
+		if err != nil {
+			fmt.Fprintf(e.stderr, "%s: %v\n", fs.Name(), err)
+			return exitUsage
+		}
+	}
+
+	ctx, cancel := withTimeout(*timeout)
+	defer cancel()
+	grid, err := sudoku.GenerateSamuraiSudokuContext(ctx, sudoku.GenerateOptions{Seed: *seed, MinClues: *minClues, Layout: layout, Variant: samurai.Variant()})
+	if err != nil {
+		return e.fail(fs, err)
+	}
+	samurai.SetGrid(grid)
+	return e.writeGrid(fs, samurai, *out)
+}
+
+//runRender prints a puzzle's grid, digits a space apart unless it is asked for lines between boxes and sub-sudokus,
+//which colours, candidates and solutions need
+func runRender(e *env, args []string) int {
+	fs := e.newFlagSet("render")
+	input := addInputFlags(fs)
+	style := fs.String("style", "plain", "how to draw the grid: plain, unicode for box-drawing lines or ascii for - | + lines")
+	colour := fs.Bool("colour", false, "colour the digits of every sub-sudoku, and of the overlaps, with ANSI escape codes")
+	candidates := fs.Bool("candidates", false, "draw the candidates of every empty cell")
+	solve := fs.Bool("solve", false, "draw the solution, the givens bold and the digits solved faint")
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+	var options sudoku.RenderOptions
+	switch *style {
+	case "plain", "unicode":
+	case "ascii":
+		options.ASCII = true
+	default:
+		fmt.Fprintf(e.stderr, "%s: unknown style %q\n", fs.Name(), *style)
+		return exitUsage
+	}
+
+	var samurai *sudoku.SamuraiSudoku
+	var code int
+	if *candidates || *solve {
+		samurai, code = e.readPuzzle(fs, input)
+	} else {
+		samurai, code = e.readGrid(fs, input)
+	}
+	if code != exitOK {
+		return code
+	}
+	if *style == "plain" && !*colour && !*candidates && !*solve {
+		fmt.Fprint(e.stdout, samurai.Layout().Format(samurai.Grid()))
+		return exitOK
+	}
+
+	options.Colour = *colour
+	if *candidates {
+		c, err := sudoku.NewCandidates(samurai)
+		if err != nil {
+			return e.fail(fs, err)
+		}
+		options.Candidates = c
+	}
+	if *solve {
+		// the solver fills the puzzle's grid in place
+		for _, row := range samurai.Grid() {
+			options.Givens = append(options.Givens, append([]int(nil), row...))
+		}
+		solution, err := sudoku.GlobalSolveSamuraiSudokuContext(context.Background(), samurai)
+		if err != nil {
+			return e.fail(fs, err)
+		}
+		samurai.SetGrid(solution)
+	}
+	fmt.Fprint(e.stdout, samurai.Render(options))
+	return exitOK
+}
+
+//runImage draws a puzzle for print, with its solution in the empty cells if asked for
+func runImage(e *env, args []string) int {
+	fs := e.newFlagSet("image")
+	input := addInputFlags(fs)
+	format := fs.String("format", "png", "image format: png or svg")
+	cellSize := fs.Int("cell-size", 0, "side of a cell in pixels, or SVG user units (default 40)")
+	solve := fs.Bool("solve", false, "draw the solution in the empty cells, lighter than the givens")
+	fontFile := fs.String("font", "", "TrueType or OpenType font file the digits of PNGs are drawn with (default Go Regular)")
+	boldFontFile := fs.String("bold-font", "", "font file the givens of PNGs are drawn with (default --font, or Go Bold)")
+	fontFamily := fs.String("font-family", "", "CSS font family the digits of SVGs are written in (default sans-serif)")
+	output := fs.String("o", "", "file to write the image to, stdout if empty")
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+
+	options := sudoku.ImageOptions{CellSize: *cellSize, FontFamily: *fontFamily}
+	switch strings.ToLower(*format) {
+	case "png":
+		options.Format = sudoku.ImagePNG
+	case "svg":
+		options.Format = sudoku.ImageSVG
+	default:
+		fmt.Fprintf(e.stderr, "%s: unknown image format %q\n", fs.Name(), *format)
+		return exitUsage
+	}
+	for _, font := range []struct {
+		file string
+		data *[]byte
+	}{{*fontFile, &options.Font}, {*boldFontFile, &options.BoldFont}} {
+		if font.file == "" {
+			continue
+		}
+		data, err := os.ReadFile(font.file)
+		if err != nil {
+			return e.fail(fs, err)
+		}
+		*font.data = data
+	}
+
+	var samurai *sudoku.SamuraiSudoku
+	var code int
+	if *solve {
+		samurai, code = e.readPuzzle(fs, input)
+	} else {
+		samurai, code = e.readGrid(fs, input)
+	}
+	if code != exitOK {
+		return code
+	}
+	if *solve {
+		// the solver fills the puzzle's grid in place, the givens are put back once it is done
+		var givens sudoku.Grid
+		for _, row := range samurai.Grid() {
+			givens = append(givens, append([]int(nil), row...))
+		}
+		solution, err := sudoku.GlobalSolveSamuraiSudokuContext(context.Background(), samurai)
+		if err != nil {
+			return e.fail(fs, err)
+		}
+		options.Solution = solution
+		samurai.SetGrid(givens)
+	}
+
+	return e.writeOutput(fs, *output, func(w io.Writer) error {
+		return sudoku.WriteImage(w, samurai, options)
+	})
+}
+
+//runTikZ writes a puzzle as a TikZ picture, with its solution or its candidates in the empty cells if asked for
+func runTikZ(e *env, args []string) int {
+	fs := e.newFlagSet("tikz")
+	input := addInputFlags(fs)
+	cellSize := fs.Float64("cell-size", 0, "side of a cell in centimetres (default 0.6)")
+	solve := fs.Bool("solve", false, "write the solution in the empty cells, lighter than the givens")
+	candidates := fs.Bool("candidates", false, "write the candidates of the empty cells the solution doesn't fill")
+	document := fs.Bool("document", false, "wrap the picture in a standalone LaTeX document")
+	output := fs.String("o", "", "file to write the picture to, stdout if empty")
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+
+	options := sudoku.TikZOptions{CellSize: *cellSize, Document: *document}
+	var samurai *sudoku.SamuraiSudoku
+	var code int
+	if *candidates || *solve {
+		samurai, code = e.readPuzzle(fs, input)
+	} else {
+		samurai, code = e.readGrid(fs, input)
+	}
+	if code != exitOK {
+		return code
+	}
+	if *candidates {
+		c, err := sudoku.NewCandidates(samurai)
+		if err != nil {
+			return e.fail(fs, err)
+		}
+		options.Candidates = c
+	}
+	if *solve {
+		// the solver fills the puzzle's grid in place, the givens are put back once it is done
+		var givens sudoku.Grid
+		for _, row := range samurai.Grid() {
+			givens = append(givens, append([]int(nil), row...))
+		}
+		solution, err := sudoku.GlobalSolveSamuraiSudokuContext(context.Background(), samurai)
+		if err != nil {
+			return e.fail(fs, err)
+		}
+		options.Solution = solution
+		samurai.SetGrid(givens)
+	}
+
+	return e.writeOutput(fs, *output, func(w io.Writer) error {
+		return sudoku.WriteTikZ(w, samurai, options)
+	})
+}
+
+//runBooklet writes a PDF booklet of the puzzles in the files given, or of puzzles it generates when there are none
+func runBooklet(e *env, args []string) int {
+	fs := e.newFlagSet("booklet")
+	input := addInputFlags(fs)
+	count := fs.Int("n", 6, "number of puzzles to generate when no file is given")
+	seed := fs.Int64("seed", 0, "seed of the first puzzle generated, the next ones using the following seeds, 0 for random seeds")
+	minClues := fs.Int("min-clues", 0, "stop removing clues from the puzzles generated once they are down to this many")
+	title := fs.String("title", "", "title written at the top of the pages of puzzles")
+	output := fs.String("o", "", "file to write the booklet to, stdout if empty")
+	// any number of files may follow the flags
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	var puzzles []*sudoku.SamuraiSudoku
+	for _, path := range fs.Args() {
+		samurai, code := e.readGridFile(fs, input, path)
+		if code != exitOK {
+			return code
+		}
+		puzzles = append(puzzles, samurai)
+	}
+	if len(puzzles) == 0 {
+		layout, code := e.layout(fs, *input.layout)
+		if code != exitOK {
+			return code
+		}
+		for i := 0; i < *count; i++ {
+			options := sudoku.GenerateOptions{MinClues: *minClues, Layout: layout}
+			if *seed != 0 {
+				options.Seed = *seed + int64(i)
+			}
+			grid, err := sudoku.GenerateSamuraiSudokuContext(context.Background(), options)
+			if err != nil {
+				return e.fail(fs, err)
+			}
+			samurai := &sudoku.SamuraiSudoku{}
+			samurai.SetLayout(layout)
+			samurai.SetGrid(grid)
+			puzzles = append(puzzles, samurai)
+		}
+	}
+
+	return e.writeOutput(fs, *output, func(w io.Writer) error {
+		return sudoku.WriteBooklet(context.Background(), w, puzzles, sudoku.BookletOptions{Title: *title})
+	})
+}
+
+func runConvert(e *env, args []string) int {
+	fs := e.newFlagSet("convert")
+	input := addInputFlags(fs)
+	out := fs.String("out", "json", "output format: text, line or json")
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+
+	samurai, code := e.readGrid(fs, input)
+	if code != exitOK {
+		return code
+	}
+	return e.writeGrid(fs, samurai, *out)
+}
+
+func runTrace(e *env, args []string) int {
+	fs := e.newFlagSet("trace")
+	flags := addSolveFlags(fs)
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+
+	samurai, code := e.solve(fs, flags)
+	if code != exitOK {
+		return code
+	}
+	if err := sudoku.WriteTrace(e.stdout, samurai); err != nil {
+		return e.fail(fs, err)
+	}
+	return exitOK
+}
+
+func runChart(e *env, args []string) int {
+	fs := e.newFlagSet("chart")
+	flags := addSolveFlags(fs)
+	format := fs.String("format", "png", "image format: png or svg")
+	threads := fs.Bool("threads", false, "draw a series per thread")
+	title := fs.String("title", "", "chart title")
+	width := fs.Int("width", 0, "chart width in pixels")
+	height := fs.Int("height", 0, "chart height in pixels")
+	output := fs.String("o", "", "file to write the chart to, stdout if empty")
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+
+	options := sudoku.ChartOptions{Title: *title, Width: *width, Height: *height}
+	switch strings.ToLower(*format) {
+	case "png":
+		options.Format = sudoku.ChartPNG
+	case "svg":
+		options.Format = sudoku.ChartSVG
+	default:
+		fmt.Fprintf(e.stderr, "%s: unknown chart format %q\n", fs.Name(), *format)
+		return exitUsage
+	}
+
+	samurai, code := e.solve(fs, flags)
+	if code != exitOK {
+		return code
+	}
+
+	write := sudoku.WriteGraph
+	if *threads {
+		write = sudoku.WriteMultiThreadedGraph
+	}
+	return e.writeOutput(fs, *output, func(w io.Writer) error {
+		return write(w, samurai, options)
+	})
+}
+
+func runLive(e *env, args []string) int {
+	fs := e.newFlagSet("live")
+	addr := fs.String("addr", "localhost:8080", "address to serve the page on")
+	input := addInputFlags(fs)
+	solver := fs.String("solver", "concurrent", "solver used unless the page picks another: global, sequential, concurrent or double")
+	delay := fs.Duration("delay", 0, "pause after every move unless the page picks another")
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+	if _, ok := sudoku.Solvers[*solver]; !ok {
+		fmt.Fprintf(e.stderr, "%s: unknown solver %q\n", fs.Name(), *solver)
+		return exitUsage
+	}
+
+	samurai, code := e.readPuzzle(fs, input)
+	if code != exitOK {
+		return code
+	}
+
+	fmt.Fprintf(e.stderr, "serving on http://%s\n", *addr)
+	err := http.ListenAndServe(*addr, live.New(samurai.Grid(), live.Options{Solver: *solver, Delay: *delay, Layout: samurai.Layout(), Variant: samurai.Variant()}))
+	return e.fail(fs, err)
+}
+
+//runPlay plays a puzzle in the terminal, keys being read from stdin. With --save the game is saved to a file on quitting,
+//and resumed from it rather than read from the puzzle when the file exists
+func runPlay(e *env, args []string) int {
+	fs := e.newFlagSet("play")
+	input := addInputFlags(fs)
+	save := fs.String("save", "", "file the game is saved to on quitting, and resumed from if it exists")
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+
+	game, code := e.game(fs, input, *save)
+	if code != exitOK {
+		return code
+	}
+	if f, ok := e.stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		state, err := term.MakeRaw(int(f.Fd()))
+		if err != nil {
+			return e.fail(fs, err)
+		}
+		defer term.Restore(int(f.Fd()), state)
+	}
+	if err := tui.Play(context.Background(), game, e.stdin, e.stdout); err != nil {
+		return e.fail(fs, err)
+	}
+
+	if *save != "" {
+		state, err := game.State()
+		if err != nil {
+			return e.fail(fs, err)
+		}
+		data, err := json.Marshal(state)
+		if err != nil {
+			return e.fail(fs, err)
+		}
+		if err := os.WriteFile(*save, data, 0o644); err != nil {
+			return e.fail(fs, err)
+		}
+	}
+	if game.Solved() {
+		fmt.Fprintf(e.stdout, "solved in %s\n", game.Elapsed().Round(time.Second))
+	}
+	return exitOK
+}
+
+//game resumes the game saved to the file named save if there is one, or starts a game of the puzzle read
+func (e *env) game(fs *flag.FlagSet, input inputFlags, save string) (*sudoku.Game, int) {
+	if save != "" {
+		data, err := os.ReadFile(save)
+		switch {
+		case err == nil:
+			var state sudoku.GameState
+			if err := json.Unmarshal(data, &state); err != nil {
+				fmt.Fprintf(e.stderr, "%s: %s: %v\n", fs.Name(), save, err)
+				return nil, exitInvalid
+			}
+			game, err := sudoku.RestoreGame(state)
+			if err != nil {
+				return nil, e.fail(fs, err)
+			}
+			return game, exitOK
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, e.fail(fs, err)
+		}
+	}
+
+	samurai, code := e.readPuzzle(fs, input)
+	if code != exitOK {
+		return nil, code
+	}
+	game, err := sudoku.NewGame(samurai)
+	if err != nil {
+		return nil, e.fail(fs, err)
+	}
+	return game, exitOK
+}
+
+func runWatch(e *env, args []string) int {
+	fs := e.newFlagSet("watch")
+	flags := addSolveFlags(fs)
+	delay := fs.Duration("delay", 10*time.Millisecond, "pause after every move")
+	if code, ok := e.parse(fs, args); !ok {
+		return code
+	}
+	solve, ok := sudoku.Solvers[*flags.solver]
+	if !ok {
+		fmt.Fprintf(e.stderr, "%s: unknown solver %q\n", fs.Name(), *flags.solver)
+		return exitUsage
+	}
+	samurai, code := e.readPuzzle(fs, flags.input)
+	if code != exitOK {
+		return code
+	}
+
+	ctx, cancel := withTimeout(*flags.timeout)
+	defer cancel()
+	if err := tui.Watch(ctx, samurai, solve, e.stdout, *delay); err != nil {
+		return e.fail(fs, err)
+	}
+	return exitOK
+}
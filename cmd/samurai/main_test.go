@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sudoku "github.com/alielbashir/samurai-sudoku-go"
+)
+
+func TestRun(t *testing.T) {
+	puzzle, err := ioutil.ReadFile("../../sudoku.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a 6 in the middle box of the centre, which already holds one
+	invalid := strings.Replace(string(puzzle), "**6*5*2**", "**6*562**", 1)
+
+	testCases := []struct {
+		name   string
+		args   []string
+		stdin  string
+		code   int
+		stdout string // expected prefix of stdout
+	}{
+		{"no command", nil, "", exitUsage, ""},
+		{"unknown command", []string{"frobnicate"}, "", exitUsage, ""},
+		{"help", []string{"help"}, "", exitOK, "Usage: samurai"},
+		{"solve", []string{"solve"}, string(puzzle), exitOK, "165798423739648125\n"},
+		{"solve file", []string{"solve", "--out", "line", "../../sudoku.txt"}, "", exitOK, "165798423"},
+		{"solve unknown solver", []string{"solve", "--solver", "magic"}, string(puzzle), exitUsage, ""},
+		{"solve invalid", []string{"solve"}, invalid, exitInvalid, ""},
+		{"solve malformed", []string{"solve"}, "123", exitInvalid, ""},
+		{"solve missing file", []string{"solve", "missing.txt"}, "", exitError, ""},
+		{"validate", []string{"validate"}, string(puzzle), exitOK, "valid\n"},
+		{"validate invalid", []string{"validate"}, invalid, exitInvalid, ""},
+		{"count solutions", []string{"count-solutions"}, string(puzzle), exitOK, "1\n"},
+		{"hint", []string{"hint"}, string(puzzle), exitOK, "naked single: "},
+		{"logic", []string{"logic"}, string(puzzle), exitOK, "1. naked single: "},
+		{"logic stuck", []string{"logic", "--layout", "twin-4", "--in", "line"}, strings.Repeat(".", 28), exitStuck, "\nstuck after 0 steps"},
+		{"image", []string{"image"}, string(puzzle), exitOK, "\x89PNG"},
+		{"image svg", []string{"image", "--format", "svg", "--solve", "--cell-size", "30"}, string(puzzle), exitOK, `<svg xmlns="http://www.w3.org/2000/svg" width="637.5"`},
+		{"image unknown format", []string{"image", "--format", "gif"}, string(puzzle), exitUsage, ""},
+		{"image missing font", []string{"image", "--font", "missing.ttf"}, string(puzzle), exitError, ""},
+		{"image malformed", []string{"image"}, "123", exitInvalid, ""},
+		{"tikz", []string{"tikz"}, string(puzzle), exitOK, "\\begin{tikzpicture}[x=0.6cm, y=-0.6cm,"},
+		{"tikz document", []string{"tikz", "--document", "--solve", "--candidates", "--cell-size", "0.5"}, string(puzzle), exitOK, "\\documentclass[tikz, border=2mm]{standalone}"},
+		{"tikz candidates invalid", []string{"tikz", "--candidates"}, invalid, exitInvalid, ""},
+		{"tikz malformed", []string{"tikz"}, "123", exitInvalid, ""},
+		{"booklet", []string{"booklet", "--title", "Samurai", "../../sudoku.txt", "-"}, string(puzzle), exitOK, "%PDF-1.4"},
+		{"booklet generated", []string{"booklet", "-n", "2", "--seed", "1", "--layout", "twin-4"}, "", exitOK, "%PDF-1.4"},
+		{"booklet not unique", []string{"booklet", "--layout", "twin-4", "--in", "line", "-"}, strings.Repeat(".", 28), exitNotUnique, ""},
+		{"booklet no puzzles", []string{"booklet", "-n", "0"}, "", exitError, ""},
+		{"booklet missing file", []string{"booklet", "missing.txt"}, "", exitError, ""},
+		{"convert", []string{"convert", "--out", "json"}, string(puzzle), exitOK, "[[0,0,5,7"},
+		{"render", []string{"render"}, string(puzzle), exitOK, "0 0 5 7"},
+		{"trace", []string{"trace", "--solver", "sequential"}, string(puzzle), exitOK, "time (microseconds)"},
+		{"chart", []string{"chart", "--format", "svg"}, string(puzzle), exitOK, "<svg"},
+		{"generate", []string{"generate", "--seed", "1", "--min-clues", "300", "--out", "json"}, "", exitOK, "[["},
+		{"solve twin", []string{"solve", "--layout", "twin", "--out", "line", "../../testdata/twin.txt"}, "", exitOK, ""},
+		{"solve unknown layout", []string{"solve", "--layout", "pinwheel"}, string(puzzle), exitUsage, ""},
+		{"solve wrong layout", []string{"solve", "--layout", "butterfly"}, string(puzzle), exitInvalid, ""},
+		{"validate windmill", []string{"validate", "--layout", "kazaguruma", "../../testdata/windmill.txt"}, "", exitOK, "valid\n"},
+		{"generate butterfly", []string{"generate", "--layout", "butterfly", "--seed", "1", "--out", "json"}, "", exitOK, "[["},
+		{"generate 16x16", []string{"generate", "--layout", "twin-16", "--seed", "1", "--min-clues", "400"}, "", exitOK, ""},
+		{"render unicode", []string{"render", "--style", "unicode"}, string(puzzle), exitOK, "┏━━━━━━━┳"},
+		{"render ascii", []string{"render", "--style", "ascii"}, string(puzzle), exitOK, "+=======+"},
+		{"render unknown style", []string{"render", "--style", "fancy"}, string(puzzle), exitUsage, ""},
+		{"render solution", []string{"render", "--solve"}, string(puzzle), exitOK, "┏━━━━━━━┳━━━━━━━┳━━━━━━━┓       ┏━━━━━━━┳━━━━━━━┳━━━━━━━┓\n┃ \x1b[2m1\x1b[0m"},
+		{"render candidates", []string{"render", "--candidates", "--style", "ascii", "--layout", "twin-4", "--in", "line"}, "1" + strings.Repeat(".", 27), exitOK, "+=======+=======+\n#    .2 |"},
+		{"render candidates invalid", []string{"render", "--candidates"}, invalid, exitInvalid, ""},
+		{"render 4x4", []string{"render", "--layout", "twin-4", "--in", "line"}, "1234" + strings.Repeat(".", 24), exitOK, "1 2 3 4"},
+		{"solve unknown size", []string{"solve", "--layout", "samurai-7"}, string(puzzle), exitUsage, ""},
+		{"generate diagonals", []string{"generate", "--diagonals", "centre", "--seed", "1", "--min-clues", "300"}, "", exitOK, "diagonals: centre\n"},
+		{"generate windows", []string{"generate", "--windows", "centre", "--seed", "1", "--min-clues", "300"}, "", exitOK, "windows: centre\n"},
+		{"generate unknown window", []string{"generate", "--windows", "middle"}, "", exitUsage, ""},
+		{"generate unknown diagonal", []string{"generate", "--diagonals", "middle"}, "", exitUsage, ""},
+		{"generate diagonal outside the layout", []string{"generate", "--layout", "twin", "--diagonals", "centre"}, "", exitUsage, ""},
+		{"solve unknown header", []string{"solve"}, "killer: yes\n" + string(puzzle), exitInvalid, ""},
+		{"validate diagonals", []string{"validate"}, "diagonals: all\n" + string(puzzle), exitInvalid, ""},
+		{"solve killer", []string{"solve"}, "cage: 7 r1c1 r1c2\n" + string(puzzle), exitOK, "cage: 7 r1c1 r1c2\n165798423"},
+		{"validate wrong cage sum", []string{"validate"}, "cage: 11 r1c3 r1c4\n" + string(puzzle), exitInvalid, ""},
+		{"validate cage in a gap", []string{"validate"}, "cage: 3 r1c1 r1c10\n" + string(puzzle), exitInvalid, ""},
+		{"solve kropki", []string{"solve"}, "marker: white r1c2 r1c3\n" + string(puzzle), exitOK, "marker: white r1c2 r1c3\n165798423"},
+		{"validate broken marker", []string{"validate"}, "marker: v r1c3 r1c4\n" + string(puzzle), exitInvalid, ""},
+		{"solve thermo", []string{"solve"}, "thermo: r1c1 r1c2\n" + string(puzzle), exitOK, "thermo: r1c1 r1c2\n165798423"},
+		{"validate broken arrow", []string{"validate"}, "arrow: r1c3 r1c4\n" + string(puzzle), exitInvalid, ""},
+		{"solve parity", []string{"solve"}, "odd: r1c1\neven: r1c2\n" + string(puzzle), exitOK, "odd: r1c1\neven: r1c2\n165798423"},
+		{"validate odd cell", []string{"validate"}, "odd: r1c3 r1c4\ndigits: 24 r1c4\n" + string(puzzle), exitInvalid, ""},
+		{"validate jigsaw", []string{"validate", "../../testdata/jigsaw.txt"}, "", exitOK, "valid\n"},
+		{"solve jigsaw", []string{"solve", "--out", "json", "../../testdata/jigsaw.txt"}, "", exitOK, `{"jigsaw":[{"position":"centre"`},
+		{"live unknown solver", []string{"live", "--solver", "magic"}, string(puzzle), exitUsage, ""},
+		{"live invalid", []string{"live"}, invalid, exitInvalid, ""},
+		{"play", []string{"play", "../../sudoku.txt"}, "1q", exitOK, "\x1b[?1049h"},
+		{"play not unique", []string{"play", "--layout", "twin-4", "--in", "line"}, strings.Repeat(".", 28), exitNotUnique, ""},
+		{"watch", []string{"watch", "--delay", "0", "../../sudoku.txt"}, "", exitOK, "\x1b[?25l"},
+		{"watch unknown solver", []string{"watch", "--solver", "magic", "../../sudoku.txt"}, "", exitUsage, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+			if code != tc.code {
+				t.Fatalf("want exit code %d, got %d, stderr:\n%s", tc.code, code, stderr.String())
+			}
+			if !strings.HasPrefix(stdout.String(), tc.stdout) {
+				t.Fatalf("want stdout starting with %q, got %q", tc.stdout, stdout.String())
+			}
+		})
+	}
+}
+
+func TestRunValidateNotUnique(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	// an empty samurai sudoku has many solutions
+	in := strings.Repeat(".", 369)
+	if code := run([]string{"validate", "--in", "line"}, strings.NewReader(in), &stdout, &stderr); code != exitNotUnique {
+		t.Fatalf("want exit code %d, got %d", exitNotUnique, code)
+	}
+}
+
+func TestRunBookletOutput(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "booklet.pdf")
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"booklet", "-o", output, "../../sudoku.txt"}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("want exit code %d, got %d, stderr:\n%s", exitOK, code, stderr.String())
+	}
+	if data, err := ioutil.ReadFile(output); err != nil || !bytes.HasPrefix(data, []byte("%PDF-1.4")) {
+		t.Fatalf("want the booklet written to %s, got %v", output, err)
+	}
+	// an empty twin sudoku has many solutions, the booklet written before is removed rather than left truncated
+	in := strings.NewReader(strings.Repeat(".", 28))
+	if code := run([]string{"booklet", "-o", output, "--layout", "twin-4", "--in", "line", "-"}, in, &stdout, &stderr); code != exitNotUnique {
+		t.Fatalf("want exit code %d, got %d", exitNotUnique, code)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Fatalf("want no booklet left at %s, got %v", output, err)
+	}
+}
+
+func TestRunPlaySave(t *testing.T) {
+	save := filepath.Join(t.TempDir(), "game.json")
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"play", "--save", save, "../../sudoku.txt"}, strings.NewReader("1q"), &stdout, &stderr); code != exitOK {
+		t.Fatalf("want exit code %d, got %d, stderr:\n%s", exitOK, code, stderr.String())
+	}
+	// the game is resumed from the save, the keys coming from stdin, with the cursor on the first empty cell
+	if code := run([]string{"play", "--save", save}, strings.NewReader("6q"), &stdout, &stderr); code != exitOK {
+		t.Fatalf("want exit code %d, got %d, stderr:\n%s", exitOK, code, stderr.String())
+	}
+	data, err := ioutil.ReadFile(save)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var state sudoku.GameState
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatal(err)
+	}
+	if got := state.Grid[0][:3]; got[0] != 1 || got[1] != 6 || len(state.Undo) != 2 {
+		t.Fatalf("want both games' moves saved, got %v and %d moves", got, len(state.Undo))
+	}
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"log"
+	"os"
+
 	. "github.com/alielbashir/samurai-sudoku-go"
 )
 
@@ -12,6 +15,13 @@ func main() {
 	samuraiSudoku.SetGrid(samuraiGrid)
 
 	DoubleThreadSolveSamuraiSudoku(&samuraiSudoku)
-	WriteGraph(&samuraiSudoku)
 
+	f, err := os.Create("output.png")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	if err := WriteGraph(f, &samuraiSudoku, ChartOptions{Format: ChartPNG}); err != nil {
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,463 @@
+package sudoku
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// GridFormat is a text representation of a samurai sudoku grid.
+// Text and line formats write digits with the layout's Symbols, e.g. 1-9A-G for 16x16 sub-sudokus and A-Y for 25x25 ones
+type GridFormat int
+
+const (
+	TextFormat GridFormat = iota // A line per row holding only the cells of the sub-sudokus, '*' for empty cells, as in sudoku.txt
+	LineFormat                   // Every cell outside the gaps on a single line, row by row, '.' for empty cells
+	JSONFormat                   // The Grid as a JSON array of rows, with -1 for gaps and 0 for empty cells
+)
+
+func (f GridFormat) String() string {
+	switch f {
+	case TextFormat:
+		return "text"
+	case LineFormat:
+		return "line"
+	case JSONFormat:
+		return "json"
+	}
+	return "unknown"
+}
+
+//ParseGridFormat returns the GridFormat named name, as returned by GridFormat.String
+func ParseGridFormat(name string) (GridFormat, error) {
+	for _, format := range []GridFormat{TextFormat, LineFormat, JSONFormat} {
+		if strings.EqualFold(name, format.String()) {
+			return format, nil
+		}
+	}
+	return 0, fmt.Errorf("sudoku: unknown grid format %q", name)
+}
+
+//ReadSamuraiGrid reads a samurai sudoku grid in TextFormat from r
+func ReadSamuraiGrid(r io.Reader) (Grid, error) {
+	return ReadGrid(r, TextFormat)
+}
+
+//ReadGrid reads a samurai sudoku grid in the given format from r.
+//The grid's shape is checked, but not whether its digits break any rule, see Validate
+func ReadGrid(r io.Reader, format GridFormat) (Grid, error) {
+	return ReadLayoutGrid(r, Samurai, format)
+}
+
+//ReadLayoutGrid reads a grid shaped like layout in the given format from r, with digits written with the layout's Symbols in either case.
+//The grid's shape is checked, but not whether its digits break any rule, see ValidateLayout.
+//A puzzle's header is checked but left out, see ReadPuzzle
+func ReadLayoutGrid(r io.Reader, layout *Layout, format GridFormat) (Grid, error) {
+	samurai, err := ReadPuzzle(r, layout, format)
+	if err != nil {
+		return nil, err
+	}
+	return samurai.Grid(), nil
+}
+
+//ReadPuzzle reads a puzzle shaped like layout in the given format from r, with the variant its header asks for.
+//In text and line formats, the grid may come after header lines of the form "name: value":
+//
+//	diagonals: centre, top-left   the two diagonals of these sub-sudokus hold every digit once, "all" for every sub-sudoku
+//	windows: centre               the windows of these sub-sudokus hold every digit once, "all" for every sub-sudoku
+//	cage: 15 r1c1 r1c2 r2c1       a killer cage whose digits add up to 15, its cells counted from 1 on the whole grid
+//	jigsaw: centre 111123333 ...  irregular regions replacing the boxes of a sub-sudoku, a word of region symbols per row
+//	marker: white r1c1 r1c2       a white or black Kropki dot, or an x or v sum, between two adjacent cells
+//	negative: white, black        adjacent cells without a marker must not have the relation of these kinds of markers
+//	thermo: r1c1 r1c2 r2c3        a thermometer from its bulb, along which digits strictly increase
+//	arrow: r1c1 r1c2 r1c3         an arrow from its circle, which holds the sum of the digits along the rest of the arrow
+//	whispers: r1c1 r2c2 r3c3      a German whispers line, along which neighbouring digits differ by at least 5
+//	even: r1c1 r2c2               cells holding even digits, "odd" for cells holding odd digits
+//	digits: 1357 r1c1 r2c2        cells holding only these digits, written with the layout's symbols
+//
+//In JSON format, the grid may be the "grid" field of an object holding the header's names as fields,
+//e.g. {"diagonals": ["centre"], "windows": ["centre"], "cages": [{"sum": 15, "cells": [{"row": 0, "column": 0}, ...]}],
+//"jigsaw": [{"position": "centre", "regions": [[1, 1, 1, 1, 2, 3, 3, 3, 3], ...]}],
+//"markers": [{"kind": "white", "cells": [{"row": 0, "column": 0}, {"row": 0, "column": 1}]}], "negative": ["white"],
+//"thermos", "arrows" and "whispers" as lists of lines, each a list of cells, e.g. "thermos": [[{"row": 0, "column": 0}, ...]],
+//"restrictions": [{"cells": [{"row": 0, "column": 0}], "digits": [2, 4, 6, 8]}],
+//"grid": [[...]]}, with cells counted from 0.
+//The grid's shape is checked, but not whether its digits break any rule, see SamuraiSudoku.Validate
+func ReadPuzzle(r io.Reader, layout *Layout, format GridFormat) (*SamuraiSudoku, error) {
+	buffer, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var grid Grid
+	var variant *Variant
+	switch format {
+	case TextFormat, LineFormat:
+		var contents string
+		variant, contents, err = parseHeader(string(buffer), layout)
+		if err != nil {
+			return nil, err
+		}
+		if format == TextFormat {
+			grid, err = parseText(contents, layout)
+		} else {
+			grid, err = parseLine(contents, layout)
+		}
+	case JSONFormat:
+		grid, variant, err = parseJSON(buffer, layout)
+	default:
+		err = fmt.Errorf("sudoku: unknown grid format %d", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := checkShape(grid, layout); err != nil {
+		return nil, err
+	}
+
+	samurai := &SamuraiSudoku{}
+	samurai.SetLayout(layout)
+	if err := samurai.SetVariant(variant); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGrid, err)
+	}
+	samurai.SetGrid(grid)
+	return samurai, nil
+}
+
+//parseHeader parses the header lines at the start of contents, returning the variant they ask for, nil if none, and the lines after them
+func parseHeader(contents string, layout *Layout) (*Variant, string, error) {
+	var variant *Variant
+	for {
+		line := contents
+		rest := ""
+		if i := strings.IndexByte(contents, '\n'); i >= 0 {
+			line, rest = contents[:i], contents[i+1:]
+		}
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			return variant, contents, nil
+		}
+		if variant == nil {
+			variant = &Variant{}
+		}
+		name, value := strings.ToLower(strings.TrimSpace(line[:i])), line[i+1:]
+		switch name {
+		case "diagonals":
+			positions, err := parsePositions(value, layout)
+			if err != nil {
+				return nil, "", fmt.Errorf("%w: %v", ErrInvalidGrid, err)
+			}
+			variant.Diagonals = append(variant.Diagonals, positions...)
+		case "windows":
+			positions, err := parsePositions(value, layout)
+			if err != nil {
+				return nil, "", fmt.Errorf("%w: %v", ErrInvalidGrid, err)
+			}
+			variant.Windows = append(variant.Windows, positions...)
+		case "cage":
+			cage, err := parseCage(value)
+			if err != nil {
+				return nil, "", fmt.Errorf("%w: %v", ErrInvalidGrid, err)
+			}
+			variant.Cages = append(variant.Cages, cage)
+		case "jigsaw":
+			jigsaw, err := parseJigsaw(value, layout.symbols)
+			if err != nil {
+				return nil, "", fmt.Errorf("%w: %v", ErrInvalidGrid, err)
+			}
+			variant.Jigsaws = append(variant.Jigsaws, jigsaw)
+		case "marker":
+			marker, err := parseMarker(value)
+			if err != nil {
+				return nil, "", fmt.Errorf("%w: %v", ErrInvalidGrid, err)
+			}
+			variant.Markers = append(variant.Markers, marker)
+		case "negative":
+			kinds, err := parseMarkerKinds(value)
+			if err != nil {
+				return nil, "", fmt.Errorf("%w: %v", ErrInvalidGrid, err)
+			}
+			variant.Negative = append(variant.Negative, kinds...)
+		case "thermo", "arrow", "whispers":
+			constraint, err := parseLineConstraint(name, value)
+			if err != nil {
+				return nil, "", fmt.Errorf("%w: %v", ErrInvalidGrid, err)
+			}
+			variant.Constraints = append(variant.Constraints, constraint)
+		case "even", "odd", "digits":
+			restriction, err := parseRestriction(name, value, layout.symbols)
+			if err != nil {
+				return nil, "", fmt.Errorf("%w: %v", ErrInvalidGrid, err)
+			}
+			variant.Restrictions = append(variant.Restrictions, restriction)
+		default:
+			return nil, "", fmt.Errorf("%w: unknown header %q", ErrInvalidGrid, name)
+		}
+		contents = rest
+	}
+}
+
+// jsonPuzzle A puzzle in JSON format, when it has more than a grid
+type jsonPuzzle struct {
+	Diagonals    []string      `json:"diagonals,omitempty"`
+	Windows      []string      `json:"windows,omitempty"`
+	Cages        []Cage        `json:"cages,omitempty"`
+	Jigsaws      []jsonJigsaw  `json:"jigsaw,omitempty"`
+	Markers      []Marker      `json:"markers,omitempty"`
+	Negative     []MarkerKind  `json:"negative,omitempty"`
+	Thermos      []Thermo      `json:"thermos,omitempty"`
+	Arrows       []Arrow       `json:"arrows,omitempty"`
+	Whispers     []Whispers    `json:"whispers,omitempty"`
+	Restrictions []Restriction `json:"restrictions,omitempty"`
+	Grid         Grid          `json:"grid"`
+}
+
+// jsonJigsaw The jigsaw regions of a sub-sudoku in JSON format
+type jsonJigsaw struct {
+	Position string `json:"position"`
+	Regions  Grid   `json:"regions"`
+}
+
+//parseJSON parses a grid, or an object holding a grid and the variant's rules
+func parseJSON(buffer []byte, layout *Layout) (Grid, *Variant, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(buffer), []byte("{")) {
+		var grid Grid
+		if err := json.Unmarshal(buffer, &grid); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidGrid, err)
+		}
+		return grid, nil, nil
+	}
+
+	var puzzle jsonPuzzle
+	if err := json.Unmarshal(buffer, &puzzle); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidGrid, err)
+	}
+	variant := &Variant{Cages: puzzle.Cages, Markers: puzzle.Markers, Negative: puzzle.Negative, Restrictions: puzzle.Restrictions}
+	for _, name := range puzzle.Diagonals {
+		p, err := ParsePosition(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidGrid, err)
+		}
+		variant.Diagonals = append(variant.Diagonals, p)
+	}
+	for _, name := range puzzle.Windows {
+		p, err := ParsePosition(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidGrid, err)
+		}
+		variant.Windows = append(variant.Windows, p)
+	}
+	for _, j := range puzzle.Jigsaws {
+		p, err := ParsePosition(j.Position)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidGrid, err)
+		}
+		variant.Jigsaws = append(variant.Jigsaws, Jigsaw{Position: p, Regions: j.Regions})
+	}
+	for _, t := range puzzle.Thermos {
+		variant.Constraints = append(variant.Constraints, t)
+	}
+	for _, a := range puzzle.Arrows {
+		variant.Constraints = append(variant.Constraints, a)
+	}
+	for _, w := range puzzle.Whispers {
+		variant.Constraints = append(variant.Constraints, w)
+	}
+	return puzzle.Grid, variant, nil
+}
+
+//parseText parses rows made of the cells of the sub-sudokus each row goes through, the gaps are left out
+func parseText(contents string, layout *Layout) (Grid, error) {
+	lines := strings.Split(strings.ReplaceAll(contents, "\r\n", "\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	grid := layout.NewGrid()
+	if len(lines) != len(grid) {
+		return nil, fmt.Errorf("%w: want %d lines, got %d", ErrInvalidGrid, len(grid), len(lines))
+	}
+
+	for y, line := range lines {
+		chars := []rune(line)
+		i := 0
+		for x, num := range grid[y] {
+			if num == -1 {
+				continue
+			}
+			if i >= len(chars) {
+				return nil, fmt.Errorf("%w: line %d is too short", ErrInvalidGrid, y+1)
+			}
+			n, err := parseCell(chars[i], layout.symbols)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", y+1, err)
+			}
+			grid[y][x] = n
+			i++
+		}
+		if i != len(chars) {
+			return nil, fmt.Errorf("%w: line %d is too long", ErrInvalidGrid, y+1)
+		}
+	}
+	return grid, nil
+}
+
+//parseLine parses every cell outside the gaps from a single line
+func parseLine(contents string, layout *Layout) (Grid, error) {
+	chars := []rune(strings.TrimSpace(contents))
+	grid := layout.NewGrid()
+	i := 0
+	for y, row := range grid {
+		for x, num := range row {
+			if num == -1 {
+				continue
+			}
+			if i >= len(chars) {
+				return nil, fmt.Errorf("%w: line is too short", ErrInvalidGrid)
+			}
+			n, err := parseCell(chars[i], layout.symbols)
+			if err != nil {
+				return nil, err
+			}
+			grid[y][x] = n
+			i++
+		}
+	}
+	if i != len(chars) {
+		return nil, fmt.Errorf("%w: line is too long", ErrInvalidGrid)
+	}
+	return grid, nil
+}
+
+//parseCell parses a digit written with one of symbols, or one of the characters used for empty cells
+func parseCell(char rune, symbols string) (int, error) {
+	if char == '*' || char == '.' || char == '0' {
+		return 0, nil
+	}
+	if n, ok := parseSymbol(symbols, char); ok {
+		return n, nil
+	}
+	return 0, fmt.Errorf("%w: unexpected character %q", ErrInvalidGrid, char)
+}
+
+//WriteGrid writes grid to w in the given format, digits above 9 as letters from A
+func WriteGrid(w io.Writer, grid Grid, format GridFormat) error {
+	return writeGrid(w, grid, digitSymbols+letterSymbols, format)
+}
+
+//WriteLayoutGrid writes grid, shaped like layout, to w in the given format with the layout's Symbols
+func WriteLayoutGrid(w io.Writer, grid Grid, layout *Layout, format GridFormat) error {
+	return writeGrid(w, grid, layout.symbols, format)
+}
+
+//WritePuzzle writes the puzzle's grid to w in the given format with its layout's Symbols, after a header holding its variant if it has one
+func WritePuzzle(w io.Writer, samurai *SamuraiSudoku, format GridFormat) error {
+	variant := samurai.Variant()
+	if variant.IsZero() {
+		return WriteLayoutGrid(w, samurai.Grid(), samurai.Layout(), format)
+	}
+
+	buf := bytes.Buffer{}
+	switch format {
+	case TextFormat, LineFormat:
+		if len(variant.Diagonals) > 0 {
+			fmt.Fprintf(&buf, "diagonals: %s\n", formatPositions(variant.Diagonals))
+		}
+		if len(variant.Windows) > 0 {
+			fmt.Fprintf(&buf, "windows: %s\n", formatPositions(variant.Windows))
+		}
+		for _, cage := range variant.Cages {
+			fmt.Fprintf(&buf, "cage: %s\n", formatCage(cage))
+		}
+		for _, j := range variant.Jigsaws {
+			fmt.Fprintf(&buf, "jigsaw: %s\n", formatJigsaw(j, samurai.Layout().symbols))
+		}
+		for _, m := range variant.Markers {
+			fmt.Fprintf(&buf, "marker: %s\n", formatMarker(m))
+		}
+		if len(variant.Negative) > 0 {
+			fmt.Fprintf(&buf, "negative: %s\n", formatMarkerKinds(variant.Negative))
+		}
+		for i, constraint := range variant.Constraints {
+			line, ok := formatLineConstraint(constraint)
+			if !ok {
+				return fmt.Errorf("sudoku: constraint %d, a %T, can't be written in a puzzle", i+1, constraint)
+			}
+			fmt.Fprintln(&buf, line)
+		}
+		for _, r := range variant.Restrictions {
+			fmt.Fprintln(&buf, formatRestriction(r, samurai.Layout().symbols))
+		}
+	case JSONFormat:
+		puzzle := jsonPuzzle{Cages: variant.Cages, Markers: variant.Markers, Negative: variant.Negative, Restrictions: variant.Restrictions, Grid: samurai.Grid()}
+		for _, p := range variant.Diagonals {
+			puzzle.Diagonals = append(puzzle.Diagonals, p.String())
+		}
+		for _, p := range variant.Windows {
+			puzzle.Windows = append(puzzle.Windows, p.String())
+		}
+		for _, j := range variant.Jigsaws {
+			puzzle.Jigsaws = append(puzzle.Jigsaws, jsonJigsaw{Position: j.Position.String(), Regions: j.Regions})
+		}
+		for i, constraint := range variant.Constraints {
+			switch line := constraint.(type) {
+			case Thermo:
+				puzzle.Thermos = append(puzzle.Thermos, line)
+			case Arrow:
+				puzzle.Arrows = append(puzzle.Arrows, line)
+			case Whispers:
+				puzzle.Whispers = append(puzzle.Whispers, line)
+			default:
+				return fmt.Errorf("sudoku: constraint %d, a %T, can't be written in a puzzle", i+1, constraint)
+			}
+		}
+		if err := json.NewEncoder(&buf).Encode(puzzle); err != nil {
+			return err
+		}
+		_, err := buf.WriteTo(w)
+		return err
+	}
+	if err := writeGrid(&buf, samurai.Grid(), samurai.Layout().symbols, format); err != nil {
+		return err
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func writeGrid(w io.Writer, grid Grid, symbols string, format GridFormat) error {
+	buf := bytes.Buffer{}
+	switch format {
+	case TextFormat, LineFormat:
+		empty := "*"
+		if format == LineFormat {
+			empty = "."
+		}
+		for _, row := range grid {
+			for _, num := range row {
+				switch num {
+				case -1:
+				case 0:
+					buf.WriteString(empty)
+				default:
+					buf.WriteString(symbol(symbols, num))
+				}
+			}
+			if format == TextFormat {
+				buf.WriteString("\n")
+			}
+		}
+		if format == LineFormat {
+			buf.WriteString("\n")
+		}
+	case JSONFormat:
+		if err := json.NewEncoder(&buf).Encode(grid); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("sudoku: unknown grid format %d", format)
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
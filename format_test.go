@@ -0,0 +1,203 @@
+package sudoku
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadWriteGrid(t *testing.T) {
+	want := SamuraiGridFromFile("sudoku.txt")
+
+	for _, format := range []GridFormat{TextFormat, LineFormat, JSONFormat} {
+		t.Run(format.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteGrid(&buf, want, format); err != nil {
+				t.Fatalf("unexpected error writing: %v", err)
+			}
+			got, err := ReadGrid(&buf, format)
+			if err != nil {
+				t.Fatalf("unexpected error reading: %v", err)
+			}
+			if !reflect.DeepEqual(want, got) {
+				t.Fatalf("want\n%v\ngot\n%v ", want, got)
+			}
+		})
+	}
+}
+
+func TestReadWriteSymbols(t *testing.T) {
+	testCases := []struct {
+		layout string
+		line   string // First line of a twin puzzle in TextFormat
+	}{
+		{"twin-16", "123456789ABCDEFG"},
+		{"twin-25", "ABCDEFGHIJKLMNOPQRSTUVWXY"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.layout, func(t *testing.T) {
+			layout, err := ParseLayout(tc.layout)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := layout.NewGrid()
+			for x := range tc.line {
+				want[0][x] = x + 1
+			}
+
+			var buf bytes.Buffer
+			if err := WriteLayoutGrid(&buf, want, layout, TextFormat); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.SplitN(buf.String(), "\n", 2)[0]; got != tc.line {
+				t.Fatalf("want the first line written as %q, got %q", tc.line, got)
+			}
+			// letters are read in either case
+			got, err := ReadLayoutGrid(strings.NewReader(strings.ToLower(buf.String())), layout, TextFormat)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(want, got) {
+				t.Fatalf("want\n%s\ngot\n%s", layout.Format(want), layout.Format(got))
+			}
+		})
+	}
+
+	if got := (Grid{{1, 9, 10, 16}}).String(); got != "1 9 A G \n" {
+		t.Fatalf("want digits above 9 as letters, got %q", got)
+	}
+}
+
+func TestReadWritePuzzle(t *testing.T) {
+	want := &SamuraiSudoku{}
+	variant := &Variant{
+		Diagonals:    []Position{Centre, TopLeft},
+		Cages:        []Cage{{Sum: 3, Cells: []Cell{{0, 1}, {0, 2}}}, {Sum: 17, Cells: []Cell{{8, 8}, {9, 8}}}},
+		Jigsaws:      []Jigsaw{testJigsaw},
+		Windows:      []Position{Centre},
+		Markers:      []Marker{{Kind: WhiteDot, Cells: [2]Cell{{0, 2}, {0, 3}}}, {Kind: VSum, Cells: [2]Cell{{1, 0}, {2, 0}}}},
+		Negative:     []MarkerKind{WhiteDot, BlackDot},
+		Constraints:  testLines,
+		Restrictions: []Restriction{EvenCells(9, Cell{0, 0}, Cell{1, 1}), {Cells: []Cell{{2, 2}}, Digits: []int{1, 5}}},
+	}
+	if err := want.SetVariant(variant); err != nil {
+		t.Fatal(err)
+	}
+	want.SetGrid(SamuraiGridFromFile("sudoku.txt"))
+
+	for _, format := range []GridFormat{TextFormat, LineFormat, JSONFormat} {
+		t.Run(format.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WritePuzzle(&buf, want, format); err != nil {
+				t.Fatalf("unexpected error writing: %v", err)
+			}
+			if format != JSONFormat && !strings.HasPrefix(buf.String(), "diagonals: centre, top-left\nwindows: centre\ncage: 3 r1c2 r1c3\ncage: 17 r9c9 r10c9\njigsaw: centre 111123333 111222333") {
+				t.Fatalf("want diagonals, windows, cage and jigsaw headers, got %q", buf.String())
+			}
+			if format != JSONFormat && !strings.Contains(buf.String(), "\nmarker: white r1c3 r1c4\nmarker: v r2c1 r3c1\nnegative: white, black\n") {
+				t.Fatalf("want marker and negative headers, got %q", buf.String())
+			}
+			if format != JSONFormat && !strings.Contains(buf.String(), "\nthermo: r6c7 r7c8 r8c9 r9c9\narrow: r10c8 r9c7 r9c8\nwhispers: r7c7") {
+				t.Fatalf("want line headers, got %q", buf.String())
+			}
+			if format != JSONFormat && !strings.Contains(buf.String(), "\neven: r1c1 r2c2\ndigits: 15 r3c3\n") {
+				t.Fatalf("want restriction headers, got %q", buf.String())
+			}
+			got, err := ReadPuzzle(&buf, Samurai, format)
+			if err != nil {
+				t.Fatalf("unexpected error reading: %v", err)
+			}
+			if !reflect.DeepEqual(want.Variant(), got.Variant()) || !reflect.DeepEqual(want.Grid(), got.Grid()) {
+				t.Fatalf("want %+v\n%v\ngot %+v\n%v", want.Variant(), want.Grid(), got.Variant(), got.Grid())
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	WriteGrid(&buf, want.Grid(), TextFormat)
+	got, err := ReadPuzzle(strings.NewReader("Diagonals: all\n"+buf.String()), Samurai, TextFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Variant().Diagonals, Samurai.Positions()) {
+		t.Fatalf("want the diagonals of every sub-sudoku, got %v", got.Variant().Diagonals)
+	}
+	if got, err := ReadPuzzle(strings.NewReader(buf.String()), Samurai, TextFormat); err != nil || got.Variant() != nil {
+		t.Fatalf("want no variant without a header, got %+v, %v", got.Variant(), err)
+	}
+
+	if err := want.SetVariant(&Variant{Constraints: []Constraint{sameDigit{{0, 0}, {0, 1}}}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []GridFormat{TextFormat, JSONFormat} {
+		if err := WritePuzzle(&buf, want, format); err == nil {
+			t.Fatalf("%s: want an error writing a constraint puzzles can't hold", format)
+		}
+	}
+}
+
+func TestReadGridErrors(t *testing.T) {
+	valid := func() []string {
+		var buf bytes.Buffer
+		WriteGrid(&buf, SamuraiGridFromFile("sudoku.txt"), TextFormat)
+		return strings.Split(strings.TrimSpace(buf.String()), "\n")
+	}
+
+	testCases := []struct {
+		name   string
+		input  string
+		format GridFormat
+	}{
+		{"missing line", strings.Join(valid()[1:], "\n"), TextFormat},
+		{"short line", strings.Join(append([]string{"12"}, valid()[1:]...), "\n"), TextFormat},
+		{"bad character", strings.Join(append([]string{"x" + valid()[0][1:]}, valid()[1:]...), "\n"), TextFormat},
+		{"short single line", "123", LineFormat},
+		{"jagged json", "[[1, 2], [3]]", JSONFormat},
+		{"unknown header", "killer: yes\n" + strings.Join(valid(), "\n"), TextFormat},
+		{"unknown diagonal", "diagonals: middle\n" + strings.Join(valid(), "\n"), TextFormat},
+		{"diagonal outside the layout", "diagonals: top\n" + strings.Join(valid(), "\n"), TextFormat},
+		{"cage without a sum", "cage: r1c1 r1c2\n" + strings.Join(valid(), "\n"), TextFormat},
+		{"bad cage cell", "cage: 3 r1c1 x\n" + strings.Join(valid(), "\n"), TextFormat},
+		{"bad jigsaw region", "jigsaw: centre 12x\n" + strings.Join(valid(), "\n"), TextFormat},
+		{"unbalanced jigsaw", "jigsaw: centre 123456789\n" + strings.Join(valid(), "\n"), TextFormat},
+		{"unknown json jigsaw", `{"jigsaw": [{"position": "middle"}], "grid": []}`, JSONFormat},
+		{"unknown window", "windows: middle\n" + strings.Join(valid(), "\n"), TextFormat},
+		{"unknown json window", `{"windows": ["middle"], "grid": []}`, JSONFormat},
+		{"unknown marker", "marker: grey r1c1 r1c2\n" + strings.Join(valid(), "\n"), TextFormat},
+		{"marker between distant cells", "marker: x r1c1 r3c1\n" + strings.Join(valid(), "\n"), TextFormat},
+		{"unknown negative constraint", "negative: white, grey\n" + strings.Join(valid(), "\n"), TextFormat},
+		{"unknown json marker", `{"markers": [{"kind": "grey"}], "grid": []}`, JSONFormat},
+		{"bad thermo cell", "thermo: r1c1 x\n" + strings.Join(valid(), "\n"), TextFormat},
+		{"broken arrow", "arrow: r1c1 r1c3\n" + strings.Join(valid(), "\n"), TextFormat},
+		{"short json whispers", `{"whispers": [[{"row": 0, "column": 0}]], "grid": []}`, JSONFormat},
+		{"bad restricted digit", "digits: 1x r1c1\n" + strings.Join(valid(), "\n"), TextFormat},
+		{"even cell in a gap", "even: r1c10\n" + strings.Join(valid(), "\n"), TextFormat},
+		{"json restriction out of range", `{"restrictions": [{"cells": [{"row": 0, "column": 0}], "digits": [10]}], "grid": []}`, JSONFormat},
+		{"unknown json diagonal", `{"diagonals": ["middle"], "grid": []}`, JSONFormat},
+		{"letter in a 9x9 sudoku", strings.Join(append([]string{"A" + valid()[0][1:]}, valid()[1:]...), "\n"), TextFormat},
+		{"not json", "{", JSONFormat},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ReadGrid(strings.NewReader(tc.input), tc.format)
+			if !errors.Is(err, ErrInvalidGrid) {
+				t.Fatalf("want %v, got %v", ErrInvalidGrid, err)
+			}
+		})
+	}
+}
+
+func TestParseGridFormat(t *testing.T) {
+	for _, format := range []GridFormat{TextFormat, LineFormat, JSONFormat} {
+		got, err := ParseGridFormat(strings.ToUpper(format.String()))
+		if err != nil || got != format {
+			t.Fatalf("want %v, got %v, %v", format, got, err)
+		}
+	}
+	if _, err := ParseGridFormat("xml"); err == nil {
+		t.Fatalf("want error for unknown format")
+	}
+}
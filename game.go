@@ -0,0 +1,377 @@
+package sudoku
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrNotUnique is returned by NewGame for puzzles with more than one solution
+var ErrNotUnique = errors.New("sudoku: the puzzle has more than one solution")
+
+// ErrGiven is returned by the moves of a Game made on a cell given by the puzzle
+var ErrGiven = errors.New("sudoku: the cell is given by the puzzle")
+
+// Game is a puzzle being played. The cells filled when the game starts are its givens, which stay as they are, the player
+// places and erases digits in the others and keeps pencil marks in the empty ones. Every change can be undone and redone,
+// entries are checked against the puzzle's unique solution, and the time spent playing is tracked
+type Game struct {
+	samurai  *SamuraiSudoku
+	givens   Grid
+	solution Grid
+	marks    map[Cell]uint32 // Pencil marks of the cells having some, bit n set for digit n
+	undo     []GameMove
+	redo     []GameMove
+	elapsed  time.Duration    // Time played until the clock was last started
+	started  time.Time        // When the clock was last started, zero while it is stopped
+	now      func() time.Time // Reads the clock, time.Now but in tests
+}
+
+// GameMove is a change the player made to a cell, which can be undone
+type GameMove struct {
+	Cell        Cell  `json:"cell"`
+	Before      int   `json:"before"` // Digit of the cell before the move, 0 for none
+	After       int   `json:"after"`  // Digit of the cell after the move, 0 for none
+	MarksBefore []int `json:"marksBefore,omitempty"`
+	MarksAfter  []int `json:"marksAfter,omitempty"`
+}
+
+// PencilMarks are the digits the player noted down in a cell
+type PencilMarks struct {
+	Cell   Cell  `json:"cell"`
+	Digits []int `json:"digits"`
+}
+
+// GameState is everything needed to resume a Game, for saving it, e.g. as JSON
+type GameState struct {
+	Layout  string        `json:"layout"`          // Name of the puzzle's layout, as ParseLayout reads it
+	Puzzle  string        `json:"puzzle"`          // Givens, after a header holding the puzzle's variant, in text format
+	Grid    Grid          `json:"grid"`            // Givens and the player's entries
+	Marks   []PencilMarks `json:"marks,omitempty"` // Pencil marks of the cells having some, in reading order
+	Undo    []GameMove    `json:"undo,omitempty"`  // Moves that can be undone, the last one first undone
+	Redo    []GameMove    `json:"redo,omitempty"`  // Moves that can be redone, the last one first redone
+	Elapsed time.Duration `json:"elapsed"`         // Time played, in nanoseconds
+}
+
+//NewGame starts a game of the puzzle, whose filled cells are the givens, and starts its clock. The game plays on the puzzle's grid.
+//It returns the errors of Validate for grids breaking a rule, ErrUnsolvable for puzzles without a solution and ErrNotUnique for
+//puzzles with more than one
+func NewGame(samurai *SamuraiSudoku) (*Game, error) {
+	solution, err := uniqueSolution(context.Background(), samurai)
+	if err != nil {
+		return nil, err
+	}
+	g := &Game{
+		samurai:  samurai,
+		givens:   copyGrid(samurai.Grid()),
+		solution: solution,
+		marks:    make(map[Cell]uint32),
+		now:      time.Now,
+	}
+	g.started = g.now()
+	return g, nil
+}
+
+//uniqueSolution returns the solution of the puzzle, leaving its grid as it is. It returns the errors of Validate for grids
+//breaking a rule, ErrUnsolvable for puzzles without a solution, ErrNotUnique for puzzles with more than one and ctx's error
+//once ctx is done
+func uniqueSolution(ctx context.Context, samurai *SamuraiSudoku) (Grid, error) {
+	if err := samurai.Validate(); err != nil {
+		return nil, err
+	}
+	e, err := newEngine(ctx, samurai.Layout(), samurai.variant, samurai.Grid())
+	if err != nil {
+		return nil, err
+	}
+	e.limit = 2
+	e.search()
+	switch {
+	case e.err != nil:
+		return nil, e.err
+	case e.solutions == 0:
+		return nil, ErrUnsolvable
+	case e.solutions > 1:
+		return nil, ErrNotUnique
+	}
+	return e.solution, nil
+}
+
+//Puzzle returns the puzzle being played, whose grid holds the givens and the player's entries
+func (g *Game) Puzzle() *SamuraiSudoku {
+	return g.samurai
+}
+
+//Grid returns a copy of the grid, with the givens and the player's entries
+func (g *Game) Grid() Grid {
+	g.samurai.mu.Lock()
+	defer g.samurai.mu.Unlock()
+	return copyGrid(g.samurai.grid)
+}
+
+//Given tells if cell c is given by the puzzle
+func (g *Game) Given(c Cell) bool {
+	return g.samurai.Layout().Contains(c.Row, c.Column) && g.givens[c.Row][c.Column] > 0
+}
+
+//checkCell returns an error if c isn't a cell of the puzzle's layout, or is given
+func (g *Game) checkCell(c Cell) error {
+	if !g.samurai.Layout().Contains(c.Row, c.Column) {
+		return fmt.Errorf("sudoku: %s isn't a cell of the %s layout", formatCell(c), g.samurai.Layout())
+	}
+	if g.Given(c) {
+		return fmt.Errorf("%w: %s", ErrGiven, formatCell(c))
+	}
+	return nil
+}
+
+//checkDigit returns an error if n isn't a digit of the puzzle's sub-sudokus
+func (g *Game) checkDigit(n int) error {
+	if size := g.samurai.Layout().size; n < 1 || n > size {
+		return fmt.Errorf("sudoku: %d isn't a digit from 1 to %d", n, size)
+	}
+	return nil
+}
+
+//Place fills cell c with n, whether n is right or not, clearing the cell's pencil marks.
+//It returns ErrGiven for givens, and an error for cells off the layout and digits out of range
+func (g *Game) Place(c Cell, n int) error {
+	if err := g.checkCell(c); err != nil {
+		return err
+	}
+	if err := g.checkDigit(n); err != nil {
+		return err
+	}
+	g.change(c, n, 0)
+	return nil
+}
+
+//Erase empties cell c, clearing its pencil marks too.
+//It returns ErrGiven for givens, and an error for cells off the layout
+func (g *Game) Erase(c Cell) error {
+	if err := g.checkCell(c); err != nil {
+		return err
+	}
+	g.change(c, 0, 0)
+	return nil
+}
+
+//Mark adds n to the pencil marks of the empty cell c, or removes it if it is already there.
+//It returns ErrGiven for givens, and an error for filled cells, cells off the layout and digits out of range
+func (g *Game) Mark(c Cell, n int) error {
+	if err := g.checkCell(c); err != nil {
+		return err
+	}
+	if err := g.checkDigit(n); err != nil {
+		return err
+	}
+	if g.digit(c) != 0 {
+		return fmt.Errorf("sudoku: %s is filled, it can't take pencil marks", formatCell(c))
+	}
+	g.change(c, 0, g.marks[c]^1<<n)
+	return nil
+}
+
+//Marks returns the pencil marks of cell c in increasing order
+func (g *Game) Marks(c Cell) []int {
+	return maskDigits(g.marks[c])
+}
+
+func (g *Game) digit(c Cell) int {
+	g.samurai.mu.Lock()
+	defer g.samurai.mu.Unlock()
+	return g.samurai.grid[c.Row][c.Column]
+}
+
+//change sets the digit and pencil marks of cell c as a move that can be undone, unless they are already set
+func (g *Game) change(c Cell, n int, marks uint32) {
+	before := g.digit(c)
+	if before == n && g.marks[c] == marks {
+		return
+	}
+	g.undo = append(g.undo, GameMove{Cell: c, Before: before, After: n, MarksBefore: maskDigits(g.marks[c]), MarksAfter: maskDigits(marks)})
+	g.redo = nil
+	g.set(c, n, marks)
+}
+
+//set sets the digit and pencil marks of cell c, stopping the clock if it solves the puzzle
+func (g *Game) set(c Cell, n int, marks uint32) {
+	g.samurai.mu.Lock()
+	g.samurai.grid[c.Row][c.Column] = n
+	g.samurai.mu.Unlock()
+	if marks == 0 {
+		delete(g.marks, c)
+	} else {
+		g.marks[c] = marks
+	}
+	if g.Solved() {
+		g.Pause()
+	}
+}
+
+//Undo reverts the last move not undone yet, and tells if there was one
+func (g *Game) Undo() bool {
+	if len(g.undo) == 0 {
+		return false
+	}
+	move := g.undo[len(g.undo)-1]
+	g.undo = g.undo[:len(g.undo)-1]
+	g.redo = append(g.redo, move)
+	g.set(move.Cell, move.Before, digitMask(move.MarksBefore))
+	return true
+}
+
+//Redo makes the last move undone again, and tells if there was one. Moves made since it was undone discard it
+func (g *Game) Redo() bool {
+	if len(g.redo) == 0 {
+		return false
+	}
+	move := g.redo[len(g.redo)-1]
+	g.redo = g.redo[:len(g.redo)-1]
+	g.undo = append(g.undo, move)
+	g.set(move.Cell, move.After, digitMask(move.MarksAfter))
+	return true
+}
+
+//Mistakes returns the cells the player filled with a digit other than the solution's, in reading order
+func (g *Game) Mistakes() []Cell {
+	var mistakes []Cell
+	for y, row := range g.Grid() {
+		for x, num := range row {
+			if num > 0 && num != g.solution[y][x] {
+				mistakes = append(mistakes, Cell{y, x})
+			}
+		}
+	}
+	return mistakes
+}
+
+//Solved tells if every cell holds the solution's digit
+func (g *Game) Solved() bool {
+	g.samurai.mu.Lock()
+	defer g.samurai.mu.Unlock()
+	for y, row := range g.samurai.grid {
+		for x, num := range row {
+			if num != g.solution[y][x] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+//Elapsed returns the time played, leaving out the time the clock was stopped
+func (g *Game) Elapsed() time.Duration {
+	if g.started.IsZero() {
+		return g.elapsed
+	}
+	return g.elapsed + g.now().Sub(g.started)
+}
+
+//Pause stops the clock, as solving the puzzle does
+func (g *Game) Pause() {
+	if !g.started.IsZero() {
+		g.elapsed += g.now().Sub(g.started)
+		g.started = time.Time{}
+	}
+}
+
+//Resume starts the clock again, unless the puzzle is solved
+func (g *Game) Resume() {
+	if g.started.IsZero() && !g.Solved() {
+		g.started = g.now()
+	}
+}
+
+//State returns the state of the game, to resume it later with RestoreGame.
+//It returns an error for puzzles WritePuzzle can't write
+func (g *Game) State() (GameState, error) {
+	givens := &SamuraiSudoku{layout: g.samurai.layout, variant: g.samurai.variant, grid: g.givens}
+	var puzzle bytes.Buffer
+	if err := WritePuzzle(&puzzle, givens, TextFormat); err != nil {
+		return GameState{}, err
+	}
+	state := GameState{
+		Layout:  g.samurai.Layout().Name(),
+		Puzzle:  puzzle.String(),
+		Grid:    g.Grid(),
+		Undo:    append([]GameMove(nil), g.undo...),
+		Redo:    append([]GameMove(nil), g.redo...),
+		Elapsed: g.Elapsed(),
+	}
+	for y, row := range state.Grid {
+		for x := range row {
+			if marks, ok := g.marks[Cell{y, x}]; ok {
+				state.Marks = append(state.Marks, PencilMarks{Cell{y, x}, maskDigits(marks)})
+			}
+		}
+	}
+	return state, nil
+}
+
+//RestoreGame resumes a game from its state, and starts its clock.
+//It returns an error wrapping ErrInvalidGrid for states whose grid, pencil marks or moves don't fit the puzzle, and
+//the errors of NewGame for the puzzle
+func RestoreGame(state GameState) (*Game, error) {
+	layout, err := ParseLayout(state.Layout)
+	if err != nil {
+		return nil, err
+	}
+	samurai, err := ReadPuzzle(strings.NewReader(state.Puzzle), layout, TextFormat)
+	if err != nil {
+		return nil, err
+	}
+	g, err := NewGame(samurai)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkShape(state.Grid, layout); err != nil {
+		return nil, err
+	}
+	for y, row := range state.Grid {
+		for x, num := range row {
+			if given := g.givens[y][x]; given > 0 && num != given {
+				return nil, fmt.Errorf("%w: given %s changed from %d to %d", ErrInvalidGrid, formatCell(Cell{y, x}), given, num)
+			}
+		}
+	}
+	for _, marks := range state.Marks {
+		if err := g.checkMarks(marks.Cell, marks.Digits); err != nil {
+			return nil, err
+		}
+	}
+	for _, move := range append(append([]GameMove(nil), state.Undo...), state.Redo...) {
+		if err := g.checkMarks(move.Cell, append(move.MarksBefore, move.MarksAfter...)); err != nil {
+			return nil, err
+		}
+		if move.Before < 0 || move.Before > layout.size || move.After < 0 || move.After > layout.size {
+			return nil, fmt.Errorf("%w: move in %s holds a digit out of range", ErrInvalidGrid, formatCell(move.Cell))
+		}
+	}
+
+	g.samurai.SetGrid(copyGrid(state.Grid))
+	for _, marks := range state.Marks {
+		g.marks[marks.Cell] = digitMask(marks.Digits)
+	}
+	g.undo = append([]GameMove(nil), state.Undo...)
+	g.redo = append([]GameMove(nil), state.Redo...)
+	g.elapsed = state.Elapsed
+	g.started = time.Time{}
+	g.Resume()
+	return g, nil
+}
+
+//checkMarks returns an error wrapping ErrInvalidGrid if c can't take pencil marks or digits aren't all digits of the puzzle
+func (g *Game) checkMarks(c Cell, digits []int) error {
+	if err := g.checkCell(c); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidGrid, err)
+	}
+	for _, n := range digits {
+		if err := g.checkDigit(n); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidGrid, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testClock A clock for games, moved forward by hand
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+//newTestGame starts a game of sudoku.txt on a test clock, whose first two cells are 1 and 6 once solved, the next two given
+func newTestGame(t *testing.T) (*Game, *testClock) {
+	g, err := NewGame(newTestSamurai())
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := &testClock{time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	g.now = clock.Now
+	g.started = clock.now
+	return g, clock
+}
+
+func TestGameMoves(t *testing.T) {
+	g, _ := newTestGame(t)
+
+	if err := g.Place(Cell{0, 2}, 1); !errors.Is(err, ErrGiven) {
+		t.Fatalf("want ErrGiven placing a digit on a given, got %v", err)
+	}
+	if err := g.Place(Cell{0, 9}, 1); err == nil {
+		t.Fatalf("want an error placing a digit in a gap")
+	}
+	if err := g.Place(Cell{0, 0}, 10); err == nil {
+		t.Fatalf("want an error placing a digit out of range")
+	}
+
+	if err := g.Place(Cell{0, 0}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Place(Cell{0, 1}, 7); err != nil {
+		t.Fatal(err)
+	}
+	if got := g.Mistakes(); !reflect.DeepEqual(got, []Cell{{0, 1}}) {
+		t.Fatalf("want the wrong 7 reported, got %v", got)
+	}
+	if g.Puzzle().Grid()[0][1] != 7 {
+		t.Fatalf("want the game played on the puzzle's grid")
+	}
+
+	if !g.Undo() || g.Grid()[0][1] != 0 || g.Mistakes() != nil {
+		t.Fatalf("want the 7 undone")
+	}
+	if !g.Redo() || g.Grid()[0][1] != 7 {
+		t.Fatalf("want the 7 redone")
+	}
+	if err := g.Erase(Cell{0, 1}); err != nil {
+		t.Fatal(err)
+	}
+	g.Undo()
+	if err := g.Place(Cell{0, 1}, 6); err != nil {
+		t.Fatal(err)
+	}
+	if g.Redo() {
+		t.Fatalf("want a new move to discard the moves undone")
+	}
+	for g.Undo() {
+	}
+	if !reflect.DeepEqual(g.Grid(), SamuraiGridFromFile("sudoku.txt")) {
+		t.Fatalf("want every move undone")
+	}
+}
+
+func TestGameMarks(t *testing.T) {
+	g, _ := newTestGame(t)
+
+	for _, n := range []int{1, 4, 6, 4} {
+		if err := g.Mark(Cell{0, 0}, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := g.Marks(Cell{0, 0}); !reflect.DeepEqual(got, []int{1, 6}) {
+		t.Fatalf("want marks 1 and 6 once 4 is toggled twice, got %v", got)
+	}
+	if err := g.Mark(Cell{0, 2}, 1); !errors.Is(err, ErrGiven) {
+		t.Fatalf("want ErrGiven marking a given, got %v", err)
+	}
+
+	if err := g.Place(Cell{0, 0}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if g.Marks(Cell{0, 0}) != nil {
+		t.Fatalf("want the marks cleared by placing a digit")
+	}
+	if err := g.Mark(Cell{0, 0}, 2); err == nil {
+		t.Fatalf("want an error marking a filled cell")
+	}
+	g.Undo()
+	if got := g.Marks(Cell{0, 0}); !reflect.DeepEqual(got, []int{1, 6}) {
+		t.Fatalf("want the marks back once the digit is undone, got %v", got)
+	}
+}
+
+func TestGameClock(t *testing.T) {
+	g, clock := newTestGame(t)
+
+	clock.now = clock.now.Add(time.Minute)
+	g.Pause()
+	clock.now = clock.now.Add(time.Hour)
+	if got := g.Elapsed(); got != time.Minute {
+		t.Fatalf("want the paused time left out, got %v", got)
+	}
+	g.Resume()
+	clock.now = clock.now.Add(time.Second)
+	if got := g.Elapsed(); got != time.Minute+time.Second {
+		t.Fatalf("want the time since resuming counted, got %v", got)
+	}
+
+	// solving the puzzle stops the clock
+	solution := g.solution
+	for y, row := range solution {
+		for x, num := range row {
+			if num > 0 && !g.Given(Cell{y, x}) {
+				if err := g.Place(Cell{y, x}, num); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+	if !g.Solved() {
+		t.Fatalf("want the puzzle solved")
+	}
+	clock.now = clock.now.Add(time.Hour)
+	g.Resume()
+	if got := g.Elapsed(); got != time.Minute+time.Second {
+		t.Fatalf("want the clock stopped once solved, got %v", got)
+	}
+}
+
+func TestGameState(t *testing.T) {
+	samurai := newTestSamurai()
+	if err := samurai.SetVariant(&Variant{Constraints: testLines}); err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGame(samurai)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Place(Cell{0, 0}, 1)
+	g.Place(Cell{0, 1}, 7)
+	g.Mark(Cell{1, 2}, 3)
+	g.Mark(Cell{1, 2}, 8)
+	g.Undo()
+
+	state, err := g.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var read GameState
+	if err := json.Unmarshal(data, &read); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestoreGame(read)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(restored.Grid(), g.Grid()) || !reflect.DeepEqual(restored.Mistakes(), []Cell{{0, 1}}) {
+		t.Fatalf("want the entries restored, got:\n%v", restored.Grid())
+	}
+	if !restored.Given(Cell{0, 2}) || restored.Given(Cell{0, 0}) {
+		t.Fatalf("want the givens restored apart from the entries")
+	}
+	if got := restored.Marks(Cell{1, 2}); !reflect.DeepEqual(got, []int{3}) {
+		t.Fatalf("want the marks restored, got %v", got)
+	}
+	if len(restored.Puzzle().Variant().Constraints) != len(testLines) {
+		t.Fatalf("want the variant restored")
+	}
+	if !restored.Redo() || !reflect.DeepEqual(restored.Marks(Cell{1, 2}), []int{3, 8}) {
+		t.Fatalf("want the moves undone restored")
+	}
+	for restored.Undo() {
+	}
+	if !reflect.DeepEqual(restored.Grid(), SamuraiGridFromFile("sudoku.txt")) {
+		t.Fatalf("want the moves made restored")
+	}
+
+	read.Grid[0][2] = 1
+	if _, err := RestoreGame(read); !errors.Is(err, ErrInvalidGrid) {
+		t.Fatalf("want ErrInvalidGrid for a changed given, got %v", err)
+	}
+}
+
+func TestNewGameErrors(t *testing.T) {
+	var samurai SamuraiSudoku
+	samurai.SetGrid(NewSamuraiGrid())
+	if _, err := NewGame(&samurai); !errors.Is(err, ErrNotUnique) {
+		t.Fatalf("want ErrNotUnique for an empty grid, got %v", err)
+	}
+
+	grid := SamuraiGridFromFile("sudoku.txt")
+	grid[0][0] = 5
+	samurai.SetGrid(grid)
+	var conflict *ConflictError
+	if _, err := NewGame(&samurai); !errors.As(err, &conflict) {
+		t.Fatalf("want a *ConflictError, got %v", err)
+	}
+}
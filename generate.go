@@ -0,0 +1,118 @@
+package sudoku
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// GenerateOptions configures the puzzles made by GenerateSamuraiSudoku
+type GenerateOptions struct {
+	Seed     int64    // Seed of the random source, the same seed always generates the same puzzle. 0 picks a random seed
+	MinClues int      // Clues stop being removed once the puzzle is down to MinClues, 0 removes as many as possible
+	Layout   *Layout  // Layout of the puzzle, Samurai if nil
+	Variant  *Variant // Rules the puzzle adds to those of its sub-sudokus, none if nil
+}
+
+//GenerateSamuraiSudoku generates a samurai sudoku puzzle that has a unique solution
+func GenerateSamuraiSudoku(options GenerateOptions) Grid {
+	grid, _ := GenerateSamuraiSudokuContext(context.Background(), options)
+	return grid
+}
+
+//GenerateSamuraiSudokuContext generates a samurai sudoku puzzle that has a unique solution, giving up once ctx is done.
+//A random solution is filled in, then its cells are emptied in random order as long as the solution can be proved to stay unique.
+//It returns ErrUnsolvable if no grid follows the variant's rules
+func GenerateSamuraiSudokuContext(ctx context.Context, options GenerateOptions) (Grid, error) {
+	seed := options.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+	layout := options.Layout
+	if layout == nil {
+		layout = Samurai
+	}
+	if err := options.Variant.check(layout); err != nil {
+		return nil, err
+	}
+
+	puzzle, e, err := randomSolution(ctx, layout, options.Variant, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	clues := make([]Cell, 0, len(e.cells))
+	clues = append(clues, e.cells...)
+	rng.Shuffle(len(clues), func(i, j int) {
+		clues[i], clues[j] = clues[j], clues[i]
+	})
+
+	remaining := len(clues)
+	for _, c := range clues {
+		if remaining <= options.MinClues {
+			break
+		}
+		num := puzzle[c.Row][c.Column]
+		puzzle[c.Row][c.Column] = 0
+		unique, err := provedUnique(ctx, layout, options.Variant, puzzle)
+		if err != nil {
+			return nil, err
+		}
+		if !unique {
+			puzzle[c.Row][c.Column] = num
+			continue
+		}
+		remaining--
+	}
+	return puzzle, nil
+}
+
+// uniquenessSteps bounds the search proving a puzzle being generated has a unique solution, per cell of the layout.
+// Puzzles whose uniqueness takes longer to prove keep their clue, which also keeps them solvable by hand
+const uniquenessSteps = 50
+
+//provedUnique tells if puzzle was proved to have a single solution within a search of uniquenessSteps per cell
+func provedUnique(ctx context.Context, layout *Layout, variant *Variant, puzzle Grid) (bool, error) {
+	e, err := newEngine(ctx, layout, variant, puzzle)
+	if err != nil {
+		return false, err
+	}
+	e.limit = 2
+	e.maxSteps = len(e.cells) * uniquenessSteps
+	e.search()
+	if e.err == errTooManySteps {
+		return false, nil
+	}
+	if e.err != nil {
+		return false, e.err
+	}
+	return e.solutions == 1, nil
+}
+
+//randomSolution returns a random solution of an empty grid shaped like layout following variant's rules, with the engine that found it,
+//or ErrUnsolvable if there is none.
+//Random searches of large layouts can get lost for a very long time, so the search starts over with a new
+//order whenever it takes more steps than a few times the number of cells, a budget which slowly grows
+func randomSolution(ctx context.Context, layout *Layout, variant *Variant, rng *rand.Rand) (Grid, *engine, error) {
+	for attempt := 1; ; attempt++ {
+		e, err := newEngine(ctx, layout, variant, layout.NewGrid())
+		if err != nil {
+			return nil, nil, err
+		}
+		e.rng = rng
+		e.limit = 1
+		e.maxSteps = len(e.cells) * (attempt + 3)
+		e.search()
+		if e.err == errTooManySteps {
+			continue
+		}
+		if e.err != nil {
+			return nil, nil, e.err
+		}
+		if e.solution == nil {
+			return nil, nil, ErrUnsolvable
+		}
+		return e.solution, e, nil
+	}
+}
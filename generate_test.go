@@ -0,0 +1,42 @@
+package sudoku
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateSamuraiSudoku(t *testing.T) {
+	options := GenerateOptions{Seed: 42, MinClues: 250}
+	puzzle := GenerateSamuraiSudoku(options)
+
+	if err := Validate(puzzle); err != nil {
+		t.Fatalf("generated an invalid puzzle: %v", err)
+	}
+	if got := CountSolutions(puzzle, 2); got != 1 {
+		t.Fatalf("want a unique solution, got %d", got)
+	}
+	clues := 0
+	for _, row := range puzzle {
+		for _, num := range row {
+			if num > 0 {
+				clues++
+			}
+		}
+	}
+	if clues != options.MinClues {
+		t.Fatalf("want %d clues, got %d", options.MinClues, clues)
+	}
+	if again := GenerateSamuraiSudoku(options); !reflect.DeepEqual(puzzle, again) {
+		t.Fatalf("the same seed generated different puzzles")
+	}
+}
+
+func TestGenerateUnsatisfiableVariant(t *testing.T) {
+	// two cells of the same row can't both hold 1
+	variant := &Variant{Restrictions: []Restriction{{Cells: []Cell{{0, 0}, {0, 1}}, Digits: []int{1}}}}
+	if _, err := GenerateSamuraiSudokuContext(context.Background(), GenerateOptions{Seed: 1, Variant: variant}); !errors.Is(err, ErrUnsolvable) {
+		t.Fatalf("want %v, got %v", ErrUnsolvable, err)
+	}
+}
@@ -2,9 +2,14 @@ module github.com/alielbashir/samurai-sudoku-go
 
 go 1.17
 
-require github.com/wcharczuk/go-chart/v2 v2.1.0
+require (
+	github.com/wcharczuk/go-chart/v2 v2.1.0
+	golang.org/x/image v0.0.0-20200927104501-e162460cd6b5
+	golang.org/x/term v0.0.0-20220722155259-a9ba230a4035
+)
 
 require (
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
-	golang.org/x/image v0.0.0-20200927104501-e162460cd6b5 // indirect
+	golang.org/x/sys v0.7.0 // indirect
+	golang.org/x/text v0.3.0 // indirect
 )
@@ -0,0 +1,163 @@
+package sudoku
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"time"
+)
+
+// CellStats is the search effort spent on a single cell of the samurai grid
+type CellStats struct {
+	Attempts    int           // Number of times a value was placed in the cell
+	Retractions int           // Number of times a placed value was taken back
+	Time        time.Duration // Time threads spent working from the cell before their next move
+}
+
+// Heatmap aggregates a move trace per cell of the whole grid of a puzzle
+type Heatmap struct {
+	grid    Grid
+	layout  *Layout
+	variant *Variant
+	borders Borders
+	cells   [][]CellStats
+}
+
+//NewHeatmap aggregates the moves recorded while solving samurai per global cell.
+//Moves on the overlaps are counted once, whichever sub-sudoku they were made from
+func NewHeatmap(samurai *SamuraiSudoku) *Heatmap {
+	grid := samurai.Grid()
+	heatmap := &Heatmap{grid: grid, layout: samurai.Layout(), variant: samurai.Variant(), borders: samurai.Borders(), cells: make([][]CellStats, len(grid))}
+	for i := range grid {
+		heatmap.cells[i] = make([]CellStats, len(grid[i]))
+	}
+
+	moves := samurai.trace()
+	// index of the previous move made by each thread, for attributing time
+	previous := make(map[int]int)
+	for i, move := range moves {
+		if j, ok := previous[move.thread]; ok {
+			prev := moves[j]
+			y, x := prev.globalCell()
+			heatmap.cells[y][x].Time += move.time.Sub(prev.time)
+		}
+		previous[move.thread] = i
+
+		y, x := move.globalCell()
+		if move.num == 0 {
+			heatmap.cells[y][x].Retractions++
+		} else {
+			heatmap.cells[y][x].Attempts++
+		}
+	}
+	return heatmap
+}
+
+//Cell returns the statistics of the cell at row y and column x of the samurai grid
+func (h *Heatmap) Cell(y int, x int) CellStats {
+	return h.cells[y][x]
+}
+
+// HeatmapMetric selects which CellStats field a heatmap is coloured by
+type HeatmapMetric int
+
+const (
+	Attempts HeatmapMetric = iota
+	Retractions
+	TimeSpent
+)
+
+func (m HeatmapMetric) String() string {
+	switch m {
+	case Attempts:
+		return "attempts"
+	case Retractions:
+		return "retractions"
+	case TimeSpent:
+		return "time spent"
+	}
+	return "unknown"
+}
+
+func (m HeatmapMetric) value(stats CellStats) float64 {
+	switch m {
+	case Retractions:
+		return float64(stats.Retractions)
+	case TimeSpent:
+		return float64(stats.Time)
+	}
+	return float64(stats.Attempts)
+}
+
+// HeatmapOptions configures how a heatmap is rendered
+type HeatmapOptions struct {
+	Metric   HeatmapMetric
+	CellSize int // Side of a cell in pixels, defaults to 24
+}
+
+const defaultHeatmapCellSize = 24
+
+//WriteHeatmap renders the heatmap of samurai's search effort as a PNG image to w
+func WriteHeatmap(w io.Writer, samurai *SamuraiSudoku, options HeatmapOptions) error {
+	return NewHeatmap(samurai).WritePNG(w, options)
+}
+
+//WritePNG renders h as a PNG image to w, every cell coloured by its heat under the lines and variant of the puzzle's picture,
+//gap cells are left blank
+func (h *Heatmap) WritePNG(w io.Writer, options HeatmapOptions) error {
+	if len(h.grid) == 0 {
+		return fmt.Errorf("sudoku: heatmap of an empty grid")
+	}
+	cellSize := options.CellSize
+	if cellSize <= 0 {
+		cellSize = defaultHeatmapCellSize
+	}
+
+	var max float64
+	for _, row := range h.cells {
+		for _, stats := range row {
+			if v := options.Metric.value(stats); v > max {
+				max = v
+			}
+		}
+	}
+
+	rows, columns := h.layout.Size()
+	p, err := newPNGPen(float64(columns)+2*imageMargin, float64(rows)+2*imageMargin, float64(cellSize), ImageOptions{})
+	if err != nil {
+		return err
+	}
+	p.polygon(square(-imageMargin, -imageMargin, float64(rows)+2*imageMargin, float64(columns)+2*imageMargin), imagePaperColour)
+	for y, row := range h.grid {
+		for x, num := range row {
+			if num == -1 {
+				continue
+			}
+			var intensity float64
+			if max > 0 {
+				intensity = options.Metric.value(h.cells[y][x]) / max
+			}
+			p.polygon(square(float64(y), float64(x), 1, 1), heatColour(intensity))
+		}
+	}
+
+	drawVariantBelow(p, h.layout, h.variant)
+	drawLines(p, h.borders)
+	drawVariantAbove(p, h.layout, h.variant)
+	return p.encode(w)
+}
+
+//heatColour maps an intensity between 0 and 1 from pale yellow to dark red
+func heatColour(intensity float64) color.RGBA {
+	if intensity < 0 {
+		intensity = 0
+	} else if intensity > 1 {
+		intensity = 1
+	}
+	return color.RGBA{
+		R: uint8(255 - 100*intensity),
+		G: uint8(250 - 250*intensity),
+		B: uint8(200 - 200*intensity),
+		A: 0xff,
+	}
+}
@@ -0,0 +1,121 @@
+package sudoku
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestNewHeatmap(t *testing.T) {
+	var samurai SamuraiSudoku
+	samurai.SetGrid(SamuraiGridFromFile("sudoku.txt"))
+
+	start := time.Now()
+	samurai.tracker.moves = []Move{
+		{thread: 31, position: Centre, row: 0, column: 0, num: 4, time: start},
+		{thread: 11, position: TopLeft, row: 6, column: 6, num: 5, time: start.Add(time.Millisecond)},
+		{thread: 31, position: Centre, row: 0, column: 0, num: 0, time: start.Add(3 * time.Millisecond)},
+		{thread: 11, position: TopLeft, row: 0, column: 0, num: 1, time: start.Add(4 * time.Millisecond)},
+	}
+
+	heatmap := NewHeatmap(&samurai)
+
+	testCases := []struct {
+		name string
+		y, x int
+		want CellStats
+	}{
+		// centre (0, 0) and top left (6, 6) are the same cell
+		{"overlap", 6, 6, CellStats{Attempts: 2, Retractions: 1, Time: 6 * time.Millisecond}},
+		{"top left corner", 0, 0, CellStats{Attempts: 1}},
+		{"untouched", 20, 20, CellStats{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := heatmap.Cell(tc.y, tc.x); got != tc.want {
+				t.Fatalf("want %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestWriteHeatmap(t *testing.T) {
+	samurai := newTestSamurai()
+	SolveSamuraiSudoku(samurai)
+
+	for _, metric := range []HeatmapMetric{Attempts, Retractions, TimeSpent} {
+		t.Run(metric.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteHeatmap(&buf, samurai, HeatmapOptions{Metric: metric, CellSize: 10}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			img, err := png.Decode(&buf)
+			if err != nil {
+				t.Fatalf("output is not a png: %v", err)
+			}
+			// 21 cells and the picture's margin of an eighth of a cell on both sides
+			if got := img.Bounds().Dx(); got != 213 {
+				t.Fatalf("want width %d, got %d", 213, got)
+			}
+		})
+	}
+}
+
+//heatmapImage draws the heatmap of samurai testCellSize pixels to a cell, coloured by attempts
+func heatmapImage(t *testing.T, samurai *SamuraiSudoku) image.Image {
+	var buf bytes.Buffer
+	if err := WriteHeatmap(&buf, samurai, HeatmapOptions{CellSize: testCellSize}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("output is not a png: %v", err)
+	}
+	return img
+}
+
+//hasColour tells if a pixel of img, drawn testCellSize pixels to a cell, is c between the points y0,x0 and y1,x1 of the canvas
+
+func TestWriteHeatmapHeat(t *testing.T) {
+	samurai := newTestSamurai()
+	start := time.Now()
+	samurai.tracker.moves = []Move{
+		{thread: 11, position: TopLeft, row: 0, column: 0, num: 1, time: start},
+		{thread: 11, position: TopLeft, row: 0, column: 0, num: 2, time: start},
+		{thread: 11, position: TopLeft, row: 0, column: 1, num: 3, time: start},
+	}
+	img := heatmapImage(t, samurai)
+
+	// cells are coloured by their attempts against the most any cell had, whatever the puzzle's lines and digits
+	for _, tc := range []struct {
+		y, x      float64
+		intensity float64
+	}{{0.5, 0.5, 1}, {0.5, 1.5, 0.5}, {0.5, 2.5, 0}} {
+		if want := heatColour(tc.intensity); !hasColour(img, tc.y, tc.x, tc.y, tc.x, want) {
+			t.Errorf("want the cell at %v, %v coloured %v", tc.y, tc.x, want)
+		}
+	}
+	if !hasColour(img, 10.5, 0.5, 10.5, 0.5, imagePaperColour) {
+		t.Fatalf("want the gaps left blank")
+	}
+}
+
+func TestWriteHeatmapOverlay(t *testing.T) {
+	samurai := newTestSamurai()
+	if err := samurai.SetVariant(&Variant{Windows: []Position{Centre}}); err != nil {
+		t.Fatal(err)
+	}
+	img := heatmapImage(t, samurai)
+
+	// the lines and the variant of the puzzle's picture are drawn over the heat: the centre's first window is outlined a
+	// twelfth of a cell inside its cell (1, 1), canvas cell (7, 7), and the edge of the top left sub-sudoku is inked
+	if !hasColour(img, 7.05, 7.5, 7.12, 7.5, imageWindowColour) {
+		t.Fatalf("want the top of the centre's first window outlined")
+	}
+	if !hasColour(img, 0.5, 0, 0.5, 0, imageInkColour) {
+		t.Fatalf("want the edge of the top left sub-sudoku drawn")
+	}
+}
@@ -0,0 +1,391 @@
+package sudoku
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// Technique is a way of deducing the digit of a cell, or digits some cells can't hold, from the candidates of the empty cells
+type Technique int
+
+const (
+	NakedSingle      Technique = iota + 1 // An empty cell has a single candidate left
+	HiddenSingle                          // A digit has a single cell left in a row, column, box or other unit
+	LockedCandidates                      // The cells a digit has left in a unit all lie in another unit, ruling it out of that unit's other cells, pointing or claiming
+	NakedPair                             // Two cells of a unit only hold the same two digits, ruling them out of the unit's other cells
+	HiddenPair                            // Two digits only fit in the same two cells of a unit, ruling the other digits out of those cells
+	NakedTriple                           // Three cells of a unit only hold three digits between them
+	HiddenTriple                          // Three digits only fit in three cells of a unit
+	XWing                                 // A digit only fits in the same two columns of two rows of a sub-sudoku, or the other way round
+	Swordfish                             // A digit only fits in three columns of three rows of a sub-sudoku, or the other way round
+	XYWing                                // A cell holding x or y sees cells holding x or z and y or z, ruling z out of the cells seeing both
+	SimpleColouring                       // A chain of cells a digit is in one of two of, alternately, rules it out of cells seeing both colours
+)
+
+func (t Technique) String() string {
+	switch t {
+	case NakedSingle:
+		return "naked single"
+	case HiddenSingle:
+		return "hidden single"
+	case LockedCandidates:
+		return "locked candidates"
+	case NakedPair:
+		return "naked pair"
+	case HiddenPair:
+		return "hidden pair"
+	case NakedTriple:
+		return "naked triple"
+	case HiddenTriple:
+		return "hidden triple"
+	case XWing:
+		return "X-wing"
+	case Swordfish:
+		return "swordfish"
+	case XYWing:
+		return "XY-wing"
+	case SimpleColouring:
+		return "simple colouring"
+	}
+	return fmt.Sprintf("Technique(%d)", int(t))
+}
+
+// ErrSolved is returned by Hint for a grid without empty cells
+var ErrSolved = errors.New("sudoku: the grid is already full")
+
+// ErrNoHint is returned by Hint when none of the techniques make progress on the grid
+var ErrNoHint = errors.New("sudoku: no simple deduction found")
+
+// Deduction is a step towards solving a puzzle, as found by Hint
+type Deduction struct {
+	Technique    Technique
+	Cell         Cell     // Cell Value goes in, for singles
+	Value        int      // Digit placed in Cell, or ruled out of Eliminations, 0 if Digits are
+	Digits       []int    // Digits ruled out of Eliminations, for pairs and triples
+	Eliminations []Cell   // Cells Value or Digits are ruled out of, for techniques other than singles
+	Position     Position // Sub-sudoku of Cell for naked singles, of the unit or cells the deduction is made from otherwise
+	Unit         string   // Kind of that unit, "row", "column", "box", "region", "diagonal" or "window", empty for naked singles and wings
+	Index        int      // Index of the unit within its sub-sudoku
+	Overlap      Position // Sub-sudoku sharing cells with Position whose digits the deduction relies on, 0 if Position's are enough
+	Explanation  string
+}
+
+// hinter looks for deductions among the candidates of the empty cells of an engine's grid
+type hinter struct {
+	*engine
+	masks  []uint32        // Candidates of each cell, 0 for filled ones
+	unitAt map[unitKey]int // Units by sub-sudoku, kind and index
+	peers  [][]int         // Cells sharing a unit with each cell, computed by findPeers
+	isPeer []map[int]bool
+}
+
+// unitKey A unit's sub-sudoku, kind and index
+type unitKey struct {
+	position Position
+	kind     string
+	index    int
+}
+
+//Hint returns the simplest deduction that can be made on the puzzle's grid, trying the techniques in the order of Technique:
+//singles, then locked candidates, pairs, triples, X-wings, swordfish, XY-wings and simple colouring. Among singles and locked
+//candidates, those holding within a single sub-sudoku come before those that only hold because of the digits of a sub-sudoku it
+//overlaps, such as Centre. Deductions follow the rules of the puzzle's variant too.
+//It returns the errors of Validate for grids breaking a rule, ErrSolved for full grids, ErrUnsolvable if a cell or a digit of a unit
+//has no candidate left, and ErrNoHint if no technique applies
+func Hint(samurai *SamuraiSudoku) (*Deduction, error) {
+	c, err := NewCandidates(samurai)
+	if err != nil {
+		return nil, err
+	}
+	return c.Deduce()
+}
+
+//Deduce returns the simplest deduction left given the candidates, as Hint does, candidates eliminated so far staying out.
+//It returns ErrSolved once every cell is filled, ErrUnsolvable if a cell or a digit of a unit has no candidate left,
+//and ErrNoHint if no technique applies
+func (c *Candidates) Deduce() (*Deduction, error) {
+	h := c.hinter()
+	full := true
+	for i, d := range h.cells {
+		if h.grid[d.Row][d.Column] != 0 {
+			continue
+		}
+		full = false
+		if h.masks[i] == 0 {
+			return nil, ErrUnsolvable
+		}
+	}
+	if full {
+		return nil, ErrSolved
+	}
+	techniques := []func() (*Deduction, error){
+		h.nakedSingle,
+		h.hiddenSingle,
+		h.lockedCandidates,
+		func() (*Deduction, error) { return h.nakedSubset(2) },
+		func() (*Deduction, error) { return h.hiddenSubset(2) },
+		func() (*Deduction, error) { return h.nakedSubset(3) },
+		func() (*Deduction, error) { return h.hiddenSubset(3) },
+		func() (*Deduction, error) { return h.fish(2) },
+		func() (*Deduction, error) { return h.fish(3) },
+		h.xyWing,
+		h.simpleColouring,
+	}
+	for _, find := range techniques {
+		d, err := find()
+		if err != nil {
+			return nil, err
+		}
+		if d != nil {
+			return d, nil
+		}
+	}
+	return nil, ErrNoHint
+}
+
+//hinter returns a hinter looking for deductions among the candidates
+func (c *Candidates) hinter() *hinter {
+	h := &hinter{engine: c.engine, masks: c.masks, unitAt: make(map[unitKey]int)}
+	for u, unit := range h.units {
+		h.unitAt[unitKey{unit.position, unit.kind, unit.index}] = u
+	}
+	return h
+}
+
+//Apply places the digit of a single in its cell, or rules the digits of any other deduction out of its eliminations.
+//It returns an error if the digit of a single isn't one of its cell's candidates
+func (c *Candidates) Apply(d *Deduction) error {
+	if d.Technique == NakedSingle || d.Technique == HiddenSingle {
+		return c.Place(d.Cell, d.Value)
+	}
+	digits := d.Digits
+	if d.Value != 0 {
+		digits = []int{d.Value}
+	}
+	for _, cell := range d.Eliminations {
+		c.Eliminate(cell, digits...)
+	}
+	return nil
+}
+
+//nakedSingle returns the first empty cell with a single candidate, nil if there is none
+func (h *hinter) nakedSingle() (*Deduction, error) {
+	var first *Deduction
+	for i, mask := range h.masks {
+		if mask == 0 || mask&(mask-1) != 0 {
+			continue
+		}
+		n := bits.TrailingZeros32(mask)
+		c := h.cells[i]
+		for _, p := range h.layout.placements(c.Row, c.Column) {
+			overlap := Position(0)
+			for m := 1; m <= h.size && overlap == 0; m++ {
+				if m != n {
+					overlap = h.overlap(i, m, p.position)
+				}
+			}
+			d := &Deduction{
+				Technique: NakedSingle,
+				Cell:      c,
+				Value:     n,
+				Position:  p.position,
+				Overlap:   overlap,
+			}
+			d.Explanation = fmt.Sprintf("%s of the %s sub-sudoku can only hold %d, every other digit being ruled out of it", capitalize(h.cellName(i, p.position)), p.position, n)
+			if overlap != 0 {
+				d.Explanation += fmt.Sprintf(", some by cells of the %s sub-sudoku", overlap)
+			}
+			if overlap == 0 {
+				return d, nil
+			}
+			if first == nil {
+				first = d
+			}
+		}
+	}
+	return first, nil
+}
+
+//hiddenSingle returns the first digit with a single cell left in a unit, nil if there is none.
+//It returns ErrUnsolvable for a digit missing from a unit without a cell left for it
+func (h *hinter) hiddenSingle() (*Deduction, error) {
+	var first *Deduction
+	for u, unit := range h.units {
+		for n := 1; n <= h.size; n++ {
+			if h.used[u]&(1<<n) != 0 {
+				continue
+			}
+			places := h.places(u, n)
+			if len(places) == 0 {
+				return nil, ErrUnsolvable
+			}
+			if len(places) > 1 {
+				continue
+			}
+			i := places[0]
+			d := &Deduction{
+				Technique: HiddenSingle,
+				Cell:      h.cells[i],
+				Value:     n,
+				Position:  unit.position,
+				Unit:      unit.kind,
+				Index:     unit.index,
+				Overlap:   h.unitOverlap(u, n),
+			}
+			d.Explanation = fmt.Sprintf("In %s, %d only fits in %s", h.unitName(u), n, h.cellName(i, unit.position))
+			if d.Overlap != 0 {
+				d.Explanation += fmt.Sprintf(", once the digits of the %s sub-sudoku it overlaps are taken into account", d.Overlap)
+			}
+			if d.Overlap == 0 {
+				return d, nil
+			}
+			if first == nil {
+				first = d
+			}
+		}
+	}
+	return first, nil
+}
+
+//lockedCandidates returns the first digit whose cells left in a unit all lie in another unit, which has other cells the digit
+//can then be ruled out of, nil if there is none
+func (h *hinter) lockedCandidates() (*Deduction, error) {
+	var first *Deduction
+	for u, unit := range h.units {
+		for n := 1; n <= h.size; n++ {
+			if h.used[u]&(1<<n) != 0 {
+				continue
+			}
+			places := h.places(u, n)
+			if len(places) < 2 {
+				continue
+			}
+			for _, v := range h.cellUnits[places[0]] {
+				if v == u || !h.inUnit(v, places) {
+					continue
+				}
+				var eliminations []int
+				for _, j := range h.unitCells[v] {
+					if h.masks[j]&(1<<n) != 0 && !h.inUnit(u, []int{j}) {
+						eliminations = append(eliminations, j)
+					}
+				}
+				if len(eliminations) == 0 {
+					continue
+				}
+				other := h.units[v]
+				d := &Deduction{
+					Technique: LockedCandidates,
+					Value:     n,
+					Position:  unit.position,
+					Unit:      unit.kind,
+					Index:     unit.index,
+					Overlap:   h.unitOverlap(u, n),
+				}
+				if other.position != unit.position {
+					d.Overlap = other.position
+				}
+				for _, j := range eliminations {
+					d.Eliminations = append(d.Eliminations, h.cells[j])
+				}
+				names := make([]string, len(eliminations))
+				for k, j := range eliminations {
+					names[k] = h.cellName(j, other.position)
+				}
+				in := h.unitName(u)
+				if d.Overlap != 0 && d.Overlap != other.position {
+					in += fmt.Sprintf(", once the digits of the %s sub-sudoku it overlaps are taken into account", d.Overlap)
+				}
+				d.Explanation = fmt.Sprintf("In %s, %d only fits in cells of %s, so it can't go in that %s's other cells: %s", in, n, h.unitName(v), other.kind, strings.Join(names, "; "))
+				if d.Overlap == 0 {
+					return d, nil
+				}
+				if first == nil {
+					first = d
+				}
+			}
+		}
+	}
+	return first, nil
+}
+
+//places returns the empty cells of the u-th unit n is a candidate of
+func (h *hinter) places(u int, n int) []int {
+	var places []int
+	for _, i := range h.unitCells[u] {
+		if h.masks[i]&(1<<n) != 0 {
+			places = append(places, i)
+		}
+	}
+	return places
+}
+
+//inUnit tells if all of cells belong to the u-th unit
+func (h *hinter) inUnit(u int, cells []int) bool {
+	for _, i := range cells {
+		found := false
+		for _, v := range h.cellUnits[i] {
+			if v == u {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+//overlap returns a sub-sudoku other than position with a unit holding n that the i-th cell is in, if no unit of position holds n.
+//It returns 0 if a unit of position holds n, or if no unit does, n being ruled out by the variant's other rules
+func (h *hinter) overlap(i int, n int, position Position) Position {
+	other := Position(0)
+	for _, u := range h.cellUnits[i] {
+		if h.used[u]&(1<<n) == 0 {
+			continue
+		}
+		if h.units[u].position == position {
+			return 0
+		}
+		if other == 0 {
+			other = h.units[u].position
+		}
+	}
+	return other
+}
+
+//unitOverlap returns a sub-sudoku other than the u-th unit's that rules n out of one of the unit's empty cells,
+//0 if the unit's own sub-sudoku rules n out of all those it is ruled out of
+func (h *hinter) unitOverlap(u int, n int) Position {
+	position := h.units[u].position
+	for _, i := range h.unitCells[u] {
+		c := h.cells[i]
+		if h.grid[c.Row][c.Column] != 0 || h.masks[i]&(1<<n) != 0 {
+			continue
+		}
+		if other := h.overlap(i, n, position); other != 0 {
+			return other
+		}
+	}
+	return 0
+}
+
+//unitName names the u-th unit for an explanation, e.g. "box 2 of the top left sub-sudoku"
+func (h *hinter) unitName(u int) string {
+	unit := h.units[u]
+	return fmt.Sprintf("%s %d of the %s sub-sudoku", unit.kind, unit.index+1, unit.position)
+}
+
+//cellName names the i-th cell by its row and column in position's sub-sudoku for an explanation, e.g. "row 1, column 2"
+func (h *hinter) cellName(i int, position Position) string {
+	c := h.cells[i]
+	for _, p := range h.layout.placements(c.Row, c.Column) {
+		if p.position == position {
+			return fmt.Sprintf("row %d, column %d", p.row+1, p.column+1)
+		}
+	}
+	return formatCell(c)
+}
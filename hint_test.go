@@ -0,0 +1,122 @@
+package sudoku
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTechniqueString(t *testing.T) {
+	if got := LockedCandidates.String(); got != "locked candidates" {
+		t.Fatalf("want locked candidates, got %q", got)
+	}
+}
+
+func TestHint(t *testing.T) {
+	solution, err := GlobalSolveSamuraiSudokuContext(context.Background(), newTestSamurai())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name  string
+		cells map[Cell]int // Digits placed on an empty grid, the solution with (0, 0) cleared if nil
+		want  Deduction
+	}{
+		{
+			name: "naked single",
+			want: Deduction{Technique: NakedSingle, Cell: Cell{0, 0}, Value: solution[0][0], Position: TopLeft},
+		},
+		{
+			// 1 is ruled out of the rest of the top left sub-sudoku's first row by its first two boxes and columns
+			name:  "hidden single",
+			cells: map[Cell]int{{1, 4}: 1, {2, 7}: 1, {4, 1}: 1, {7, 2}: 1},
+			want:  Deduction{Technique: HiddenSingle, Cell: Cell{0, 0}, Value: 1, Position: TopLeft, Unit: "row", Index: 0},
+		},
+		{
+			// the top left sub-sudoku's last row only has room for 1 in its last cell once the columns of the centre one,
+			// which start in the box they share, are taken into account
+			name:  "hidden single across the overlap",
+			cells: map[Cell]int{{6, 0}: 1, {7, 3}: 1, {10, 6}: 1, {13, 7}: 1},
+			want:  Deduction{Technique: HiddenSingle, Cell: Cell{8, 8}, Value: 1, Position: TopLeft, Unit: "row", Index: 8, Overlap: Centre},
+		},
+		{
+			// the top left sub-sudoku's first box only has room for 1 in its first row
+			name:  "locked candidates",
+			cells: map[Cell]int{{1, 0}: 2, {1, 1}: 3, {1, 2}: 4, {2, 0}: 5, {2, 1}: 6, {2, 2}: 7},
+			want: Deduction{Technique: LockedCandidates, Value: 1, Position: TopLeft, Unit: "box", Index: 0,
+				Eliminations: []Cell{{0, 3}, {0, 4}, {0, 5}, {0, 6}, {0, 7}, {0, 8}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			grid := copyGrid(solution)
+			grid[0][0] = 0
+			if tc.cells != nil {
+				grid = NewSamuraiGrid()
+				for c, n := range tc.cells {
+					grid[c.Row][c.Column] = n
+				}
+			}
+			var samurai SamuraiSudoku
+			samurai.SetGrid(grid)
+
+			got, err := Hint(&samurai)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Explanation == "" {
+				t.Fatalf("want an explanation")
+			}
+			if !strings.Contains(got.Explanation, tc.want.Position.String()) || tc.want.Overlap != 0 && !strings.Contains(got.Explanation, tc.want.Overlap.String()) {
+				t.Fatalf("want the sub-sudokus named in %q", got.Explanation)
+			}
+			got.Explanation = ""
+			if !reflect.DeepEqual(*got, tc.want) {
+				t.Fatalf("want %+v, got %+v", tc.want, *got)
+			}
+		})
+	}
+}
+
+func TestHintErrors(t *testing.T) {
+	solution, err := GlobalSolveSamuraiSudokuContext(context.Background(), newTestSamurai())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conflict := NewSamuraiGrid()
+	conflict[0][0], conflict[0][1] = 1, 1
+	// the first box of the top left sub-sudoku has no room left for 1
+	unsolvable := NewSamuraiGrid()
+	for c, n := range map[Cell]int{{0, 4}: 1, {1, 7}: 1, {4, 0}: 1, {7, 1}: 1, {2, 2}: 2} {
+		unsolvable[c.Row][c.Column] = n
+	}
+
+	testCases := []struct {
+		name string
+		grid Grid
+		want error
+	}{
+		{"solved", solution, ErrSolved},
+		{"empty", NewSamuraiGrid(), ErrNoHint},
+		{"no room for a digit", unsolvable, ErrUnsolvable},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var samurai SamuraiSudoku
+			samurai.SetGrid(tc.grid)
+			if _, err := Hint(&samurai); !errors.Is(err, tc.want) {
+				t.Fatalf("want %v, got %v", tc.want, err)
+			}
+		})
+	}
+
+	var samurai SamuraiSudoku
+	samurai.SetGrid(conflict)
+	var conflictErr *ConflictError
+	if _, err := Hint(&samurai); !errors.As(err, &conflictErr) {
+		t.Fatalf("want a *ConflictError, got %v", err)
+	}
+}
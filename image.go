@@ -0,0 +1,356 @@
+package sudoku
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"sort"
+)
+
+// ImageFormat is the format WriteImage draws a puzzle in
+type ImageFormat int
+
+const (
+	ImagePNG ImageFormat = iota
+	ImageSVG
+)
+
+func (f ImageFormat) String() string {
+	switch f {
+	case ImagePNG:
+		return "png"
+	case ImageSVG:
+		return "svg"
+	}
+	return "unknown"
+}
+
+// ImageOptions configures how WriteImage draws a puzzle for print
+type ImageOptions struct {
+	Format     ImageFormat // ImagePNG or ImageSVG
+	CellSize   int         // Side of a cell, in pixels for PNGs and user units for SVGs, defaults to 40
+	Solution   Grid        // Solved grid whose digits are drawn in the puzzle's empty cells, lighter than the givens
+	Font       []byte      // TrueType or OpenType font the digits of PNGs are drawn with, defaults to Go Regular
+	BoldFont   []byte      // Font the givens of PNGs are drawn with, defaults to Font if it is set and to Go Bold otherwise
+	FontFamily string      // CSS font family the digits of SVGs are written in, defaults to sans-serif
+}
+
+const defaultImageCellSize = 40
+
+// Widths of the lines of a puzzle's picture, in cells
+const (
+	cellLineWidth = 0.025
+	boxLineWidth  = 0.06
+	gridLineWidth = 0.09
+	markLineWidth = 0.04 // Outlines of markers, arrows and cages
+)
+
+// Font sizes of the text of a puzzle's picture, in cells
+const (
+	digitTextSize = 0.6
+	cageTextSize  = 0.25
+)
+
+// imageMargin is the room left around the canvas of a picture, in cells, so that the lines along its edges fit
+const imageMargin = 0.125
+
+// Colours of a puzzle's picture, whichever pen draws it
+var (
+	imagePaperColour    = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	imageInkColour      = color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0xff}
+	imageOverlapColour  = color.RGBA{R: 0xe4, G: 0xe4, B: 0xe4, A: 0xff}
+	imageDiagonalColour = color.RGBA{R: 0xa0, G: 0xa0, B: 0xa0, A: 0xff}
+	imageSolutionColour = color.RGBA{R: 0x40, G: 0x60, B: 0x90, A: 0xff}
+	imageMarkColour     = color.RGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xff} // Markers and arrows
+	imageCageColour     = color.RGBA{R: 0x20, G: 0x40, B: 0xa0, A: 0xff}
+	imageWindowColour   = color.RGBA{R: 0x30, G: 0x90, B: 0x30, A: 0xff}
+	imageThermoColour   = color.RGBA{R: 0x90, G: 0x90, B: 0x90, A: 0xff}
+	imageWhispersColour = color.RGBA{R: 0x40, G: 0xc0, B: 0x40, A: 0xff}
+	imageShapeColour    = color.RGBA{R: 0xc8, G: 0xc8, B: 0xc8, A: 0xff} // Restricted cells
+)
+
+// point A position on a puzzle's picture, in cells right and down from the top left corner of its canvas
+type point struct {
+	x, y float64
+}
+
+// pen draws the shapes a puzzle's picture is made of, positions and sizes being measured in cells
+type pen interface {
+	polygon(points []point, c color.RGBA)                                  // Fills the polygon
+	polyline(points []point, width float64, c color.RGBA, dashed bool)     // Strokes the line through points, with round ends
+	disc(centre point, r float64, c color.RGBA)                            // Fills the circle
+	circle(centre point, r float64, width float64, c color.RGBA)           // Strokes the circle
+	text(centre point, text string, size float64, bold bool, c color.RGBA) // Writes text, size being the height of the font
+}
+
+//WriteImage draws samurai for print as an SVG or PNG image to w, as options ask: its sub-sudokus framed by thick lines,
+//their boxes, or jigsaw regions, by thinner ones, the cells shared by overlapping sub-sudokus shaded and the givens in bold,
+//along with the elements of its variant. Gaps are left blank.
+//It returns ErrInvalidGrid if the puzzle's grid, or the solution, isn't shaped like its layout
+func WriteImage(w io.Writer, samurai *SamuraiSudoku, options ImageOptions) error {
+	layout := samurai.Layout()
+	grid := samurai.Grid()
+	if err := checkShape(grid, layout); err != nil {
+		return err
+	}
+	if options.Solution != nil {
+		if err := checkShape(options.Solution, layout); err != nil {
+			return fmt.Errorf("solution: %w", err)
+		}
+	}
+	cellSize := options.CellSize
+	if cellSize <= 0 {
+		cellSize = defaultImageCellSize
+	}
+
+	rows, columns := layout.Size()
+	width, height := float64(columns)+2*imageMargin, float64(rows)+2*imageMargin
+	switch options.Format {
+	case ImagePNG:
+		p, err := newPNGPen(width, height, float64(cellSize), options)
+		if err != nil {
+			return err
+		}
+		drawPicture(p, samurai, grid, options.Solution)
+		return p.encode(w)
+	case ImageSVG:
+		p := newSVGPen(width, height, float64(cellSize), options)
+		drawPicture(p, samurai, grid, options.Solution)
+		return p.encode(w)
+	}
+	return fmt.Errorf("sudoku: unknown image format %v", options.Format)
+}
+
+//drawPicture draws the puzzle with grid's digits as givens, and those solution has in the empty cells if it isn't nil
+func drawPicture(p pen, samurai *SamuraiSudoku, grid Grid, solution Grid) {
+	layout := samurai.Layout()
+	rows, columns := layout.Size()
+
+	p.polygon(square(-imageMargin, -imageMargin, float64(rows)+2*imageMargin, float64(columns)+2*imageMargin), imagePaperColour)
+	for y := 0; y < rows; y++ {
+		for x := 0; x < columns; x++ {
+			if len(layout.placements(y, x)) > 1 {
+				p.polygon(square(float64(y), float64(x), 1, 1), imageOverlapColour)
+			}
+		}
+	}
+
+	drawVariantBelow(p, layout, samurai.Variant())
+	drawLines(p, samurai.Borders())
+	drawVariantAbove(p, layout, samurai.Variant())
+
+	symbols := layout.symbols
+	for y, row := range grid {
+		for x, num := range row {
+			c := Cell{y, x}
+			switch {
+			case num > 0:
+				p.text(middle(c), symbol(symbols, num), digitTextSize, true, imageInkColour)
+			case num == 0 && solution != nil && solution[y][x] > 0:
+				p.text(middle(c), symbol(symbols, solution[y][x]), digitTextSize, false, imageSolutionColour)
+			}
+		}
+	}
+}
+
+//drawLines draws the lines along the sides of the cells, the heavier ones over the lighter ones where they meet
+func drawLines(p pen, borders Borders) {
+	widths := [...]float64{CellBorder: cellLineWidth, BoxBorder: boxLineWidth, GridBorder: gridLineWidth}
+	for _, kind := range []Border{CellBorder, BoxBorder, GridBorder} {
+		half := widths[kind] / 2
+		for y, row := range borders.Left {
+			for x, b := range row {
+				if b == kind {
+					p.polygon(square(float64(y)-half, float64(x)-half, 1+2*half, 2*half), imageInkColour)
+				}
+			}
+		}
+		for y, row := range borders.Top {
+			for x, b := range row {
+				if b == kind {
+					p.polygon(square(float64(y)-half, float64(x)-half, 2*half, 1+2*half), imageInkColour)
+				}
+			}
+		}
+	}
+}
+
+//drawVariantBelow draws the elements of the puzzle's variant that go below the lines of the grid: restricted cells, diagonals
+//and line constraints
+func drawVariantBelow(p pen, layout *Layout, variant *Variant) {
+
+	// restricted cells as grey shapes in their middle: squares for even cells, discs for odd ones, outlines for other digits
+	if variant != nil {
+		even, odd := EvenCells(layout.size).mask(), OddCells(layout.size).mask()
+		for _, r := range variant.Restrictions {
+			for _, c := range r.Cells {
+				switch r.mask() {
+				case even:
+					p.polygon(square(float64(c.Row)+0.25, float64(c.Column)+0.25, 0.5, 0.5), imageShapeColour)
+				case odd:
+					p.disc(middle(c), 0.25, imageShapeColour)
+				default:
+					shape := square(float64(c.Row)+0.25, float64(c.Column)+0.25, 0.5, 0.5)
+					p.polyline(append(shape, shape[0]), markLineWidth, imageShapeColour, false)
+				}
+			}
+		}
+	}
+
+	// diagonals of Sudoku-X sub-sudokus from corner to corner
+	for _, g := range layout.SubGrids() {
+		if variant.diagonal(g.Position) {
+			top, left, size := float64(g.Row), float64(g.Column), float64(layout.size)
+			p.polyline([]point{{left, top}, {left + size, top + size}}, markLineWidth, imageDiagonalColour, false)
+			p.polyline([]point{{left + size, top}, {left, top + size}}, markLineWidth, imageDiagonalColour, false)
+		}
+	}
+
+	if variant != nil {
+		for _, constraint := range variant.Constraints {
+			drawPictureConstraint(p, constraint)
+		}
+	}
+}
+
+//drawVariantAbove draws the elements of the puzzle's variant that go above the lines of the grid: windows, cages and markers
+func drawVariantAbove(p pen, layout *Layout, variant *Variant) {
+
+	// hyper sudoku windows as outlines just inside their cells
+	for _, g := range layout.SubGrids() {
+		if !variant.window(g.Position) {
+			continue
+		}
+		for _, w := range windows(layout.box) {
+			inset := 1.0 / 12
+			outline := square(float64(g.Row+w.Min.Y)+inset, float64(g.Column+w.Min.X)+inset, float64(w.Dy())-2*inset, float64(w.Dx())-2*inset)
+			p.polyline(append(outline, outline[0]), markLineWidth, imageWindowColour, false)
+		}
+	}
+
+	if variant != nil {
+		for _, cage := range variant.Cages {
+			drawPictureCage(p, cage)
+		}
+		for _, m := range variant.Markers {
+			drawPictureMarker(p, m)
+		}
+	}
+}
+
+//square returns the corners of the rectangle h by w cells from top left corner y,x
+func square(y float64, x float64, h float64, w float64) []point {
+	return []point{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}}
+}
+
+//middle returns the middle of cell c
+func middle(c Cell) point {
+	return point{float64(c.Column) + 0.5, float64(c.Row) + 0.5}
+}
+
+//drawPictureConstraint draws a line constraint through the middle of its cells: a thermometer thick and grey from a round bulb,
+//an arrow thin from a circle to an arrowhead, a whispers line green. Other constraints aren't drawn
+func drawPictureConstraint(p pen, constraint Constraint) {
+	path := func(cells []Cell) []point {
+		points := make([]point, len(cells))
+		for i, c := range cells {
+			points[i] = point{float64(c.Column) + 0.5, float64(c.Row) + 0.5}
+		}
+		return points
+	}
+	switch cells := constraint.(type) {
+	case Thermo:
+		points := path(cells)
+		p.polyline(points, 1.0/6, imageThermoColour, false)
+		p.disc(points[0], 1.0/3, imageThermoColour)
+	case Arrow:
+		// the shaft starts on the circle, the head points the way the last step goes
+		points := path(cells)
+		const r = 0.4
+		dx, dy := points[1].x-points[0].x, points[1].y-points[0].y
+		length := math.Hypot(dx, dy)
+		points[0] = point{points[0].x + dx/length*r, points[0].y + dy/length*r}
+		p.polyline(points, markLineWidth, imageMarkColour, false)
+		p.circle(path(cells[:1])[0], r, markLineWidth, imageMarkColour)
+		tip, before := points[len(points)-1], points[len(points)-2]
+		dx, dy = tip.x-before.x, tip.y-before.y
+		length = math.Hypot(dx, dy) * 6
+		dx, dy = dx/length, dy/length
+		p.polyline([]point{{tip.x - dx - dy, tip.y - dy + dx}, tip, {tip.x - dx + dy, tip.y - dy - dx}}, markLineWidth, imageMarkColour, false)
+	case Whispers:
+		p.polyline(path(cells), 1.0/9, imageWhispersColour, false)
+	}
+}
+
+//drawPictureCage draws a killer cage as a dashed outline just inside its cells, with its sum in the top left corner of its first cell
+func drawPictureCage(p pen, cage Cage) {
+	const inset = 1.0 / 8
+	in := make(map[Cell]bool, len(cage.Cells))
+	for _, c := range cage.Cells {
+		in[c] = true
+	}
+	for _, c := range cage.Cells {
+		top, left := float64(c.Row), float64(c.Column)
+		// sides towards a cell of the same cage run to the edge of the cell, so the outline joins the next cell's
+		x0, x1, y0, y1 := left+inset, left+1-inset, top+inset, top+1-inset
+		if in[Cell{c.Row, c.Column - 1}] {
+			x0 = left
+		}
+		if in[Cell{c.Row, c.Column + 1}] {
+			x1 = left + 1
+		}
+		if in[Cell{c.Row - 1, c.Column}] {
+			y0 = top
+		}
+		if in[Cell{c.Row + 1, c.Column}] {
+			y1 = top + 1
+		}
+		if !in[Cell{c.Row - 1, c.Column}] {
+			p.polyline([]point{{x0, top + inset}, {x1, top + inset}}, cellLineWidth, imageCageColour, true)
+		}
+		if !in[Cell{c.Row + 1, c.Column}] {
+			p.polyline([]point{{x0, top + 1 - inset}, {x1, top + 1 - inset}}, cellLineWidth, imageCageColour, true)
+		}
+		if !in[Cell{c.Row, c.Column - 1}] {
+			p.polyline([]point{{left + inset, y0}, {left + inset, y1}}, cellLineWidth, imageCageColour, true)
+		}
+		if !in[Cell{c.Row, c.Column + 1}] {
+			p.polyline([]point{{left + 1 - inset, y0}, {left + 1 - inset, y1}}, cellLineWidth, imageCageColour, true)
+		}
+	}
+
+	cells := append([]Cell(nil), cage.Cells...)
+	sort.Slice(cells, func(i, j int) bool {
+		return cells[i].Row < cells[j].Row || cells[i].Row == cells[j].Row && cells[i].Column < cells[j].Column
+	})
+	if len(cells) == 0 {
+		return
+	}
+	// the sum is written over the corner of the outline, on paper so that the dashes don't cross it
+	const size = cageTextSize
+	sum := fmt.Sprint(cage.Sum)
+	width := float64(len(sum))*size*0.6 + 0.04
+	top, left := float64(cells[0].Row)+0.04, float64(cells[0].Column)+0.04
+	p.polygon([]point{{left, top}, {left + width, top}, {left + width, top + size}, {left, top + size}}, imagePaperColour)
+	p.text(point{left + width/2, top + size/2}, sum, size, false, imageCageColour)
+}
+
+//drawPictureMarker draws m over the middle of the side its cells share: Kropki dots as white or black discs, X and V sums as their letter
+func drawPictureMarker(p pen, m Marker) {
+	a, b := m.Cells[0], m.Cells[1]
+	centre := point{float64(a.Column+b.Column+1) / 2, float64(a.Row+b.Row+1) / 2}
+	const r = 1.0 / 7
+	switch m.Kind {
+	case WhiteDot:
+		p.disc(centre, r, imagePaperColour)
+		p.circle(centre, r, markLineWidth, imageMarkColour)
+	case BlackDot:
+		p.disc(centre, r+markLineWidth/2, imageMarkColour)
+	case XSum:
+		p.polyline([]point{{centre.x - r, centre.y - r}, {centre.x + r, centre.y + r}}, markLineWidth, imageMarkColour, false)
+		p.polyline([]point{{centre.x - r, centre.y + r}, {centre.x + r, centre.y - r}}, markLineWidth, imageMarkColour, false)
+	case VSum:
+		p.polyline([]point{{centre.x - r, centre.y - r}, {centre.x, centre.y + r}, {centre.x + r, centre.y - r}}, markLineWidth, imageMarkColour, false)
+	}
+}
@@ -0,0 +1,191 @@
+package sudoku
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+)
+
+//testSolution returns the solution of the test puzzle, leaving the puzzle as it is
+func testSolution(t *testing.T) Grid {
+	var solved SamuraiSudoku
+	solved.SetGrid(copyGrid(newTestSamurai().Grid()))
+	solution := SolveSamuraiSudoku(&solved)
+	if !solution.isSolved() {
+		t.Fatal("want the test puzzle solved")
+	}
+	return solution
+}
+
+// testCellSize is the side of a cell, in pixels, of the images the drawing of variants is checked on
+const testCellSize = 100
+
+//pictureImage draws samurai as a PNG picture testCellSize pixels to a cell
+func pictureImage(t *testing.T, samurai *SamuraiSudoku) image.Image {
+	var buf bytes.Buffer
+	if err := WriteImage(&buf, samurai, ImageOptions{CellSize: testCellSize}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("output is not a png: %v", err)
+	}
+	return img
+}
+
+//emptyTestSamurai returns a samurai sudoku without digits, so that the elements of its variant are drawn alone in its cells
+func emptyTestSamurai() *SamuraiSudoku {
+	var samurai SamuraiSudoku
+	samurai.SetGrid(NewSamuraiGrid())
+	return &samurai
+}
+
+//hasColour tells if a pixel of img, drawn testCellSize pixels to a cell, is c between the points y0,x0 and y1,x1 of the canvas
+func hasColour(img image.Image, y0 float64, x0 float64, y1 float64, x1 float64, c color.RGBA) bool {
+	wr, wg, wb, _ := c.RGBA()
+	for y := int((y0 + imageMargin) * testCellSize); y <= int((y1+imageMargin)*testCellSize); y++ {
+		for x := int((x0 + imageMargin) * testCellSize); x <= int((x1+imageMargin)*testCellSize); x++ {
+			if r, g, b, _ := img.At(x, y).RGBA(); r == wr && g == wg && b == wb {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestWriteImagePNG(t *testing.T) {
+	samurai := newTestSamurai()
+	var buf bytes.Buffer
+	if err := WriteImage(&buf, samurai, ImageOptions{CellSize: 20, Solution: testSolution(t)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("output is not a png: %v", err)
+	}
+	// 21 cells and a margin of an eighth of a cell on both sides
+	if got := img.Bounds(); got != image.Rect(0, 0, 425, 425) {
+		t.Fatalf("want bounds %v, got %v", image.Rect(0, 0, 425, 425), got)
+	}
+
+	same := func(c color.Color, want color.RGBA) bool {
+		r, g, b, _ := c.RGBA()
+		wr, wg, wb, _ := want.RGBA()
+		return r == wr && g == wg && b == wb
+	}
+	// pixel returns the colour of the point of canvas cell y,x the fractions give
+	pixel := func(y float64, x float64) color.Color {
+		return img.At(int((x+imageMargin)*20), int((y+imageMargin)*20))
+	}
+	testCases := []struct {
+		name string
+		y, x float64
+		want color.RGBA
+	}{
+		{"gap", 10.5, 0.5, imagePaperColour},
+		{"overlap", 6.1, 6.1, imageOverlapColour},
+		{"single sub-sudoku", 0.1, 0.1, imagePaperColour},
+		{"edge of a sub-sudoku", 0.5, 0, imageInkColour},
+		{"edge of a box", 0.5, 3, imageInkColour},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := pixel(tc.y, tc.x); !same(got, tc.want) {
+				t.Fatalf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+
+	// the empty top left cell holds the 1 of the solution, the cell right of its neighbour the given 5
+	inks := func(y int, x int, c color.RGBA) int {
+		count := 0
+		for py := int((float64(y) + imageMargin + 0.2) * 20); py < int((float64(y)+imageMargin+0.8)*20); py++ {
+			for px := int((float64(x) + imageMargin + 0.2) * 20); px < int((float64(x)+imageMargin+0.8)*20); px++ {
+				if same(img.At(px, py), c) {
+					count++
+				}
+			}
+		}
+		return count
+	}
+	if inks(0, 0, imageSolutionColour) == 0 {
+		t.Fatalf("want the solution drawn in the empty top left cell")
+	}
+	if inks(0, 2, imageInkColour) == 0 || inks(0, 2, imageSolutionColour) > 0 {
+		t.Fatalf("want the given of cell (1, 3) drawn in ink")
+	}
+}
+
+func TestWriteImageSVG(t *testing.T) {
+	samurai := newTestSamurai()
+	if err := samurai.SetVariant(&Variant{Cages: []Cage{{Sum: 13, Cells: []Cell{{0, 0}, {0, 1}}}}, Windows: []Position{Centre}}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	options := ImageOptions{Format: ImageSVG, CellSize: 10, Solution: testSolution(t), FontFamily: "Go & Co"}
+	if err := WriteImage(&buf, samurai, options); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	svg := buf.String()
+
+	d := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		if _, err := d.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("output is not well-formed: %v", err)
+		}
+	}
+	for _, want := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" width="212.5" height="212.5" viewBox="0 0 212.5 212.5">`,
+		`font-family="Go &amp; Co"`,
+		// the given of cell (1, 3), bold, and the solution of the top left cell
+		`<text x="25" y="7.1" font-size="6" font-weight="bold" fill="#000000">5</text>`,
+		`<text x="5" y="7.1" font-size="6" fill="#406090">1</text>`,
+		// the shading of the top left overlap cell, and the outline of the centre's first window
+		`<polygon points="60,60 70,60 70,70 60,70" fill="#e4e4e4"/>`,
+		`<polyline points="70.83,70.83 99.17,70.83 99.17,99.17 70.83,99.17 70.83,70.83" fill="none" stroke="#309030"`,
+		// the cage's dashed outline and sum
+		`stroke-dasharray="0.83"`,
+		`>13</text>`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("want the SVG to contain %s", want)
+		}
+	}
+}
+
+func TestWriteImageErrors(t *testing.T) {
+	malformed := newTestSamurai()
+	malformed.SetGrid(Grid{{1, 2, 3}})
+
+	testCases := []struct {
+		name    string
+		samurai *SamuraiSudoku
+		options ImageOptions
+		want    error
+	}{
+		{"malformed grid", malformed, ImageOptions{}, ErrInvalidGrid},
+		{"malformed solution", newTestSamurai(), ImageOptions{Solution: Grid{{1}}}, ErrInvalidGrid},
+		{"bad font", newTestSamurai(), ImageOptions{Font: []byte("not a font")}, nil},
+		{"bad bold font", newTestSamurai(), ImageOptions{BoldFont: []byte("not a font")}, nil},
+		{"unknown format", newTestSamurai(), ImageOptions{Format: ImageFormat(7)}, nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := WriteImage(io.Discard, tc.samurai, tc.options)
+			if err == nil {
+				t.Fatal("want an error")
+			}
+			if tc.want != nil && !errors.Is(err, tc.want) {
+				t.Fatalf("want %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package sudoku
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Jigsaw gives a sub-sudoku irregular regions in place of its boxes, as in jigsaw sudoku.
+// Every region holds as many cells as there are digits, which must all be different
+type Jigsaw struct {
+	Position Position // Sub-sudoku the regions are in
+	Regions  Grid     // Region of every cell of the sub-sudoku, numbered from 1 to the number of digits
+}
+
+//checkJigsaws returns an error wrapping ErrInvalidLayout if a jigsaw is for a sub-sudoku the layout doesn't have, or one
+//that has a jigsaw already, isn't shaped like a sub-sudoku, or has a region that doesn't hold as many cells as there are digits
+func checkJigsaws(jigsaws []Jigsaw, layout *Layout) error {
+	seen := make(map[Position]bool)
+	for _, j := range jigsaws {
+		if _, _, ok := layout.Offset(j.Position); !ok {
+			return fmt.Errorf("%w: %s has no %s sub-sudoku for jigsaw regions", ErrInvalidLayout, layout, j.Position)
+		}
+		if seen[j.Position] {
+			return fmt.Errorf("%w: jigsaw regions of the %s sub-sudoku given twice", ErrInvalidLayout, j.Position)
+		}
+		seen[j.Position] = true
+
+		if len(j.Regions) != layout.size {
+			return fmt.Errorf("%w: want %d rows of jigsaw regions for the %s sub-sudoku, got %d", ErrInvalidLayout, layout.size, j.Position, len(j.Regions))
+		}
+		counts := make([]int, layout.size+1)
+		for _, row := range j.Regions {
+			if len(row) != layout.size {
+				return fmt.Errorf("%w: want %d columns of jigsaw regions for the %s sub-sudoku, got %d", ErrInvalidLayout, layout.size, j.Position, len(row))
+			}
+			for _, r := range row {
+				if r < 1 || r > layout.size {
+					return fmt.Errorf("%w: jigsaw region %d of the %s sub-sudoku isn't between 1 and %d", ErrInvalidLayout, r, j.Position, layout.size)
+				}
+				counts[r]++
+			}
+		}
+		for r, count := range counts[1:] {
+			if count != layout.size {
+				return fmt.Errorf("%w: jigsaw region %d of the %s sub-sudoku has %d cells instead of %d", ErrInvalidLayout, r+1, j.Position, count, layout.size)
+			}
+		}
+	}
+	return nil
+}
+
+//regionUnits returns the regions of j as units of the sub-sudoku at offset y0,x0, ordered by region
+func regionUnits(j Jigsaw, y0 int, x0 int) []unit {
+	units := make([]unit, len(j.Regions))
+	for i := range units {
+		units[i] = unit{position: j.Position, kind: "region", index: i}
+	}
+	for y, row := range j.Regions {
+		for x, r := range row {
+			units[r-1].cells = append(units[r-1].cells, Cell{y0 + y, x0 + x})
+		}
+	}
+	return units
+}
+
+//possibleRegions checks if sudoku, split into regions, can be filled in position y,x with n
+func possibleRegions(sudoku Grid, regions Grid, y int, x int, n int) bool {
+	for i := range sudoku {
+		if sudoku[y][i] == n || sudoku[i][x] == n {
+			return false
+		}
+	}
+	region := regions[y][x]
+	for i, row := range regions {
+		for j, r := range row {
+			if r == region && sudoku[i][j] == n {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+//formatJigsaw writes a jigsaw for a header line, its position followed by a word per row of regions written with symbols,
+//e.g. "centre 111123333 111222333 ..."
+func formatJigsaw(j Jigsaw, symbols string) string {
+	parts := []string{strings.ReplaceAll(j.Position.String(), " ", "-")}
+	for _, row := range j.Regions {
+		var word strings.Builder
+		for _, r := range row {
+			word.WriteString(symbol(symbols, r))
+		}
+		parts = append(parts, word.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+//parseJigsaw parses a header line's jigsaw, as written by formatJigsaw
+func parseJigsaw(value string, symbols string) (Jigsaw, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 2 {
+		return Jigsaw{}, fmt.Errorf("jigsaw %q has no regions", value)
+	}
+	position, err := ParsePosition(fields[0])
+	if err != nil {
+		return Jigsaw{}, err
+	}
+	j := Jigsaw{Position: position}
+	for _, word := range fields[1:] {
+		row := make([]int, 0, len(word))
+		for _, char := range word {
+			r, ok := parseSymbol(symbols, char)
+			if !ok {
+				return Jigsaw{}, fmt.Errorf("bad region %q in jigsaw %q", char, value)
+			}
+			row = append(row, r)
+		}
+		j.Regions = append(j.Regions, row)
+	}
+	return j, nil
+}
@@ -0,0 +1,163 @@
+package sudoku
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+// testJigsaw The regions of testdata/jigsaw.txt's Centre sub-sudoku: boxes whose corners were swapped with their neighbours'
+var testJigsaw = mustJigsaw("centre 111123333 111222333 112222233 444456666 444555666 445555566 777789999 777888999 778888899")
+
+func mustJigsaw(value string) Jigsaw {
+	j, err := parseJigsaw(value, digitSymbols)
+	if err != nil {
+		panic(err)
+	}
+	return j
+}
+
+func TestJigsaw(t *testing.T) {
+	// testdata/jigsaw.txt was made by GenerateSamuraiSudoku with seed 2026, jigsaw regions in the Centre and BottomRight sub-sudokus
+	f, err := os.Open("testdata/jigsaw.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	puzzle, err := ReadPuzzle(f, Samurai, TextFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := puzzle.Variant().Jigsaws; len(got) != 2 || got[0].Position != Centre || got[1].Position != BottomRight {
+		t.Fatalf("want jigsaw regions for the centre and bottom right sub-sudokus, got %+v", got)
+	}
+
+	var samurai SamuraiSudoku
+	if err := samurai.SetVariant(puzzle.Variant()); err != nil {
+		t.Fatal(err)
+	}
+	samurai.SetGrid(puzzle.Grid())
+	if count, err := samurai.CountSolutions(context.Background(), 2); err != nil || count != 1 {
+		t.Fatalf("want a unique solution, got %d, %v", count, err)
+	}
+	solution, err := GlobalSolveSamuraiSudokuContext(context.Background(), &samurai)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the backtracking solvers complete a sub-sudoku at a time and never undo one, which a puzzle as sparse as the file's
+	// rarely allows. They are given its solution with the first three rows of the Centre and the three rows of the
+	// BottomRight from its overlap down cleared instead, which leaves one way to fill them following the regions
+	cleared := copyGrid(solution)
+	for _, c := range []Cell{{6, 6}, {13, 12}} {
+		for y := c.Row; y < c.Row+3; y++ {
+			for x := c.Column; x < c.Column+9; x++ {
+				cleared[y][x] = 0
+			}
+		}
+	}
+	checkVariantSolves(t, cleared, puzzle.Variant(), func(t *testing.T, samurai *SamuraiSudoku) {
+		sudoku := samurai.GetSubSudoku(Centre)
+		digits := make(map[int]map[int]bool)
+		for y, row := range testJigsaw.Regions {
+			for x, r := range row {
+				if digits[r] == nil {
+					digits[r] = make(map[int]bool)
+				}
+				digits[r][sudoku[y][x]] = true
+			}
+		}
+		for r, seen := range digits {
+			if len(seen) != 9 {
+				t.Fatalf("region %d of the centre sub-sudoku repeats digits:\n%v", r, sudoku)
+			}
+		}
+	})
+}
+
+func TestJigsawConflict(t *testing.T) {
+	grid := NewSamuraiGrid()
+	// the centre's (1, 0) and (0, 3) are in region 1 but in different rows, columns and boxes
+	grid[7][6], grid[6][9] = 5, 5
+	if err := Validate(grid); err != nil {
+		t.Fatalf("want the grid valid with boxes, got %v", err)
+	}
+
+	var samurai SamuraiSudoku
+	if err := samurai.SetVariant(&Variant{Jigsaws: []Jigsaw{testJigsaw}}); err != nil {
+		t.Fatal(err)
+	}
+	samurai.SetGrid(grid)
+	var conflict *ConflictError
+	if err := samurai.Validate(); !errors.As(err, &conflict) || conflict.Unit != "region" || conflict.Index != 0 || conflict.Position != Centre {
+		t.Fatalf("want a conflict in the centre's first region, got %v", err)
+	}
+
+	// the centre's (1, 4) is in the box of (0, 3) but in region 2
+	grid[7][6] = 0
+	if possible(samurai.GetSubSudoku(Centre), 1, 0, 5, Centre, &samurai) {
+		t.Fatalf("want 5 impossible in the region already holding it")
+	}
+	if !possible(samurai.GetSubSudoku(Centre), 1, 4, 5, Centre, &samurai) {
+		t.Fatalf("want 5 possible in another region of the same box")
+	}
+	grid[7][10] = 5
+	if err := samurai.Validate(); err != nil {
+		t.Fatalf("want a box holding 5 twice valid in a jigsaw sub-sudoku, got %v", err)
+	}
+}
+
+func TestJigsawErrors(t *testing.T) {
+	short := testJigsaw
+	short.Regions = short.Regions[1:]
+	unbalanced := mustJigsaw("centre 111123333 111222333 112222233 444456666 444555666 445555566 777789999 777888999 778888891")
+	outOfRange := mustJigsaw("centre 111123333 111222333 112222233 444456666 444555666 445555566 777789999 777888999 778888899")
+	outOfRange.Regions[8][8] = 10
+
+	testCases := []struct {
+		name    string
+		layout  *Layout
+		jigsaws []Jigsaw
+	}{
+		{"sub-sudoku outside the layout", Twin, []Jigsaw{testJigsaw}},
+		{"given twice", Samurai, []Jigsaw{testJigsaw, testJigsaw}},
+		{"missing row", Samurai, []Jigsaw{short}},
+		{"region too large", Samurai, []Jigsaw{unbalanced}},
+		{"region out of range", Samurai, []Jigsaw{outOfRange}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var samurai SamuraiSudoku
+			samurai.SetLayout(tc.layout)
+			if err := samurai.SetVariant(&Variant{Jigsaws: tc.jigsaws}); !errors.Is(err, ErrInvalidLayout) {
+				t.Fatalf("want ErrInvalidLayout, got %v", err)
+			}
+		})
+	}
+
+	if _, err := parseJigsaw("middle 123", digitSymbols); err == nil {
+		t.Fatalf("want an error for an unknown position")
+	}
+	if _, err := parseJigsaw("centre 12x", digitSymbols); err == nil {
+		t.Fatalf("want an error for a bad region")
+	}
+}
+
+func TestWriteImageJigsaw(t *testing.T) {
+	samurai := emptyTestSamurai()
+	// left side of the centre's (3, 3), between its boxes 4 and 5 but inside region 4: a box line is wider than a cell line
+	isBorder := func() bool {
+		return hasColour(pictureImage(t, samurai), 9.5, 9.02, 9.5, 9.02, imageInkColour)
+	}
+
+	if !isBorder() {
+		t.Fatalf("want a box border between the centre's boxes")
+	}
+	if err := samurai.SetVariant(&Variant{Jigsaws: []Jigsaw{testJigsaw}}); err != nil {
+		t.Fatal(err)
+	}
+	if isBorder() {
+		t.Fatalf("want no border inside a jigsaw region")
+	}
+}
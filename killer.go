@@ -0,0 +1,200 @@
+package sudoku
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Cell is a cell of the whole grid of a puzzle, counted from 0 at the top left of the canvas
+type Cell struct {
+	Row    int `json:"row"`
+	Column int `json:"column"`
+}
+
+// Cage is a group of cells of a killer puzzle, whose digits must add up to Sum without repeating.
+// Its cells may belong to different sub-sudokus, e.g. cross the overlap between a corner and the Centre
+type Cage struct {
+	Sum   int    `json:"sum"`
+	Cells []Cell `json:"cells"`
+}
+
+// CageError is returned by Validate for a cage whose digits add up to more than its sum, or to another sum once it is full
+type CageError struct {
+	Index int // Index of the cage in the puzzle's Variant
+	Sum   int // Sum of the digits in the cage
+	Want  int // Sum the digits must add up to
+}
+
+func (e *CageError) Error() string {
+	return fmt.Sprintf("sudoku: cage %d adds up to %d instead of %d", e.Index+1, e.Sum, e.Want)
+}
+
+//sumRange returns the smallest and largest sums of count different digits from 1 to size
+func sumRange(count int, size int) (int, int) {
+	return count * (count + 1) / 2, count * (2*size - count + 1) / 2
+}
+
+//cageCandidates returns the digits, as a bitmask, that can go in an empty cell of a cage holding the digits of used, as a bitmask,
+//and empty more cells including this one, so that the cage can still add up to remaining without repeating a digit.
+//Digits are kept when the other empty cells can add up to what is left between the smallest and largest sums of the free digits
+func cageCandidates(used uint32, empty int, remaining int, size int) uint32 {
+	var digits [maxDigits]int
+	free := digits[:0]
+	for n := 1; n <= size; n++ {
+		if used&(1<<n) == 0 {
+			free = append(free, n)
+		}
+	}
+
+	var mask uint32
+	for _, n := range free {
+		rest := remaining - n
+		low, high, count := 0, 0, 0
+		for _, m := range free {
+			if m != n && count < empty-1 {
+				low += m
+				count++
+			}
+		}
+		if count < empty-1 {
+			continue
+		}
+		count = 0
+		for i := len(free) - 1; i >= 0; i-- {
+			if m := free[i]; m != n && count < empty-1 {
+				high += m
+				count++
+			}
+		}
+		if low <= rest && rest <= high {
+			mask |= 1 << n
+		}
+	}
+	return mask
+}
+
+//checkCages returns an error wrapping ErrInvalidLayout if a cage has cells outside layout's sub-sudokus,
+//shares a cell with another cage, holds more cells than there are digits, or has a sum its cells can't add up to
+func checkCages(cages []Cage, layout *Layout) error {
+	seen := make(map[Cell]int)
+	for i, cage := range cages {
+		if len(cage.Cells) == 0 || len(cage.Cells) > layout.size {
+			return fmt.Errorf("%w: cage %d has %d cells", ErrInvalidLayout, i+1, len(cage.Cells))
+		}
+		for _, c := range cage.Cells {
+			if !layout.Contains(c.Row, c.Column) {
+				return fmt.Errorf("%w: cell (%d, %d) of cage %d isn't in a sub-sudoku of %s", ErrInvalidLayout, c.Row+1, c.Column+1, i+1, layout)
+			}
+			if j, ok := seen[c]; ok {
+				return fmt.Errorf("%w: cell (%d, %d) is in cages %d and %d", ErrInvalidLayout, c.Row+1, c.Column+1, j+1, i+1)
+			}
+			seen[c] = i
+		}
+		if low, high := sumRange(len(cage.Cells), layout.size); cage.Sum < low || cage.Sum > high {
+			return fmt.Errorf("%w: the %d cells of cage %d can't add up to %d", ErrInvalidLayout, len(cage.Cells), i+1, cage.Sum)
+		}
+	}
+	return nil
+}
+
+//validateCages checks that the digits of every cage of grid don't repeat, add up to no more than its sum, and to its sum once it is full.
+//It returns a *ConflictError for repeated digits and a *CageError for wrong sums
+func validateCages(grid Grid, cages []Cage) error {
+	for i, cage := range cages {
+		sum, empty, seen := 0, 0, 0
+		for _, c := range cage.Cells {
+			num := grid[c.Row][c.Column]
+			if num == 0 {
+				empty++
+				continue
+			}
+			if seen&(1<<num) != 0 {
+				return &ConflictError{Unit: "cage", Index: i, Num: num}
+			}
+			seen |= 1 << num
+			sum += num
+		}
+		if sum > cage.Sum || (empty == 0 && sum != cage.Sum) {
+			return &CageError{Index: i, Sum: sum, Want: cage.Sum}
+		}
+	}
+	return nil
+}
+
+//possibleCages checks if the canvas cell at row y and column x of grid can be filled with n given the cages it is in, listed in index
+func possibleCages(grid Grid, y int, x int, n int, cages []Cage, index map[Cell][]int, size int) bool {
+	for _, i := range index[Cell{y, x}] {
+		cage := cages[i]
+		var used uint32
+		remaining, empty := cage.Sum, 0
+		for _, c := range cage.Cells {
+			if num := grid[c.Row][c.Column]; num > 0 {
+				used |= 1 << num
+				remaining -= num
+			} else {
+				empty++
+			}
+		}
+		if cageCandidates(used, empty, remaining, size)&(1<<n) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+//cageIndex returns the cages every caged cell is in
+func cageIndex(cages []Cage) map[Cell][]int {
+	index := make(map[Cell][]int)
+	for i, cage := range cages {
+		for _, c := range cage.Cells {
+			index[c] = append(index[c], i)
+		}
+	}
+	return index
+}
+
+//formatCage writes a cage for a header line, its sum followed by its cells counted from 1, e.g. "15 r1c1 r1c2"
+func formatCage(cage Cage) string {
+	parts := []string{strconv.Itoa(cage.Sum)}
+	for _, c := range cage.Cells {
+		parts = append(parts, formatCell(c))
+	}
+	return strings.Join(parts, " ")
+}
+
+//formatCell writes c for a header line, counted from 1 as in "r1c2"
+func formatCell(c Cell) string {
+	return fmt.Sprintf("r%dc%d", c.Row+1, c.Column+1)
+}
+
+//parseCellName parses a cell written by formatCell, in either case
+func parseCellName(name string) (Cell, bool) {
+	name = strings.ToLower(name)
+	var row, column int
+	if _, err := fmt.Sscanf(name, "r%dc%d", &row, &column); err != nil || fmt.Sprintf("r%dc%d", row, column) != name || row < 1 || column < 1 {
+		return Cell{}, false
+	}
+	return Cell{row - 1, column - 1}, true
+}
+
+//parseCage parses a header line's cage, as written by formatCage
+func parseCage(value string) (Cage, error) {
+	fields := strings.Fields(strings.ToLower(value))
+	if len(fields) < 2 {
+		return Cage{}, fmt.Errorf("cage %q has no cells", value)
+	}
+	sum, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return Cage{}, fmt.Errorf("cage %q doesn't start with its sum", value)
+	}
+	cage := Cage{Sum: sum}
+	for _, field := range fields[1:] {
+		c, ok := parseCellName(field)
+		if !ok {
+			return Cage{}, fmt.Errorf("bad cell %q in cage %q", field, value)
+		}
+		cage.Cells = append(cage.Cells, c)
+	}
+	return cage, nil
+}
@@ -0,0 +1,168 @@
+package sudoku
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCageCandidates(t *testing.T) {
+	testCases := []struct {
+		name      string
+		used      []int
+		empty     int
+		remaining int
+		want      []int
+	}{
+		{"two cells adding up to 3", nil, 2, 3, []int{1, 2}},
+		{"two cells adding up to 17", nil, 2, 17, []int{8, 9}},
+		{"three cells adding up to 6", nil, 3, 6, []int{1, 2, 3}},
+		{"last cell", []int{8}, 1, 9, []int{9}},
+		{"last cell holding a used digit", []int{4}, 1, 4, nil},
+		{"two cells adding up to 10", []int{5}, 2, 10, []int{1, 2, 3, 4, 6, 7, 8, 9}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var used, want uint32
+			for _, n := range tc.used {
+				used |= 1 << n
+			}
+			for _, n := range tc.want {
+				want |= 1 << n
+			}
+			if got := cageCandidates(used, tc.empty, tc.remaining, 9); got != want {
+				t.Fatalf("want %b, got %b", want, got)
+			}
+		})
+	}
+}
+
+//killerPuzzle sudoku.txt with the middle rows of the Centre sub-sudoku cleared. Vertical dominoes cage the cleared rows with
+//the ones next to them, and the row between them is caged in horizontal dominoes, the last cell on its own
+func killerPuzzle(t *testing.T) (Grid, *Variant) {
+	puzzle, solution := centreRowsPuzzle(t, 15)
+	variant := &Variant{}
+	cage := func(cells ...Cell) {
+		sum := 0
+		for _, c := range cells {
+			sum += solution[c.Row][c.Column]
+		}
+		variant.Cages = append(variant.Cages, Cage{Sum: sum, Cells: cells})
+	}
+	for x := 6; x < 15; x++ {
+		cage(Cell{8, x}, Cell{9, x})
+		cage(Cell{11, x}, Cell{12, x})
+	}
+	for x := 6; x < 14; x += 2 {
+		cage(Cell{10, x}, Cell{10, x + 1})
+	}
+	cage(Cell{10, 14})
+	return puzzle, variant
+}
+
+func TestKiller(t *testing.T) {
+	puzzle, variant := killerPuzzle(t)
+	checkVariantSolves(t, puzzle, variant, func(t *testing.T, samurai *SamuraiSudoku) {
+		solution := samurai.Grid()
+		for i, cage := range variant.Cages {
+			sum := 0
+			for _, c := range cage.Cells {
+				sum += solution[c.Row][c.Column]
+			}
+			if sum != cage.Sum {
+				t.Fatalf("cage %d adds up to %d instead of %d", i+1, sum, cage.Sum)
+			}
+		}
+	})
+}
+
+func TestCageValidation(t *testing.T) {
+	grid := NewSamuraiGrid()
+	grid[0][6], grid[2][3] = 4, 4
+	variant := &Variant{Cages: []Cage{
+		{Sum: 10, Cells: []Cell{{0, 6}, {0, 7}}},
+		{Sum: 9, Cells: []Cell{{1, 2}, {2, 3}}},
+	}}
+
+	var samurai SamuraiSudoku
+	if err := samurai.SetVariant(variant); err != nil {
+		t.Fatal(err)
+	}
+	samurai.SetGrid(grid)
+	if err := samurai.Validate(); err != nil {
+		t.Fatalf("want cages that aren't full valid, got %v", err)
+	}
+	if !possible(samurai.GetSubSudoku(TopLeft), 0, 7, 6, TopLeft, &samurai) {
+		t.Fatalf("want 6 possible to make 10 with 4")
+	}
+	if possible(samurai.GetSubSudoku(TopLeft), 0, 7, 7, TopLeft, &samurai) {
+		t.Fatalf("want 7 impossible to make 10 with 4")
+	}
+
+	grid[0][7] = 7
+	var cageErr *CageError
+	if err := samurai.Validate(); !errors.As(err, &cageErr) || *cageErr != (CageError{Index: 0, Sum: 11, Want: 10}) {
+		t.Fatalf("want the first cage adding up to 11, got %v", err)
+	}
+
+	grid[0][7], grid[1][2] = 6, 4
+	var conflict *ConflictError
+	if err := samurai.Validate(); !errors.As(err, &conflict) || conflict.Unit != "cage" || conflict.Index != 1 || conflict.Num != 4 {
+		t.Fatalf("want 4 repeated in the second cage, got %v", err)
+	}
+}
+
+func TestCageErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		cages []Cage
+	}{
+		{"no cells", []Cage{{Sum: 5}}},
+		{"cell in a gap", []Cage{{Sum: 3, Cells: []Cell{{0, 9}, {0, 10}}}}},
+		{"cell in two cages", []Cage{{Sum: 3, Cells: []Cell{{0, 0}, {0, 1}}}, {Sum: 3, Cells: []Cell{{0, 1}, {0, 2}}}}},
+		{"sum too small", []Cage{{Sum: 2, Cells: []Cell{{0, 0}, {0, 1}}}}},
+		{"sum too large", []Cage{{Sum: 18, Cells: []Cell{{0, 0}, {0, 1}}}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var samurai SamuraiSudoku
+			if err := samurai.SetVariant(&Variant{Cages: tc.cages}); !errors.Is(err, ErrInvalidLayout) {
+				t.Fatalf("want ErrInvalidLayout, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseCage(t *testing.T) {
+	want := Cage{Sum: 15, Cells: []Cell{{0, 0}, {11, 20}}}
+	if got := formatCage(want); got != "15 r1c1 r12c21" {
+		t.Fatalf("want the cage written as \"15 r1c1 r12c21\", got %q", got)
+	}
+	got, err := parseCage(" 15  R1C1 r12c21 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Sum != want.Sum || len(got.Cells) != 2 || got.Cells[0] != want.Cells[0] || got.Cells[1] != want.Cells[1] {
+		t.Fatalf("want %+v, got %+v", want, got)
+	}
+	for _, value := range []string{"15", "r1c1 r1c2", "15 r1c1 r0c2", "15 r1c1x", "15 c1r1"} {
+		if _, err := parseCage(value); err == nil {
+			t.Fatalf("%q: want an error", value)
+		}
+	}
+}
+
+func TestWriteImageCages(t *testing.T) {
+	samurai := emptyTestSamurai()
+	if err := samurai.SetVariant(&Variant{Cages: []Cage{{Sum: 3, Cells: []Cell{{0, 0}, {0, 1}}}}}); err != nil {
+		t.Fatal(err)
+	}
+	img := pictureImage(t, samurai)
+
+	// the outline runs an eighth of a cell inside the cells, across the side the two cells share
+	if !hasColour(img, 0.1, 1.3, 0.15, 1.7, imageCageColour) || !hasColour(img, 0.3, 0.1, 0.7, 0.15, imageCageColour) {
+		t.Fatalf("want a cage outline around the top left cells")
+	}
+	if hasColour(img, 0.3, 0.8, 0.7, 1.2, imageCageColour) {
+		t.Fatalf("want no outline between cells of the same cage")
+	}
+}
@@ -0,0 +1,404 @@
+package sudoku
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidLayout is returned by NewLayout for sub-sudokus that can't be laid out together
+var ErrInvalidLayout = errors.New("sudoku: invalid layout")
+
+// SubGrid places a sub-sudoku of a Layout on its canvas
+type SubGrid struct {
+	Position Position
+	Row      int // Row of the sub-sudoku's top left cell on the canvas
+	Column   int // Column of the sub-sudoku's top left cell on the canvas
+}
+
+// Overlap is a rectangle of cells shared by two sub-sudokus of a Layout, in canvas coordinates
+type Overlap struct {
+	A, B          Position
+	Row, Column   int // Top left cell of the overlap
+	Rows, Columns int
+}
+
+// Layout describes a gattai puzzle: sub-sudokus placed at offsets on a canvas, sharing the cells where they overlap.
+// Sub-sudokus are 9x9 with 3x3 boxes unless the layout was made with another Box.
+// Canvas cells outside every sub-sudoku are the gaps, held as -1 in a Grid.
+// A Layout is immutable once made, and safe to share between puzzles
+type Layout struct {
+	name     string
+	box      Box
+	size     int    // Rows, columns and digits of every sub-sudoku, box.Size()
+	symbols  string // Characters digits 1 to size are written with
+	grids    []SubGrid
+	rows     int
+	columns  int
+	overlaps []Overlap
+	cells    [][][]placement // Sub-sudokus each canvas cell belongs to, nil for gaps
+	units    []unit
+}
+
+// placement A canvas cell seen from one of the sub-sudokus it belongs to
+type placement struct {
+	position Position
+	row      int
+	column   int
+}
+
+// Samurai is the classic samurai sudoku: four corner sub-sudokus sharing a box each with a Centre one, on a 21*21 canvas
+var Samurai = MustLayout("samurai",
+	SubGrid{TopLeft, 0, 0},
+	SubGrid{TopRight, 0, 12},
+	SubGrid{Centre, 6, 6},
+	SubGrid{BottomLeft, 12, 0},
+	SubGrid{BottomRight, 12, 12},
+)
+
+// Twin is the double-doku: two sub-sudokus sharing a corner box, on a 15*15 canvas
+var Twin = MustLayout("twin",
+	SubGrid{TopLeft, 0, 0},
+	SubGrid{BottomRight, 6, 6},
+)
+
+// Butterfly is four sub-sudokus sharing all but three rows or columns with their neighbours, on a 12*12 canvas
+var Butterfly = MustLayout("butterfly",
+	SubGrid{TopLeft, 0, 0},
+	SubGrid{TopRight, 0, 3},
+	SubGrid{BottomLeft, 3, 0},
+	SubGrid{BottomRight, 3, 3},
+)
+
+// Flower is a Centre sub-sudoku sharing six rows or columns with each of four petals,
+// which overlap their neighbours too, on a 15*15 canvas
+var Flower = MustLayout("flower",
+	SubGrid{Top, 0, 3},
+	SubGrid{Left, 3, 0},
+	SubGrid{Centre, 3, 3},
+	SubGrid{Right, 3, 6},
+	SubGrid{Bottom, 6, 3},
+)
+
+// Cross is a Centre sub-sudoku sharing a band of three boxes with each of four arms,
+// which share a corner box with their neighbours, on a 21*21 canvas
+var Cross = MustLayout("cross",
+	SubGrid{Top, 0, 6},
+	SubGrid{Left, 6, 0},
+	SubGrid{Centre, 6, 6},
+	SubGrid{Right, 6, 12},
+	SubGrid{Bottom, 12, 6},
+)
+
+// Sohei is four sub-sudokus in a diamond, each sharing a corner box with its two neighbours, on a 21*21 canvas
+var Sohei = MustLayout("sohei",
+	SubGrid{Top, 0, 6},
+	SubGrid{Left, 6, 0},
+	SubGrid{Right, 6, 12},
+	SubGrid{Bottom, 12, 6},
+)
+
+// Windmill is the kazaguruma: four sub-sudokus turning around a Centre one, each sharing two boxes with it, on a 21*21 canvas
+var Windmill = MustLayout("windmill",
+	SubGrid{Top, 0, 3},
+	SubGrid{Right, 3, 12},
+	SubGrid{Centre, 6, 6},
+	SubGrid{Left, 9, 0},
+	SubGrid{Bottom, 12, 9},
+)
+
+// SuperSamurai is the 13 sub-sudoku gattai: nine sub-sudokus in a square, linked by four inner ones
+// sharing a corner box with each of their four neighbours, on a 33*33 canvas
+var SuperSamurai = MustLayout("super-samurai",
+	SubGrid{TopLeft, 0, 0},
+	SubGrid{Top, 0, 12},
+	SubGrid{TopRight, 0, 24},
+	SubGrid{InnerTopLeft, 6, 6},
+	SubGrid{InnerTopRight, 6, 18},
+	SubGrid{Left, 12, 0},
+	SubGrid{Centre, 12, 12},
+	SubGrid{Right, 12, 24},
+	SubGrid{InnerBottomLeft, 18, 6},
+	SubGrid{InnerBottomRight, 18, 18},
+	SubGrid{BottomLeft, 24, 0},
+	SubGrid{Bottom, 24, 12},
+	SubGrid{BottomRight, 24, 24},
+)
+
+// Layouts holds every built-in layout
+var Layouts = []*Layout{Samurai, Twin, Butterfly, Flower, Cross, Sohei, Windmill, SuperSamurai}
+
+// layoutAliases maps the other names puzzle authors use to the built-in layouts
+var layoutAliases = map[string]*Layout{
+	"double-doku": Twin,
+	"kazaguruma":  Windmill,
+	"gattai-13":   SuperSamurai,
+}
+
+//ParseLayout returns the built-in layout named name, or known under another name such as "kazaguruma".
+//A "-N" suffix scales the layout to sub-sudokus of N digits, e.g. "samurai-16" or "twin-6"
+func ParseLayout(name string) (*Layout, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if layout, ok := builtinLayout(name); ok {
+		return layout, nil
+	}
+	if i := strings.LastIndex(name, "-"); i > 0 {
+		if size, err := strconv.Atoi(name[i+1:]); err == nil {
+			if layout, ok := builtinLayout(name[:i]); ok {
+				box, err := BoxOfSize(size)
+				if err != nil {
+					return nil, err
+				}
+				return layout.Scale(box)
+			}
+		}
+	}
+	return nil, fmt.Errorf("sudoku: unknown layout %q", name)
+}
+
+//builtinLayout returns the built-in layout named name, or known under another name
+func builtinLayout(name string) (*Layout, bool) {
+	for _, layout := range Layouts {
+		if name == layout.name {
+			return layout, true
+		}
+	}
+	layout, ok := layoutAliases[name]
+	return layout, ok
+}
+
+//NewLayout lays out the given 9x9 sub-sudokus, working out the size of the canvas and where they overlap.
+//It returns an error wrapping ErrInvalidLayout if there are no sub-sudokus, a Position is used twice or an offset is negative
+func NewLayout(name string, grids ...SubGrid) (*Layout, error) {
+	return NewBoxLayout(name, ClassicBox, grids...)
+}
+
+//NewBoxLayout is like NewLayout for sub-sudokus made of boxes shaped like box, e.g. 16x16 sub-sudokus for a 4x4 box.
+//It also returns an error wrapping ErrInvalidLayout for boxes less than 2 cells high or wide,
+//or of more than 26 cells, which have no symbols to write them with
+func NewBoxLayout(name string, box Box, grids ...SubGrid) (*Layout, error) {
+	if len(grids) == 0 {
+		return nil, fmt.Errorf("%w: %s has no sub-sudokus", ErrInvalidLayout, name)
+	}
+	if box.Rows < 2 || box.Columns < 2 || box.Size() > maxDigits {
+		return nil, fmt.Errorf("%w: %s boxes aren't supported", ErrInvalidLayout, box)
+	}
+	size := box.Size()
+	l := &Layout{name: name, box: box, size: size, symbols: symbolsFor(size), grids: append([]SubGrid(nil), grids...)}
+	seen := make(map[Position]bool)
+	for _, g := range grids {
+		if g.Row < 0 || g.Column < 0 {
+			return nil, fmt.Errorf("%w: the %s sub-sudoku of %s is off the canvas", ErrInvalidLayout, g.Position, name)
+		}
+		if seen[g.Position] {
+			return nil, fmt.Errorf("%w: %s has two %s sub-sudokus", ErrInvalidLayout, name, g.Position)
+		}
+		seen[g.Position] = true
+		if g.Row+size > l.rows {
+			l.rows = g.Row + size
+		}
+		if g.Column+size > l.columns {
+			l.columns = g.Column + size
+		}
+	}
+
+	l.cells = make([][][]placement, l.rows)
+	for y := range l.cells {
+		l.cells[y] = make([][]placement, l.columns)
+	}
+	for _, g := range grids {
+		for i := 0; i < size; i++ {
+			for j := 0; j < size; j++ {
+				l.cells[g.Row+i][g.Column+j] = append(l.cells[g.Row+i][g.Column+j], placement{g.Position, i, j})
+			}
+		}
+	}
+
+	for i, a := range grids {
+		for _, b := range grids[i+1:] {
+			top, left := a.Row, a.Column
+			if b.Row > top {
+				top = b.Row
+			}
+			if b.Column > left {
+				left = b.Column
+			}
+			bottom, right := a.Row+size, a.Column+size
+			if b.Row+size < bottom {
+				bottom = b.Row + size
+			}
+			if b.Column+size < right {
+				right = b.Column + size
+			}
+			if top < bottom && left < right {
+				l.overlaps = append(l.overlaps, Overlap{a.Position, b.Position, top, left, bottom - top, right - left})
+			}
+		}
+	}
+
+	l.units = l.makeUnits()
+	return l, nil
+}
+
+//MustLayout is like NewLayout but panics if the sub-sudokus can't be laid out, for declaring layouts as variables
+func MustLayout(name string, grids ...SubGrid) *Layout {
+	return MustBoxLayout(name, ClassicBox, grids...)
+}
+
+//MustBoxLayout is like NewBoxLayout but panics if the sub-sudokus can't be laid out
+func MustBoxLayout(name string, box Box, grids ...SubGrid) *Layout {
+	l, err := NewBoxLayout(name, box, grids...)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+//Scale returns the layout with sub-sudokus made of boxes shaped like box, named after the layout and the new size, e.g. "samurai-16".
+//Offsets are counted in boxes, and every whole sub-sudoku but one box along an axis becomes one of the new size,
+//so sub-sudokus sharing a corner box in the layout share a corner box in the scaled one.
+//It returns an error if an offset isn't a whole number of boxes
+func (l *Layout) Scale(box Box) (*Layout, error) {
+	if box == l.box {
+		return l, nil
+	}
+	if box.Rows < 2 || box.Columns < 2 {
+		return nil, fmt.Errorf("%w: %s boxes aren't supported", ErrInvalidLayout, box)
+	}
+	grids := make([]SubGrid, len(l.grids))
+	for i, g := range l.grids {
+		if g.Row%l.box.Rows != 0 || g.Column%l.box.Columns != 0 {
+			return nil, fmt.Errorf("%w: the %s sub-sudoku of %s isn't on a box boundary", ErrInvalidLayout, g.Position, l.name)
+		}
+		// a sub-sudoku is Columns boxes high and Rows boxes wide
+		grids[i] = SubGrid{
+			g.Position,
+			scaleOffset(g.Row/l.box.Rows, l.box.Columns, box.Columns) * box.Rows,
+			scaleOffset(g.Column/l.box.Columns, l.box.Rows, box.Rows) * box.Columns,
+		}
+	}
+	name := strings.TrimSuffix(l.name, fmt.Sprintf("-%d", l.size))
+	return NewBoxLayout(fmt.Sprintf("%s-%d", name, box.Size()), box, grids...)
+}
+
+//scaleOffset returns an offset of boxes along an axis sub-sudokus span from boxes to span boxes along
+func scaleOffset(boxes int, from int, to int) int {
+	return boxes/(from-1)*(to-1) + boxes%(from-1)
+}
+
+//Name returns the name the layout was made with
+func (l *Layout) Name() string {
+	return l.name
+}
+
+func (l *Layout) String() string {
+	return l.name
+}
+
+//Size returns the number of rows and columns of the layout's canvas
+func (l *Layout) Size() (int, int) {
+	return l.rows, l.columns
+}
+
+//Box returns the shape of the boxes of the layout's sub-sudokus
+func (l *Layout) Box() Box {
+	return l.box
+}
+
+//Digits returns the number of digits of the layout's sub-sudokus, which is also their number of rows and columns
+func (l *Layout) Digits() int {
+	return l.size
+}
+
+//Symbols returns the characters digits 1 to Digits are written with, in order, e.g. "123456789ABCDEFG" for 16x16 sub-sudokus
+func (l *Layout) Symbols() string {
+	return l.symbols
+}
+
+//SubGrids returns where every sub-sudoku of the layout is placed, in the order they were given
+func (l *Layout) SubGrids() []SubGrid {
+	return append([]SubGrid(nil), l.grids...)
+}
+
+//Positions returns the Position of every sub-sudoku of the layout, in the order they were given
+func (l *Layout) Positions() []Position {
+	positions := make([]Position, len(l.grids))
+	for i, g := range l.grids {
+		positions[i] = g.Position
+	}
+	return positions
+}
+
+//Offset returns the canvas row and column of the top left cell of position's sub-sudoku, false if the layout doesn't have it
+func (l *Layout) Offset(position Position) (int, int, bool) {
+	for _, g := range l.grids {
+		if g.Position == position {
+			return g.Row, g.Column, true
+		}
+	}
+	return 0, 0, false
+}
+
+//Overlaps returns every rectangle of cells shared by two sub-sudokus
+func (l *Layout) Overlaps() []Overlap {
+	return append([]Overlap(nil), l.overlaps...)
+}
+
+//Contains tells if the canvas cell at row y and column x belongs to a sub-sudoku, rather than to a gap
+func (l *Layout) Contains(y int, x int) bool {
+	return 0 <= y && y < l.rows && 0 <= x && x < l.columns && l.cells[y][x] != nil
+}
+
+//NewGrid returns an empty grid shaped like the layout, with the gaps set to -1
+func (l *Layout) NewGrid() Grid {
+	grid := make(Grid, l.rows)
+	for y := range grid {
+		grid[y] = make([]int, l.columns)
+		for x := range grid[y] {
+			if l.cells[y][x] == nil {
+				grid[y][x] = -1
+			}
+		}
+	}
+	return grid
+}
+
+//placements returns every sub-sudoku the canvas cell at row y and column x belongs to, with its row and column in them
+func (l *Layout) placements(y int, x int) []placement {
+	if !l.Contains(y, x) {
+		return nil
+	}
+	return l.cells[y][x]
+}
+
+//locate returns the first sub-sudoku the canvas cell at row y and column x is in, with its row and column in it
+func (l *Layout) locate(y int, x int) (Position, int, int, bool) {
+	if p := l.placements(y, x); p != nil {
+		return p[0].position, p[0].row, p[0].column, true
+	}
+	return 0, 0, 0, false
+}
+
+//makeUnits returns every row, column and box of every sub-sudoku.
+//Boxes on the overlaps are returned once per sub-sudoku they belong to
+func (l *Layout) makeUnits() []unit {
+	var units []unit
+	for _, g := range l.grids {
+		y0, x0 := g.Row, g.Column
+		// box i is the (i % Rows)-th across of the (i / Rows)-th band of boxes, as there are Rows boxes across
+		rows, columns := l.box.Rows, l.box.Columns
+		for i := 0; i < l.size; i++ {
+			row := unit{position: g.Position, kind: "row", index: i}
+			column := unit{position: g.Position, kind: "column", index: i}
+			box := unit{position: g.Position, kind: "box", index: i}
+			for j := 0; j < l.size; j++ {
+				row.cells = append(row.cells, Cell{y0 + i, x0 + j})
+				column.cells = append(column.cells, Cell{y0 + j, x0 + i})
+				box.cells = append(box.cells, Cell{y0 + (i/rows)*rows + j/columns, x0 + (i%rows)*columns + j%columns})
+			}
+			units = append(units, row, column, box)
+		}
+	}
+	return units
+}
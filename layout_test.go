@@ -0,0 +1,293 @@
+package sudoku
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// twin Two sub-sudokus sharing their corner box, used to check nothing depends on the samurai geometry
+var twin = MustLayout("test twin", SubGrid{TopLeft, 0, 0}, SubGrid{BottomRight, 6, 6})
+
+func TestSamuraiLayout(t *testing.T) {
+	if rows, columns := Samurai.Size(); rows != 21 || columns != 21 {
+		t.Fatalf("want a 21*21 canvas, got %d*%d", rows, columns)
+	}
+	if got := Samurai.Positions(); !reflect.DeepEqual(got, []Position{TopLeft, TopRight, Centre, BottomLeft, BottomRight}) {
+		t.Fatalf("unexpected positions %v", got)
+	}
+
+	want := []Overlap{
+		{TopLeft, Centre, 6, 6, 3, 3},
+		{TopRight, Centre, 6, 12, 3, 3},
+		{Centre, BottomLeft, 12, 6, 3, 3},
+		{Centre, BottomRight, 12, 12, 3, 3},
+	}
+	if got := Samurai.Overlaps(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want overlaps %+v, got %+v", want, got)
+	}
+
+	grid := SamuraiGridFromFile("sudoku.txt")
+	for y, row := range grid {
+		for x, num := range row {
+			if Samurai.Contains(y, x) != (num != -1) {
+				t.Fatalf("cell (%d, %d) holds %d, Contains says %v", y, x, num, Samurai.Contains(y, x))
+			}
+		}
+	}
+}
+
+func TestNewLayoutErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		grids []SubGrid
+	}{
+		{"no sub-sudokus", nil},
+		{"negative offset", []SubGrid{{TopLeft, -1, 0}}},
+		{"position used twice", []SubGrid{{TopLeft, 0, 0}, {TopLeft, 6, 6}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewLayout(tc.name, tc.grids...); !errors.Is(err, ErrInvalidLayout) {
+				t.Fatalf("want ErrInvalidLayout, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTwinLayout(t *testing.T) {
+	if rows, columns := twin.Size(); rows != 15 || columns != 15 {
+		t.Fatalf("want a 15*15 canvas, got %d*%d", rows, columns)
+	}
+	if got, want := twin.Overlaps(), []Overlap{{TopLeft, BottomRight, 6, 6, 3, 3}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("want overlaps %+v, got %+v", want, got)
+	}
+
+	puzzle, err := GenerateSamuraiSudokuContext(context.Background(), GenerateOptions{Seed: 3, MinClues: 100, Layout: twin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateLayout(puzzle, twin); err != nil {
+		t.Fatalf("invalid puzzle: %v", err)
+	}
+	if err := Validate(puzzle); !errors.Is(err, ErrInvalidGrid) {
+		t.Fatalf("want a twin grid not to pass as samurai, got %v", err)
+	}
+
+	for name, solve := range Solvers {
+		t.Run(name, func(t *testing.T) {
+			var samurai SamuraiSudoku
+			samurai.SetLayout(twin)
+			samurai.SetGrid(copyGrid(puzzle))
+			if count, err := samurai.CountSolutions(context.Background(), 2); err != nil || count != 1 {
+				t.Fatalf("want a unique solution, got %d, %v", count, err)
+			}
+
+			solution, err := solve(context.Background(), &samurai)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !solution.isSolved() {
+				t.Fatalf("solution has empty cells:\n%v", solution)
+			}
+			if err := samurai.Validate(); err != nil {
+				t.Fatalf("invalid solution: %v", err)
+			}
+			// the shared box is seen the same from both sub-sudokus
+			if got, want := samurai.GetSubSudoku(TopLeft)[6][6], samurai.GetSubSudoku(BottomRight)[0][0]; got != want {
+				t.Fatalf("overlap differs between sub-sudokus: %d and %d", got, want)
+			}
+		})
+	}
+}
+
+func TestLayoutCatalogue(t *testing.T) {
+	testCases := []struct {
+		layout        *Layout
+		rows, columns int
+		overlaps      int
+	}{
+		{Twin, 15, 15, 1},
+		{Butterfly, 12, 12, 6},
+		{Flower, 15, 15, 10},
+		{Cross, 21, 21, 8},
+		{Sohei, 21, 21, 4},
+		{Windmill, 21, 21, 4},
+		{SuperSamurai, 33, 33, 16},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.layout.Name(), func(t *testing.T) {
+			if rows, columns := tc.layout.Size(); rows != tc.rows || columns != tc.columns {
+				t.Fatalf("want a %d*%d canvas, got %d*%d", tc.rows, tc.columns, rows, columns)
+			}
+			if got := len(tc.layout.Overlaps()); got != tc.overlaps {
+				t.Fatalf("want %d overlaps, got %d", tc.overlaps, got)
+			}
+			if layout, err := ParseLayout(tc.layout.Name()); err != nil || layout != tc.layout {
+				t.Fatalf("want %s parsed back, got %v, %v", tc.layout, layout, err)
+			}
+
+			// testdata holds a puzzle per layout, made by GenerateSamuraiSudoku with seed 2026
+			f, err := os.Open("testdata/" + tc.layout.Name() + ".txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			grid, err := ReadLayoutGrid(f, tc.layout, TextFormat)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var samurai SamuraiSudoku
+			samurai.SetLayout(tc.layout)
+			samurai.SetGrid(grid)
+			if count, err := samurai.CountSolutions(context.Background(), 2); err != nil || count != 1 {
+				t.Fatalf("want a unique solution, got %d, %v", count, err)
+			}
+			solution, err := GlobalSolveSamuraiSudokuContext(context.Background(), &samurai)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !solution.isSolved() {
+				t.Fatalf("solution has empty cells:\n%v", solution)
+			}
+			if err := samurai.Validate(); err != nil {
+				t.Fatalf("invalid solution: %v", err)
+			}
+		})
+	}
+}
+
+func TestScaleLayout(t *testing.T) {
+	testCases := []struct {
+		name          string
+		box           Box
+		rows, columns int
+		overlap       Overlap
+	}{
+		{"samurai-4", Box{2, 2}, 8, 8, Overlap{TopLeft, Centre, 2, 2, 2, 2}},
+		{"samurai-6", Box{2, 3}, 14, 12, Overlap{TopLeft, Centre, 4, 3, 2, 3}},
+		{"samurai-16", Box{4, 4}, 40, 40, Overlap{TopLeft, Centre, 12, 12, 4, 4}},
+		{"samurai-25", Box{5, 5}, 65, 65, Overlap{TopLeft, Centre, 20, 20, 5, 5}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			layout, err := ParseLayout(tc.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if layout.Name() != tc.name || layout.Box() != tc.box || layout.Digits() != tc.box.Size() {
+				t.Fatalf("want %s with %s boxes, got %s with %s boxes", tc.name, tc.box, layout, layout.Box())
+			}
+			if rows, columns := layout.Size(); rows != tc.rows || columns != tc.columns {
+				t.Fatalf("want a %d*%d canvas, got %d*%d", tc.rows, tc.columns, rows, columns)
+			}
+			// every corner sub-sudoku still shares a single box with the Centre one
+			if got := layout.Overlaps(); len(got) != 4 || got[0] != tc.overlap {
+				t.Fatalf("want overlaps like %+v, got %+v", tc.overlap, got)
+			}
+		})
+	}
+
+	if layout, err := ParseLayout("samurai-9"); err != nil || layout != Samurai {
+		t.Fatalf("want samurai-9 to be samurai, got %v, %v", layout, err)
+	}
+	for _, name := range []string{"samurai-7", "samurai-36", "pinwheel-16"} {
+		if _, err := ParseLayout(name); err == nil {
+			t.Fatalf("%s: want an error", name)
+		}
+	}
+	if _, err := NewBoxLayout("rows", Box{1, 4}, SubGrid{TopLeft, 0, 0}); !errors.Is(err, ErrInvalidLayout) {
+		t.Fatalf("want ErrInvalidLayout for boxes a row high, got %v", err)
+	}
+}
+
+func TestBoxLayouts(t *testing.T) {
+	testCases := []struct {
+		name     string
+		minClues int
+		solvers  []string
+	}{
+		{"twin-4", 0, []string{"global"}},
+		{"samurai-6", 0, []string{"global"}},
+		// the solvers working a sub-sudoku at a time need more clues to get through
+		{"twin-4", 20, []string{"sequential", "concurrent", "double"}},
+		{"samurai-6", 110, []string{"sequential", "concurrent", "double"}},
+		// removing every clue that can be takes a while on large sub-sudokus
+		{"samurai-16", 600, []string{"global"}},
+		{"twin-25", 750, []string{"global"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			layout, err := ParseLayout(tc.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			puzzle, err := GenerateSamuraiSudokuContext(context.Background(), GenerateOptions{Seed: 2026, MinClues: tc.minClues, Layout: layout})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ValidateLayout(puzzle, layout); err != nil {
+				t.Fatalf("invalid puzzle: %v", err)
+			}
+
+			for _, name := range tc.solvers {
+				t.Run(name, func(t *testing.T) {
+					var samurai SamuraiSudoku
+					samurai.SetLayout(layout)
+					samurai.SetGrid(copyGrid(puzzle))
+					if count, err := samurai.CountSolutions(context.Background(), 2); err != nil || count != 1 {
+						t.Fatalf("want a unique solution, got %d, %v", count, err)
+					}
+					solution, err := Solvers[name](context.Background(), &samurai)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !solution.isSolved() {
+						t.Fatalf("solution has empty cells:\n%s", layout.Format(solution))
+					}
+					if err := samurai.Validate(); err != nil {
+						t.Fatalf("invalid solution: %v", err)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestParseLayout(t *testing.T) {
+	testCases := []struct {
+		name string
+		want *Layout
+	}{
+		{"samurai", Samurai},
+		{" Butterfly ", Butterfly},
+		{"kazaguruma", Windmill},
+		{"double-doku", Twin},
+		{"gattai-13", SuperSamurai},
+	}
+	for _, tc := range testCases {
+		if got, err := ParseLayout(tc.name); err != nil || got != tc.want {
+			t.Fatalf("%q: want %s, got %v, %v", tc.name, tc.want, got, err)
+		}
+	}
+	if _, err := ParseLayout("pinwheel"); err == nil {
+		t.Fatalf("want an error for an unknown layout")
+	}
+}
+
+func TestParsePosition(t *testing.T) {
+	for p := TopLeft; p <= InnerBottomRight; p++ {
+		if got, err := ParsePosition(strings.ReplaceAll(p.String(), " ", "-")); err != nil || got != p {
+			t.Fatalf("want %s parsed back, got %v, %v", p, got, err)
+		}
+	}
+	if _, err := ParsePosition("middle"); err == nil {
+		t.Fatalf("want an error for an unknown position")
+	}
+}
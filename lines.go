@@ -0,0 +1,280 @@
+package sudoku
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Constraint is a rule on the digits of some cells of the whole grid, which the solvers follow alongside the rules of the
+// sub-sudokus and Validate checks. Its cells may belong to different sub-sudokus, e.g. run across the overlap of two of them.
+// Thermometers, arrows and German whispers lines are constraints, other rules can be added to a Variant by implementing it
+type Constraint interface {
+	// Cells returns the cells of the whole grid the rule is about
+	Cells() []Cell
+	// Check returns an error if the rule can't be followed in sub-sudokus holding digits from 1 to digits
+	Check(digits int) error
+	// Possible tells if c, one of Cells, can hold n given the digits already in the other cells, c's own digit in grid
+	// being ignored, in sub-sudokus holding digits from 1 to digits
+	Possible(grid Grid, c Cell, n int, digits int) bool
+}
+
+// ConstraintError is returned by Validate for a cell whose digit breaks a constraint given the other filled cells
+type ConstraintError struct {
+	Index      int // Index of the constraint in the Variant
+	Constraint Constraint
+	Cell       Cell
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("sudoku: the digit of %s breaks constraint %d, %v", formatCell(e.Cell), e.Index+1, e.Constraint)
+}
+
+// Thermo is a thermometer line, whose digits must strictly increase from its bulb, its first cell
+type Thermo []Cell
+
+// Arrow is an arrow line, whose first cell is its circle holding the sum of the digits along the rest of its cells.
+// Digits may repeat along an arrow if the sub-sudokus allow it
+type Arrow []Cell
+
+// Whispers is a German whispers line, whose neighbouring digits must differ by at least 5
+type Whispers []Cell
+
+// whispersGap is the smallest difference between neighbouring digits of a German whispers line
+const whispersGap = 5
+
+func (t Thermo) Cells() []Cell   { return t }
+func (a Arrow) Cells() []Cell    { return a }
+func (w Whispers) Cells() []Cell { return w }
+
+func (t Thermo) String() string   { return "thermo " + formatCells(t) }
+func (a Arrow) String() string    { return "arrow " + formatCells(a) }
+func (w Whispers) String() string { return "whispers " + formatCells(w) }
+
+//Check returns an error if the thermometer has fewer than 2 cells, more cells than there are digits, or isn't a line
+func (t Thermo) Check(digits int) error {
+	if len(t) < 2 || len(t) > digits {
+		return fmt.Errorf("thermometer of %d cells isn't between 2 and %d cells long", len(t), digits)
+	}
+	return checkLine(t)
+}
+
+//Check returns an error if the arrow has no cell besides its circle, or isn't a line
+func (a Arrow) Check(digits int) error {
+	if len(a) < 2 {
+		return fmt.Errorf("arrow has no cell besides its circle")
+	}
+	return checkLine(a)
+}
+
+//Check returns an error if the line has fewer than 2 cells, isn't a line, or no two digits differ by at least 5
+func (w Whispers) Check(digits int) error {
+	if len(w) < 2 {
+		return fmt.Errorf("whispers line of %d cells is too short", len(w))
+	}
+	if digits <= whispersGap {
+		return fmt.Errorf("no two digits from 1 to %d differ by at least %d for a whispers line", digits, whispersGap)
+	}
+	return checkLine(w)
+}
+
+//Possible tells if c can hold n, leaving room for the digits below it towards the bulb and above it towards the tip
+func (t Thermo) Possible(grid Grid, c Cell, n int, digits int) bool {
+	i := indexOf(t, c)
+	if i < 0 {
+		return true
+	}
+	if n < i+1 || n > digits-(len(t)-1-i) {
+		return false
+	}
+	for j, d := range t {
+		v := grid[d.Row][d.Column]
+		if j == i || v <= 0 {
+			continue
+		}
+		if j < i && n-v < i-j || j > i && v-n < j-i {
+			return false
+		}
+	}
+	return true
+}
+
+//Possible tells if c can hold n, leaving the circle between the smallest and largest sums the shaft can still add up to
+func (a Arrow) Possible(grid Grid, c Cell, n int, digits int) bool {
+	if indexOf(a, c) < 0 {
+		return true
+	}
+	value := func(d Cell) int {
+		if d == c {
+			return n
+		}
+		return grid[d.Row][d.Column]
+	}
+	sum, empty := 0, 0
+	for _, d := range a[1:] {
+		if v := value(d); v > 0 {
+			sum += v
+		} else {
+			empty++
+		}
+	}
+	if circle := value(a[0]); circle > 0 {
+		return sum+empty <= circle && circle <= sum+empty*digits
+	}
+	return sum+empty <= digits
+}
+
+//Possible tells if c can hold n, differing by at least 5 from its neighbours along the line, or from some digit if they're empty
+func (w Whispers) Possible(grid Grid, c Cell, n int, digits int) bool {
+	i := indexOf(w, c)
+	if i < 0 {
+		return true
+	}
+	for _, j := range []int{i - 1, i + 1} {
+		if j < 0 || j >= len(w) {
+			continue
+		}
+		v := grid[w[j].Row][w[j].Column]
+		if v > 0 && (v-n < whispersGap && n-v < whispersGap) {
+			return false
+		}
+		if v <= 0 && n-whispersGap < 1 && n+whispersGap > digits {
+			return false
+		}
+	}
+	return true
+}
+
+func indexOf(cells []Cell, c Cell) int {
+	for i, d := range cells {
+		if d == c {
+			return i
+		}
+	}
+	return -1
+}
+
+//checkLine returns an error if consecutive cells don't touch by a side or a corner
+func checkLine(cells []Cell) error {
+	for i := 1; i < len(cells); i++ {
+		a, b := cells[i-1], cells[i]
+		dy, dx := a.Row-b.Row, a.Column-b.Column
+		if dy < -1 || dy > 1 || dx < -1 || dx > 1 {
+			return fmt.Errorf("%s and %s are next to each other on a line but don't touch", formatCell(a), formatCell(b))
+		}
+	}
+	return nil
+}
+
+//checkConstraints returns an error wrapping ErrInvalidLayout if a constraint has no cell, a cell outside layout's sub-sudokus
+//or the same cell twice, or doesn't pass its own Check
+func checkConstraints(constraints []Constraint, layout *Layout) error {
+	for i, constraint := range constraints {
+		cells := constraint.Cells()
+		if len(cells) == 0 {
+			return fmt.Errorf("%w: constraint %d has no cells", ErrInvalidLayout, i+1)
+		}
+		seen := make(map[Cell]bool)
+		for _, c := range cells {
+			if !layout.Contains(c.Row, c.Column) {
+				return fmt.Errorf("%w: cell (%d, %d) of constraint %d isn't in a sub-sudoku of %s", ErrInvalidLayout, c.Row+1, c.Column+1, i+1, layout)
+			}
+			if seen[c] {
+				return fmt.Errorf("%w: cell (%d, %d) is in constraint %d twice", ErrInvalidLayout, c.Row+1, c.Column+1, i+1)
+			}
+			seen[c] = true
+		}
+		if err := constraint.Check(layout.size); err != nil {
+			return fmt.Errorf("%w: constraint %d: %v", ErrInvalidLayout, i+1, err)
+		}
+	}
+	return nil
+}
+
+//constraintIndex returns the constraints every cell is in
+func constraintIndex(constraints []Constraint) map[Cell][]int {
+	index := make(map[Cell][]int)
+	for i, constraint := range constraints {
+		for _, c := range constraint.Cells() {
+			index[c] = append(index[c], i)
+		}
+	}
+	return index
+}
+
+//validateConstraints checks that the filled cells of grid follow constraints, in sub-sudokus holding digits from 1 to digits.
+//It returns a *ConstraintError for the first cell that doesn't
+func validateConstraints(grid Grid, constraints []Constraint, digits int) error {
+	for i, constraint := range constraints {
+		for _, c := range constraint.Cells() {
+			if num := grid[c.Row][c.Column]; num > 0 && !constraint.Possible(grid, c, num, digits) {
+				return &ConstraintError{Index: i, Constraint: constraint, Cell: c}
+			}
+		}
+	}
+	return nil
+}
+
+//possibleConstraints checks if the canvas cell at row y and column x of grid can be filled with n given the constraints it's in,
+//listed by cell in index
+func possibleConstraints(grid Grid, y int, x int, n int, constraints []Constraint, index map[Cell][]int, digits int) bool {
+	c := Cell{y, x}
+	for _, i := range index[c] {
+		if !constraints[i].Possible(grid, c, n, digits) {
+			return false
+		}
+	}
+	return true
+}
+
+//formatCells writes cells counted from 1 for a header line, e.g. "r1c1 r1c2"
+func formatCells(cells []Cell) string {
+	names := make([]string, len(cells))
+	for i, c := range cells {
+		names[i] = formatCell(c)
+	}
+	return strings.Join(names, " ")
+}
+
+//parseCells parses a header line's cells, as written by formatCells
+func parseCells(value string) ([]Cell, error) {
+	var cells []Cell
+	for _, field := range strings.Fields(value) {
+		c, ok := parseCellName(field)
+		if !ok {
+			return nil, fmt.Errorf("bad cell %q in %q", field, value)
+		}
+		cells = append(cells, c)
+	}
+	return cells, nil
+}
+
+//formatLineConstraint writes a line constraint for a header line, e.g. "thermo: r1c1 r1c2".
+//It returns false for constraints that aren't thermometers, arrows or whispers lines, which puzzle files can't hold
+func formatLineConstraint(constraint Constraint) (string, bool) {
+	switch line := constraint.(type) {
+	case Thermo:
+		return "thermo: " + formatCells(line), true
+	case Arrow:
+		return "arrow: " + formatCells(line), true
+	case Whispers:
+		return "whispers: " + formatCells(line), true
+	}
+	return "", false
+}
+
+//parseLineConstraint parses the value of a header line for the line constraint named key, thermo, arrow or whispers
+func parseLineConstraint(key string, value string) (Constraint, error) {
+	cells, err := parseCells(value)
+	if err != nil {
+		return nil, err
+	}
+	switch key {
+	case "thermo":
+		return Thermo(cells), nil
+	case "arrow":
+		return Arrow(cells), nil
+	case "whispers":
+		return Whispers(cells), nil
+	}
+	return nil, fmt.Errorf("unknown line %q", key)
+}
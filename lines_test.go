@@ -0,0 +1,201 @@
+package sudoku
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLinePossible(t *testing.T) {
+	grid := NewSamuraiGrid()
+	grid[0][0], grid[0][3] = 3, 7
+	thermo := Thermo{{0, 0}, {0, 1}, {0, 2}, {0, 3}, {0, 4}}
+	arrow := Arrow{{0, 3}, {0, 2}, {0, 1}}
+	whispers := Whispers{{0, 0}, {0, 1}, {0, 2}}
+
+	testCases := []struct {
+		name       string
+		constraint Constraint
+		cell       Cell
+		want       []int
+	}{
+		{"thermo between its bulb and a filled cell", thermo, Cell{0, 1}, []int{4, 5}},
+		{"thermo next to its tip", thermo, Cell{0, 2}, []int{5, 6}},
+		{"thermo tip", thermo, Cell{0, 4}, []int{8, 9}},
+		{"thermo bulb", thermo, Cell{0, 0}, []int{1, 2, 3, 4}},
+		{"arrow shaft", arrow, Cell{0, 2}, []int{1, 2, 3, 4, 5, 6}},
+		{"arrow circle", arrow, Cell{0, 3}, []int{2, 3, 4, 5, 6, 7, 8, 9}},
+		{"whispers next to 3", whispers, Cell{0, 1}, []int{8, 9}},
+		{"whispers between empty cells", whispers, Cell{0, 2}, []int{1, 2, 3, 4, 6, 7, 8, 9}},
+		{"cell off the line", whispers, Cell{1, 1}, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []int
+			for n := 1; n <= 9; n++ {
+				if tc.constraint.Possible(grid, tc.cell, n, 9) {
+					got = append(got, n)
+				}
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+// testLines Lines along sudoku.txt's solution, running from the top left sub-sudoku across its overlap with the centre one
+var testLines = []Constraint{
+	Thermo{{5, 6}, {6, 7}, {7, 8}, {8, 8}},
+	Arrow{{9, 7}, {8, 6}, {8, 7}},
+	Whispers{{6, 6}, {7, 6}, {7, 7}, {6, 8}, {5, 7}},
+}
+
+func TestLines(t *testing.T) {
+	// the lines' cells are left for the lines to fill
+	puzzle := SamuraiGridFromFile("sudoku.txt")
+	for _, line := range testLines {
+		for _, c := range line.Cells() {
+			puzzle[c.Row][c.Column] = 0
+		}
+	}
+
+	checkVariantSolves(t, puzzle, &Variant{Constraints: testLines}, func(t *testing.T, samurai *SamuraiSudoku) {
+		solution := samurai.Grid()
+		if solution[9][7] != solution[8][6]+solution[8][7] {
+			t.Fatalf("want the arrow's circle to hold the sum of its shaft, got %d, %d and %d", solution[9][7], solution[8][6], solution[8][7])
+		}
+	})
+}
+
+func TestConstraintValidation(t *testing.T) {
+	grid := NewSamuraiGrid()
+	grid[0][0], grid[0][2] = 5, 6
+	var samurai SamuraiSudoku
+	if err := samurai.SetVariant(&Variant{Constraints: []Constraint{Thermo{{0, 0}, {0, 1}, {0, 2}}}}); err != nil {
+		t.Fatal(err)
+	}
+	samurai.SetGrid(grid)
+
+	var constraintErr *ConstraintError
+	if err := samurai.Validate(); !errors.As(err, &constraintErr) || constraintErr.Index != 0 || constraintErr.Cell != (Cell{0, 0}) {
+		t.Fatalf("want 5 and 6 two cells apart to break the thermometer, got %v", err)
+	}
+	if n, err := samurai.CountSolutions(context.Background(), 1); !errors.Is(err, ErrUnsolvable) || n != 0 {
+		t.Fatalf("want no solution and %v, got %d, %v", ErrUnsolvable, n, err)
+	}
+
+	grid[0][2] = 7
+	if err := samurai.Validate(); err != nil {
+		t.Fatalf("want 5 and 7 two cells apart to follow the thermometer, got %v", err)
+	}
+	if !possible(samurai.GetSubSudoku(TopLeft), 0, 1, 6, TopLeft, &samurai) {
+		t.Fatalf("want 6 possible between 5 and 7")
+	}
+	if possible(samurai.GetSubSudoku(TopLeft), 0, 1, 4, TopLeft, &samurai) {
+		t.Fatalf("want 4 impossible between 5 and 7")
+	}
+}
+
+// sameDigit A constraint outside the package's own, whose cells must all hold the same digit
+type sameDigit []Cell
+
+func (s sameDigit) Cells() []Cell          { return s }
+func (s sameDigit) Check(digits int) error { return nil }
+func (s sameDigit) Possible(grid Grid, c Cell, n int, digits int) bool {
+	for _, d := range s {
+		if v := grid[d.Row][d.Column]; d != c && v > 0 && v != n {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCustomConstraint(t *testing.T) {
+	// the top left sub-sudoku's (5, 4) and the centre's (0, 0) hold 7 in sudoku.txt's solution, (0, 1) holds 6
+	for _, tc := range []struct {
+		cells []Cell
+		want  int
+	}{
+		{[]Cell{{5, 4}, {6, 6}}, 1},
+		{[]Cell{{0, 1}, {6, 6}}, 0},
+	} {
+		var samurai SamuraiSudoku
+		if err := samurai.SetVariant(&Variant{Constraints: []Constraint{sameDigit(tc.cells)}}); err != nil {
+			t.Fatal(err)
+		}
+		samurai.SetGrid(SamuraiGridFromFile("sudoku.txt"))
+		if n, err := samurai.CountSolutions(context.Background(), 2); err != nil || n != tc.want {
+			t.Fatalf("%v: want %d solutions, got %d, %v", tc.cells, tc.want, n, err)
+		}
+	}
+}
+
+func TestConstraintErrors(t *testing.T) {
+	twin4, err := ParseLayout("twin-4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		name       string
+		layout     *Layout
+		constraint Constraint
+	}{
+		{"no cells", Samurai, sameDigit(nil)},
+		{"cell in a gap", Samurai, Thermo{{0, 8}, {0, 9}}},
+		{"same cell twice", Samurai, Arrow{{0, 0}, {0, 1}, {0, 0}}},
+		{"cells not touching", Samurai, Whispers{{0, 0}, {0, 2}}},
+		{"thermometer longer than the digits", twin4, Thermo{{0, 0}, {0, 1}, {1, 1}, {1, 0}, {2, 0}}},
+		{"arrow without a shaft", Samurai, Arrow{{0, 0}}},
+		{"whispers with 4 digits", twin4, Whispers{{0, 0}, {0, 1}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var samurai SamuraiSudoku
+			samurai.SetLayout(tc.layout)
+			if err := samurai.SetVariant(&Variant{Constraints: []Constraint{tc.constraint}}); !errors.Is(err, ErrInvalidLayout) {
+				t.Fatalf("want ErrInvalidLayout, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseLineConstraint(t *testing.T) {
+	for _, want := range testLines {
+		line, ok := formatLineConstraint(want)
+		if !ok {
+			t.Fatalf("want %v written", want)
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if got, err := parseLineConstraint(parts[0], parts[1]); err != nil || !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v read back from %q, got %v, %v", want, line, got, err)
+		}
+	}
+	if _, ok := formatLineConstraint(sameDigit{{0, 0}}); ok {
+		t.Fatalf("want constraints other than lines left out of headers")
+	}
+	if _, err := parseLineConstraint("thermo", "r1c1 x"); err == nil {
+		t.Fatalf("want an error for a bad cell")
+	}
+}
+
+func TestWriteImageLines(t *testing.T) {
+	samurai := emptyTestSamurai()
+	if err := samurai.SetVariant(&Variant{Constraints: testLines}); err != nil {
+		t.Fatal(err)
+	}
+	img := pictureImage(t, samurai)
+
+	// the middle of the thermometer's bulb, the arrow's circle and a cell of the whispers line
+	if !hasColour(img, 5.5, 6.5, 5.5, 6.5, imageThermoColour) {
+		t.Fatalf("want a thermometer bulb")
+	}
+	if !hasColour(img, 9.5, 7.08, 9.5, 7.12, imageMarkColour) || hasColour(img, 9.3, 7.3, 9.7, 7.7, imageMarkColour) {
+		t.Fatalf("want an arrow circle")
+	}
+	if !hasColour(img, 7.5, 6.5, 7.5, 6.5, imageWhispersColour) {
+		t.Fatalf("want a whispers line")
+	}
+}
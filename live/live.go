@@ -0,0 +1,239 @@
+// Package live serves a web page showing a samurai sudoku being solved, move by move, as it happens.
+//
+// The page at / opens a Server-Sent Events stream at /events, which starts a new solve of the puzzle
+// for every connection and sends what the solver does as it does it:
+//
+//	event: start  data: {"grid": [[...]], "symbols": "123..."} the puzzle, before anything is solved, and the characters digits are written with
+//	event: reset  data: {"grid": [[...]]}                      the grid was reset for a new solving attempt
+//	event: move   data: {"thread": 31, "position": 3, ...}     a value was placed, or taken back when value is 0
+//	event: done   data: {"grid": [[...]], "moves": 1234}       the puzzle was solved
+//	event: error  data: {"message": "..."}                     the solver gave up
+//
+// The solver and a pause after every move are picked with the query parameters of /events,
+// e.g. /events?solver=double&delay=5ms. Closing the page stops the solve.
+package live
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	sudoku "github.com/alielbashir/samurai-sudoku-go"
+)
+
+//go:embed index.html
+var index []byte
+
+// Options configures the handler returned by New
+type Options struct {
+	Solver  string          // Solver used when the page doesn't ask for one, "concurrent" by default
+	Delay   time.Duration   // Pause after every move when the page doesn't ask for one, so the solve can be followed
+	Timeout time.Duration   // Longest time spent on a solve, 0 for no limit
+	Layout  *sudoku.Layout  // Layout of the puzzle, samurai if nil
+	Variant *sudoku.Variant // Rules the puzzle adds to those of its sub-sudokus, none if nil
+}
+
+const defaultSolver = "concurrent"
+
+type handler struct {
+	grid    sudoku.Grid
+	options Options
+	mux     *http.ServeMux
+}
+
+//New returns a handler serving the page and the event stream of grid being solved
+func New(grid sudoku.Grid, options Options) http.Handler {
+	if options.Solver == "" {
+		options.Solver = defaultSolver
+	}
+	h := &handler{
+		grid:    copyGrid(grid),
+		options: options,
+		mux:     http.NewServeMux(),
+	}
+	h.mux.HandleFunc("/", h.page)
+	h.mux.HandleFunc("/events", h.events)
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *handler) page(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(index)
+}
+
+// event A Server-Sent Event, its data is sent as JSON
+type event struct {
+	name string
+	data interface{}
+}
+
+type gridEvent struct {
+	Grid    sudoku.Grid `json:"grid"`
+	Symbols string      `json:"symbols,omitempty"`
+	Moves   int         `json:"moves,omitempty"`
+}
+
+type moveEvent struct {
+	Thread   int             `json:"thread"`
+	Position sudoku.Position `json:"position"`
+	Row      int             `json:"row"`    // Row within the sub-sudoku
+	Column   int             `json:"column"` // Column within the sub-sudoku
+	Y        int             `json:"y"`      // Row within the whole grid
+	X        int             `json:"x"`      // Column within the whole grid
+	Value    int             `json:"value"`
+}
+
+type errorEvent struct {
+	Message string `json:"message"`
+}
+
+//events solves the puzzle and streams every move made until it is solved or the client goes away
+func (h *handler) events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming isn't supported", http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+	name := query.Get("solver")
+	if name == "" {
+		name = h.options.Solver
+	}
+	solve, ok := sudoku.Solvers[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown solver %q", name), http.StatusBadRequest)
+		return
+	}
+	delay := h.options.Delay
+	if d := query.Get("delay"); d != "" {
+		var err error
+		if delay, err = time.ParseDuration(d); err != nil || delay < 0 {
+			http.Error(w, fmt.Sprintf("bad delay %q", d), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx := r.Context()
+	if h.options.Timeout > 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, h.options.Timeout)
+		defer cancel()
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	send := func(e event) error {
+		data, err := json.Marshal(e.data)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.name, data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+
+	var samurai sudoku.SamuraiSudoku
+	samurai.SetLayout(h.options.Layout)
+	if err := samurai.SetVariant(h.options.Variant); err != nil {
+		send(event{"error", errorEvent{Message: err.Error()}})
+		return
+	}
+	grid := copyGrid(h.grid)
+	if err := send(event{"start", gridEvent{Grid: grid, Symbols: samurai.Layout().Symbols()}}); err != nil {
+		return
+	}
+
+	samurai.SetGrid(grid)
+	o := &observer{ctx: ctx, delay: delay, events: make(chan event)}
+	stop := samurai.Observe(o)
+	defer stop()
+
+	type result struct {
+		grid sudoku.Grid
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		grid, err := solve(ctx, &samurai)
+		done <- result{grid, err}
+	}()
+
+	for {
+		select {
+		case e := <-o.events:
+			// once the client is gone sending fails, and the solver gives up as ctx is done
+			send(e)
+		case res := <-done:
+			if res.err != nil {
+				send(event{"error", errorEvent{res.err.Error()}})
+				return
+			}
+			send(event{"done", gridEvent{Grid: res.grid, Moves: o.moves}})
+			return
+		}
+	}
+}
+
+// observer passes the moves of a solve on to the event stream, one at a time.
+// Its methods are called with the sudoku locked, so they never run at the same time
+type observer struct {
+	ctx    context.Context
+	delay  time.Duration
+	events chan event
+	moves  int
+}
+
+func (o *observer) Moved(move sudoku.Move) {
+	y, x := move.GlobalCell()
+	o.moves++
+	o.emit(event{"move", moveEvent{
+		Thread:   move.Thread(),
+		Position: move.Position(),
+		Row:      move.Row(),
+		Column:   move.Column(),
+		Y:        y,
+		X:        x,
+		Value:    move.Num(),
+	}})
+	if o.delay > 0 {
+		select {
+		case <-time.After(o.delay):
+		case <-o.ctx.Done():
+		}
+	}
+}
+
+func (o *observer) Reset(grid sudoku.Grid) {
+	o.emit(event{"reset", gridEvent{Grid: copyGrid(grid)}})
+}
+
+//emit hands e over to the event stream, holding the solver up until it is taken or the solve is given up
+func (o *observer) emit(e event) {
+	select {
+	case o.events <- e:
+	case <-o.ctx.Done():
+	}
+}
+
+func copyGrid(grid sudoku.Grid) sudoku.Grid {
+	c := make(sudoku.Grid, len(grid))
+	for i := range grid {
+		c[i] = append([]int(nil), grid[i]...)
+	}
+	return c
+}
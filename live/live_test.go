@@ -0,0 +1,158 @@
+package live
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	sudoku "github.com/alielbashir/samurai-sudoku-go"
+)
+
+func testGrid(t *testing.T) sudoku.Grid {
+	f, err := os.Open("../sudoku.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	grid, err := sudoku.ReadSamuraiGrid(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return grid
+}
+
+// streamed A Server-Sent Event as read by the client
+type streamed struct {
+	name string
+	data string
+}
+
+//readEvents reads the events of the stream at url until it ends
+func readEvents(t *testing.T, url string) []streamed {
+	response, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, response.StatusCode)
+	}
+	if got := response.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("want an event stream, got %q", got)
+	}
+
+	var events []streamed
+	var e streamed
+	scanner := bufio.NewScanner(response.Body)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			e.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		case line == "":
+			events = append(events, e)
+			e = streamed{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestEvents(t *testing.T) {
+	for _, solver := range []string{"concurrent", "double", "global"} {
+		t.Run(solver, func(t *testing.T) {
+			server := httptest.NewServer(New(testGrid(t), Options{}))
+			defer server.Close()
+
+			events := readEvents(t, server.URL+"/events?solver="+solver)
+			if len(events) < 3 {
+				t.Fatalf("want start, moves and done events, got %v", events)
+			}
+			if events[0].name != "start" {
+				t.Fatalf("want the stream to start with the puzzle, got %q", events[0].name)
+			}
+
+			last := events[len(events)-1]
+			if last.name != "done" {
+				t.Fatalf("want the stream to end with done, got %q: %s", last.name, last.data)
+			}
+			var done gridEvent
+			if err := json.Unmarshal([]byte(last.data), &done); err != nil {
+				t.Fatal(err)
+			}
+			if err := sudoku.Validate(done.Grid); err != nil {
+				t.Fatalf("invalid solution: %v", err)
+			}
+
+			moves := 0
+			for _, e := range events[1 : len(events)-1] {
+				switch e.name {
+				case "move":
+					var move moveEvent
+					if err := json.Unmarshal([]byte(e.data), &move); err != nil {
+						t.Fatal(err)
+					}
+					if move.Y < 0 || move.Y > 20 || move.X < 0 || move.X > 20 || move.Value < 0 || move.Value > 9 {
+						t.Fatalf("move out of the grid: %+v", move)
+					}
+					moves++
+				case "reset":
+				default:
+					t.Fatalf("unexpected %q event", e.name)
+				}
+			}
+			if moves != done.Moves {
+				t.Fatalf("want %d move events, got %d", done.Moves, moves)
+			}
+		})
+	}
+}
+
+func TestEventsBadRequest(t *testing.T) {
+	handler := New(testGrid(t), Options{})
+	for _, query := range []string{"solver=magic", "delay=soon", "delay=-1s"} {
+		t.Run(query, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/events?"+query, nil))
+			if recorder.Code != http.StatusBadRequest {
+				t.Fatalf("want status %d, got %d", http.StatusBadRequest, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestEventsTimeout(t *testing.T) {
+	server := httptest.NewServer(New(testGrid(t), Options{Delay: 10 * time.Millisecond, Timeout: 50 * time.Millisecond}))
+	defer server.Close()
+
+	events := readEvents(t, server.URL+"/events")
+	if last := events[len(events)-1]; last.name != "error" {
+		t.Fatalf("want the stream to end with an error, got %q", last.name)
+	}
+}
+
+func TestPage(t *testing.T) {
+	handler := New(testGrid(t), Options{})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "EventSource") {
+		t.Fatalf("want the page, got status %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("want status %d, got %d", http.StatusNotFound, recorder.Code)
+	}
+}
@@ -0,0 +1,536 @@
+package sudoku
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// LogicalSolution is what SolveLogically made of a puzzle
+type LogicalSolution struct {
+	Steps      []Deduction // Deductions applied, in order
+	Solved     bool        // Every cell got filled, otherwise the techniques got stuck and the puzzle needs guessing
+	Grid       Grid        // Grid once the steps are applied, partly filled if Solved is false
+	Candidates *Candidates // Candidates left once the steps are applied, for the empty cells of a stuck grid
+}
+
+// Difficulty is how hard a puzzle is to solve by hand, rated by the hardest technique its logical solution needs
+type Difficulty int
+
+const (
+	Easy   Difficulty = iota + 1 // Naked and hidden singles are enough
+	Medium                       // Locked candidates, naked or hidden pairs are needed
+	Hard                         // Triples, X-wings, swordfish, XY-wings or simple colouring are needed
+	Expert                       // The techniques get stuck, the puzzle needs guessing
+)
+
+func (d Difficulty) String() string {
+	switch d {
+	case Easy:
+		return "easy"
+	case Medium:
+		return "medium"
+	case Hard:
+		return "hard"
+	case Expert:
+		return "expert"
+	}
+	return fmt.Sprintf("Difficulty(%d)", int(d))
+}
+
+//Difficulty rates the puzzle by the hardest technique among the steps, Expert if they got stuck
+func (l *LogicalSolution) Difficulty() Difficulty {
+	if !l.Solved {
+		return Expert
+	}
+	d := Easy
+	for _, step := range l.Steps {
+		switch step.Technique {
+		case NakedSingle, HiddenSingle:
+		case LockedCandidates, NakedPair, HiddenPair:
+			if d < Medium {
+				d = Medium
+			}
+		default:
+			d = Hard
+		}
+	}
+	return d
+}
+
+//SolveLogically solves the puzzle with the techniques a person would use, never guessing: it applies the simplest deduction
+//Hint would find, over and over, across all the sub-sudokus of the layout, until the grid is full or no technique applies.
+//The puzzle's grid is left as it is. It returns the errors of Validate for grids breaking a rule, ErrUnsolvable if the steps
+//leave a cell or a digit of a unit without a candidate, and ctx's error once ctx is done
+func SolveLogically(ctx context.Context, samurai *SamuraiSudoku) (*LogicalSolution, error) {
+	c, err := NewCandidates(samurai)
+	if err != nil {
+		return nil, err
+	}
+	solution := &LogicalSolution{Candidates: c}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		d, err := c.Deduce()
+		switch {
+		case errors.Is(err, ErrSolved):
+			solution.Solved = true
+			solution.Grid = c.Grid()
+			return solution, nil
+		case errors.Is(err, ErrNoHint):
+			solution.Grid = c.Grid()
+			return solution, nil
+		case err != nil:
+			return nil, err
+		}
+		if err := c.Apply(d); err != nil {
+			return nil, err
+		}
+		solution.Steps = append(solution.Steps, *d)
+	}
+}
+
+//nakedSubset returns the first k cells of a unit holding only k digits between them, which other cells of the unit hold,
+//nil if there are none
+func (h *hinter) nakedSubset(k int) (*Deduction, error) {
+	for u, unit := range h.units {
+		var cells []int
+		for _, i := range h.unitCells[u] {
+			if m := h.masks[i]; m != 0 && bits.OnesCount32(m) <= k {
+				cells = append(cells, i)
+			}
+		}
+		var d *Deduction
+		combinations(len(cells), k, func(pick []int) bool {
+			var mask uint32
+			subset := make([]int, k)
+			for p, q := range pick {
+				subset[p] = cells[q]
+				mask |= h.masks[cells[q]]
+			}
+			if bits.OnesCount32(mask) != k {
+				return false
+			}
+			var eliminations []int
+			for _, j := range h.unitCells[u] {
+				if h.masks[j]&mask != 0 && !contains(subset, j) {
+					eliminations = append(eliminations, j)
+				}
+			}
+			if len(eliminations) == 0 {
+				return false
+			}
+			digits := maskDigits(mask)
+			d = h.deduction(subsetTechniques[k][0], 0, digits, eliminations)
+			d.Position, d.Unit, d.Index = unit.position, unit.kind, unit.index
+			d.Explanation = fmt.Sprintf("In %s, %s only hold %s between them, so those digits can't go in its other cells: %s",
+				h.unitName(u), h.cellList(subset, unit.position, " and "), numberList(digits), h.cellList(eliminations, unit.position, "; "))
+			return true
+		})
+		if d != nil {
+			return d, nil
+		}
+	}
+	return nil, nil
+}
+
+//hiddenSubset returns the first k digits only fitting in k cells of a unit, which hold other candidates, nil if there are none
+func (h *hinter) hiddenSubset(k int) (*Deduction, error) {
+	for u, unit := range h.units {
+		var digits []int
+		for n := 1; n <= h.size; n++ {
+			if places := h.places(u, n); h.used[u]&(1<<n) == 0 && len(places) >= 2 && len(places) <= k {
+				digits = append(digits, n)
+			}
+		}
+		var d *Deduction
+		combinations(len(digits), k, func(pick []int) bool {
+			var mask uint32
+			var cells []int
+			for _, q := range pick {
+				mask |= 1 << digits[q]
+				for _, i := range h.places(u, digits[q]) {
+					if !contains(cells, i) {
+						cells = append(cells, i)
+					}
+				}
+			}
+			if len(cells) != k {
+				return false
+			}
+			var others uint32
+			for _, i := range cells {
+				others |= h.masks[i] &^ mask
+			}
+			if others == 0 {
+				return false
+			}
+			d = h.deduction(subsetTechniques[k][1], 0, maskDigits(others), cells)
+			d.Position, d.Unit, d.Index = unit.position, unit.kind, unit.index
+			d.Explanation = fmt.Sprintf("In %s, %s only fit in %s, so those cells can't hold %s",
+				h.unitName(u), numberList(maskDigits(mask)), h.cellList(cells, unit.position, " and "), digitList(maskDigits(others)))
+			return true
+		})
+		if d != nil {
+			return d, nil
+		}
+	}
+	return nil, nil
+}
+
+// subsetTechniques The naked and hidden techniques for subsets of 2 and 3 cells
+var subsetTechniques = map[int][2]Technique{
+	2: {NakedPair, HiddenPair},
+	3: {NakedTriple, HiddenTriple},
+}
+
+//fish returns the first digit fitting in k rows of a sub-sudoku only in the same k columns, which ruled it out of the rest of
+//those columns, or the other way round: an X-wing for 2, a swordfish for 3. It returns nil if there is none
+func (h *hinter) fish(k int) (*Deduction, error) {
+	technique := map[int]Technique{2: XWing, 3: Swordfish}[k]
+	for _, position := range h.layout.Positions() {
+		for _, kinds := range [][2]string{{"row", "column"}, {"column", "row"}} {
+			base, cover := kinds[0], kinds[1]
+			for n := 1; n <= h.size; n++ {
+				var lines, covers []int // Base units with 2 to k cells left for n, and the cover indexes of those cells
+				for index := 0; index < h.size; index++ {
+					u, ok := h.unitAt[unitKey{position, base, index}]
+					if !ok || h.used[u]&(1<<n) != 0 {
+						continue
+					}
+					places := h.places(u, n)
+					if len(places) < 2 || len(places) > k {
+						continue
+					}
+					mask := 0
+					for _, i := range places {
+						row, column, _ := h.coordinates(i, position)
+						if base == "row" {
+							mask |= 1 << column
+						} else {
+							mask |= 1 << row
+						}
+					}
+					lines, covers = append(lines, u), append(covers, mask)
+				}
+				var d *Deduction
+				combinations(len(lines), k, func(pick []int) bool {
+					mask := 0
+					picked := make([]int, k)
+					for p, q := range pick {
+						mask |= covers[q]
+						picked[p] = lines[q]
+					}
+					if bits.OnesCount(uint(mask)) != k {
+						return false
+					}
+					var eliminations, indexes []int
+					for index := 0; index < h.size; index++ {
+						if mask&(1<<index) == 0 {
+							continue
+						}
+						indexes = append(indexes, index)
+						for _, j := range h.unitCells[h.unitAt[unitKey{position, cover, index}]] {
+							if h.masks[j]&(1<<n) == 0 || contains(eliminations, j) {
+								continue
+							}
+							inBase := false
+							for _, u := range picked {
+								inBase = inBase || h.inUnit(u, []int{j})
+							}
+							if !inBase {
+								eliminations = append(eliminations, j)
+							}
+						}
+					}
+					if len(eliminations) == 0 {
+						return false
+					}
+					d = h.deduction(technique, n, nil, eliminations)
+					d.Position, d.Unit, d.Index = position, base, h.units[picked[0]].index
+					bases := make([]int, k)
+					for p, u := range picked {
+						bases[p] = h.units[u].index + 1
+					}
+					for p := range indexes {
+						indexes[p]++
+					}
+					d.Explanation = fmt.Sprintf("In the %s sub-sudoku, %d only fits in %ss %s of %ss %s, so it can't go elsewhere in those %ss: %s",
+						position, n, cover, numberList(indexes), base, numberList(bases), cover, h.cellList(eliminations, position, "; "))
+					return true
+				})
+				if d != nil {
+					return d, nil
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+//xyWing returns the first cell holding x or y, seeing a cell holding x or z and another holding y or z, where cells seeing both
+//of them hold z, nil if there is none
+func (h *hinter) xyWing() (*Deduction, error) {
+	h.findPeers()
+	for pivot, mask := range h.masks {
+		if bits.OnesCount32(mask) != 2 {
+			continue
+		}
+		for _, a := range h.peers[pivot] {
+			shared := h.masks[a] & mask
+			if bits.OnesCount32(h.masks[a]) != 2 || bits.OnesCount32(shared) != 1 {
+				continue
+			}
+			z := h.masks[a] &^ mask
+			for _, b := range h.peers[pivot] {
+				if h.masks[b] != mask&^shared|z {
+					continue
+				}
+				var eliminations []int
+				for _, j := range h.peers[a] {
+					if j != b && h.isPeer[b][j] && h.masks[j]&z != 0 {
+						eliminations = append(eliminations, j)
+					}
+				}
+				if len(eliminations) == 0 {
+					continue
+				}
+				n := bits.TrailingZeros32(z)
+				d := h.deduction(XYWing, n, nil, eliminations)
+				d.Position = h.firstPosition(pivot)
+				d.Explanation = fmt.Sprintf("%s holds %s, and sees %s holding %s and %s holding %s: whichever digit the first holds, "+
+					"one of the others holds %d, so it can't go in the cells seeing both: %s",
+					capitalize(h.where(pivot)), digitList(maskDigits(mask)), h.where(a), digitList(maskDigits(h.masks[a])), h.where(b), digitList(maskDigits(h.masks[b])),
+					n, h.whereList(eliminations))
+				return d, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+//simpleColouring returns the first digit ruled out by colouring the cells it fits in twice in a unit, alternately, along the
+//chains they form: out of the cells of a colour two of which see each other, or else out of cells seeing both colours.
+//It returns nil if there is none
+func (h *hinter) simpleColouring() (*Deduction, error) {
+	h.findPeers()
+	for n := 1; n <= h.size; n++ {
+		links := make(map[int][]int)
+		for u := range h.units {
+			if places := h.places(u, n); h.used[u]&(1<<n) == 0 && len(places) == 2 {
+				a, b := places[0], places[1]
+				links[a], links[b] = append(links[a], b), append(links[b], a)
+			}
+		}
+		colours := make(map[int]int)
+		for start := range h.cells {
+			if _, done := colours[start]; done || len(links[start]) == 0 {
+				continue
+			}
+			// colour the chain from start, breadth first
+			chain := [][]int{{start}, nil}
+			colours[start] = 0
+			for queue := []int{start}; len(queue) > 0; queue = queue[1:] {
+				i := queue[0]
+				for _, j := range links[i] {
+					if _, done := colours[j]; !done {
+						colours[j] = 1 - colours[i]
+						chain[colours[j]] = append(chain[colours[j]], j)
+						queue = append(queue, j)
+					}
+				}
+			}
+			if d := h.colourDeduction(n, start, chain); d != nil {
+				return d, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+//colourDeduction returns what the two colours of the chain of cells n fits in rule out, nil if nothing
+func (h *hinter) colourDeduction(n int, start int, chain [][]int) *Deduction {
+	for colour, cells := range chain {
+		for p, a := range cells {
+			for _, b := range cells[p+1:] {
+				if !h.isPeer[a][b] {
+					continue
+				}
+				d := h.deduction(SimpleColouring, n, nil, cells)
+				d.Position = h.firstPosition(start)
+				d.Explanation = fmt.Sprintf("Colouring the cells %d fits in twice in a unit alternately from %s, %s and %s get the same colour "+
+					"but see each other, so %d goes in the cells of the other colour and not in these: %s",
+					n, h.where(start), h.where(a), h.where(b), n, h.whereList(chain[colour]))
+				return d
+			}
+		}
+	}
+	var eliminations []int
+	for j, mask := range h.masks {
+		if mask&(1<<n) == 0 || contains(chain[0], j) || contains(chain[1], j) {
+			continue
+		}
+		seen := [2]bool{}
+		for colour, cells := range chain {
+			for _, i := range cells {
+				seen[colour] = seen[colour] || h.isPeer[i][j]
+			}
+		}
+		if seen[0] && seen[1] {
+			eliminations = append(eliminations, j)
+		}
+	}
+	if len(eliminations) == 0 {
+		return nil
+	}
+	d := h.deduction(SimpleColouring, n, nil, eliminations)
+	d.Position = h.firstPosition(start)
+	d.Explanation = fmt.Sprintf("Colouring the cells %d fits in twice in a unit alternately from %s, %d goes in all the cells of one "+
+		"of the two colours, so it can't go in the cells seeing both: %s", n, h.where(start), n, h.whereList(eliminations))
+	return d
+}
+
+//deduction returns a deduction of technique ruling n, or digits if n is 0, out of the cells of eliminations
+func (h *hinter) deduction(technique Technique, n int, digits []int, eliminations []int) *Deduction {
+	d := &Deduction{Technique: technique, Value: n, Digits: digits}
+	for _, i := range eliminations {
+		d.Eliminations = append(d.Eliminations, Cell{h.cells[i].Row, h.cells[i].Column})
+	}
+	return d
+}
+
+//findPeers lists the cells sharing a unit with every cell, once
+func (h *hinter) findPeers() {
+	if h.peers != nil {
+		return
+	}
+	h.peers = make([][]int, len(h.cells))
+	h.isPeer = make([]map[int]bool, len(h.cells))
+	for i := range h.cells {
+		h.isPeer[i] = make(map[int]bool)
+		for _, u := range h.cellUnits[i] {
+			for _, j := range h.unitCells[u] {
+				if j != i && !h.isPeer[i][j] {
+					h.isPeer[i][j] = true
+					h.peers[i] = append(h.peers[i], j)
+				}
+			}
+		}
+	}
+}
+
+//coordinates returns the row and column of the i-th cell in position's sub-sudoku, false if it isn't in it
+func (h *hinter) coordinates(i int, position Position) (int, int, bool) {
+	c := h.cells[i]
+	for _, p := range h.layout.placements(c.Row, c.Column) {
+		if p.position == position {
+			return p.row, p.column, true
+		}
+	}
+	return 0, 0, false
+}
+
+//firstPosition returns the first sub-sudoku the i-th cell is in
+func (h *hinter) firstPosition(i int) Position {
+	position, _, _, _ := h.layout.locate(h.cells[i].Row, h.cells[i].Column)
+	return position
+}
+
+//where names the i-th cell by its row and column in its first sub-sudoku, e.g. "row 1, column 2 of the centre sub-sudoku"
+func (h *hinter) where(i int) string {
+	position := h.firstPosition(i)
+	return fmt.Sprintf("%s of the %s sub-sudoku", h.cellName(i, position), position)
+}
+
+//whereList names cells with where, separated by semicolons
+func (h *hinter) whereList(cells []int) string {
+	names := make([]string, len(cells))
+	for k, i := range cells {
+		names[k] = h.where(i)
+	}
+	return strings.Join(names, "; ")
+}
+
+//cellList names cells with cellName in position's sub-sudoku, separated by sep
+func (h *hinter) cellList(cells []int, position Position, sep string) string {
+	names := make([]string, len(cells))
+	for k, i := range cells {
+		names[k] = h.cellName(i, position)
+	}
+	return strings.Join(names, sep)
+}
+
+//capitalize returns s with its first letter in upper case, for names starting a sentence
+func capitalize(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+//combinations calls f with every k indexes out of n in increasing order, until f returns true
+func combinations(n int, k int, f func(pick []int) bool) {
+	pick := make([]int, k)
+	var rec func(from int, depth int) bool
+	rec = func(from int, depth int) bool {
+		if depth == k {
+			return f(pick)
+		}
+		for i := from; i <= n-(k-depth); i++ {
+			pick[depth] = i
+			if rec(i+1, depth+1) {
+				return true
+			}
+		}
+		return false
+	}
+	rec(0, 0)
+}
+
+func contains(cells []int, i int) bool {
+	for _, j := range cells {
+		if j == i {
+			return true
+		}
+	}
+	return false
+}
+
+//maskDigits returns the digits of a bitmask in increasing order
+func maskDigits(mask uint32) []int {
+	var digits []int
+	for ; mask != 0; mask &= mask - 1 {
+		digits = append(digits, bits.TrailingZeros32(mask))
+	}
+	return digits
+}
+
+//digitMask returns digits as a bitmask, bit n set for digit n
+func digitMask(digits []int) uint32 {
+	var mask uint32
+	for _, n := range digits {
+		mask |= 1 << n
+	}
+	return mask
+}
+
+//digitList writes digits for an explanation, e.g. "1, 2 or 3"
+func digitList(digits []int) string {
+	return joinNumbers(digits, " or ")
+}
+
+//numberList writes numbers for an explanation, e.g. "1, 2 and 3"
+func numberList(numbers []int) string {
+	return joinNumbers(numbers, " and ")
+}
+
+func joinNumbers(numbers []int, last string) string {
+	names := make([]string, len(numbers))
+	for i, n := range numbers {
+		names[i] = fmt.Sprint(n)
+	}
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + last + names[len(names)-1]
+}
@@ -0,0 +1,233 @@
+package sudoku
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSolveLogically(t *testing.T) {
+	solution, err := GlobalSolveSamuraiSudokuContext(context.Background(), newTestSamurai())
+	if err != nil {
+		t.Fatal(err)
+	}
+	samurai := newTestSamurai()
+	puzzle := samurai.Grid()
+
+	logical, err := SolveLogically(context.Background(), samurai)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !logical.Solved || !reflect.DeepEqual(logical.Grid, solution) {
+		t.Fatalf("want the puzzle solved, got:\n%v", logical.Grid)
+	}
+	if !reflect.DeepEqual(samurai.Grid(), puzzle) {
+		t.Fatalf("want the puzzle's grid left as it is")
+	}
+	placed := 0
+	for _, step := range logical.Steps {
+		if step.Explanation == "" {
+			t.Fatalf("want every step explained, got %+v", step)
+		}
+		if step.Technique != NakedSingle && step.Technique != HiddenSingle {
+			continue
+		}
+		placed++
+		if want := solution[step.Cell.Row][step.Cell.Column]; step.Value != want {
+			t.Fatalf("want %d placed in %v, got %+v", want, step.Cell, step)
+		}
+	}
+	empty := 0
+	for _, row := range puzzle {
+		for _, num := range row {
+			if num == 0 {
+				empty++
+			}
+		}
+	}
+	if placed != empty {
+		t.Fatalf("want a single for each of the %d empty cells, got %d", empty, placed)
+	}
+}
+
+func TestSolveLogicallyStuck(t *testing.T) {
+	var samurai SamuraiSudoku
+	samurai.SetGrid(NewSamuraiGrid())
+	logical, err := SolveLogically(context.Background(), &samurai)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logical.Solved || len(logical.Steps) != 0 || !reflect.DeepEqual(logical.Grid, NewSamuraiGrid()) {
+		t.Fatalf("want no progress on an empty grid, got %d steps", len(logical.Steps))
+	}
+	if got := logical.Candidates.Digits(Cell{0, 0}); len(got) != 9 {
+		t.Fatalf("want the candidates left reported, got %v", got)
+	}
+}
+
+func TestDifficulty(t *testing.T) {
+	testCases := []struct {
+		name     string
+		solution LogicalSolution
+		want     Difficulty
+	}{
+		{"singles", LogicalSolution{Solved: true, Steps: []Deduction{{Technique: NakedSingle}, {Technique: HiddenSingle}}}, Easy},
+		{"pair", LogicalSolution{Solved: true, Steps: []Deduction{{Technique: NakedPair}, {Technique: NakedSingle}}}, Medium},
+		{"X-wing", LogicalSolution{Solved: true, Steps: []Deduction{{Technique: XWing}, {Technique: LockedCandidates}}}, Hard},
+		{"stuck", LogicalSolution{Steps: []Deduction{{Technique: NakedSingle}}}, Expert},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.solution.Difficulty(); got != tc.want {
+				t.Fatalf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+
+	logical, err := SolveLogically(context.Background(), newTestSamurai())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the test puzzle needs locked candidates or pairs on top of singles
+	if got := logical.Difficulty(); got != Medium {
+		t.Fatalf("want the test puzzle rated %v, got %v", Medium, got)
+	}
+}
+
+//onlyIn rules n out of cells other than keep among those listed
+func onlyIn(c *Candidates, n int, cells []Cell, keep ...Cell) {
+	for _, d := range cells {
+		if indexOf(keep, d) < 0 {
+			c.Eliminate(d, n)
+		}
+	}
+}
+
+//topLeftRow returns the cells of row y of the top left sub-sudoku, and topLeftColumn those of column x
+func topLeftRow(y int) []Cell {
+	var cells []Cell
+	for x := 0; x < 9; x++ {
+		cells = append(cells, Cell{y, x})
+	}
+	return cells
+}
+
+func topLeftColumn(x int) []Cell {
+	var cells []Cell
+	for y := 0; y < 9; y++ {
+		cells = append(cells, Cell{y, x})
+	}
+	return cells
+}
+
+func TestTechniques(t *testing.T) {
+	testCases := []struct {
+		name  string
+		setup func(c *Candidates)
+		find  func(h *hinter) (*Deduction, error)
+		want  Deduction
+	}{
+		{
+			name: "naked pair",
+			setup: func(c *Candidates) {
+				c.Eliminate(Cell{0, 0}, 3, 4, 5, 6, 7, 8, 9)
+				c.Eliminate(Cell{0, 1}, 3, 4, 5, 6, 7, 8, 9)
+			},
+			find: func(h *hinter) (*Deduction, error) { return h.nakedSubset(2) },
+			want: Deduction{Technique: NakedPair, Digits: []int{1, 2}, Position: TopLeft, Unit: "row",
+				Eliminations: []Cell{{0, 2}, {0, 3}, {0, 4}, {0, 5}, {0, 6}, {0, 7}, {0, 8}}},
+		},
+		{
+			name: "hidden pair",
+			setup: func(c *Candidates) {
+				onlyIn(c, 1, topLeftRow(0), Cell{0, 0}, Cell{0, 1})
+				onlyIn(c, 2, topLeftRow(0), Cell{0, 0}, Cell{0, 1})
+			},
+			find: func(h *hinter) (*Deduction, error) { return h.hiddenSubset(2) },
+			want: Deduction{Technique: HiddenPair, Digits: []int{3, 4, 5, 6, 7, 8, 9}, Position: TopLeft, Unit: "row",
+				Eliminations: []Cell{{0, 0}, {0, 1}}},
+		},
+		{
+			name: "X-wing",
+			setup: func(c *Candidates) {
+				onlyIn(c, 1, topLeftRow(0), Cell{0, 1}, Cell{0, 4})
+				onlyIn(c, 1, topLeftRow(4), Cell{4, 1}, Cell{4, 4})
+			},
+			find: func(h *hinter) (*Deduction, error) { return h.fish(2) },
+			want: Deduction{Technique: XWing, Value: 1, Position: TopLeft, Unit: "row",
+				Eliminations: []Cell{{1, 1}, {2, 1}, {3, 1}, {5, 1}, {6, 1}, {7, 1}, {8, 1}, {1, 4}, {2, 4}, {3, 4}, {5, 4}, {6, 4}, {7, 4}, {8, 4}}},
+		},
+		{
+			name: "swordfish",
+			setup: func(c *Candidates) {
+				onlyIn(c, 1, topLeftColumn(0), Cell{0, 0}, Cell{3, 0})
+				onlyIn(c, 1, topLeftColumn(4), Cell{3, 4}, Cell{7, 4})
+				onlyIn(c, 1, topLeftColumn(8), Cell{0, 8}, Cell{7, 8})
+			},
+			find: func(h *hinter) (*Deduction, error) { return h.fish(3) },
+			want: Deduction{Technique: Swordfish, Value: 1, Position: TopLeft, Unit: "column",
+				Eliminations: []Cell{{0, 1}, {0, 2}, {0, 3}, {0, 5}, {0, 6}, {0, 7}, {3, 1}, {3, 2}, {3, 3}, {3, 5}, {3, 6}, {3, 7},
+					{7, 1}, {7, 2}, {7, 3}, {7, 5}, {7, 6}, {7, 7}}},
+		},
+		{
+			name: "XY-wing",
+			setup: func(c *Candidates) {
+				c.Eliminate(Cell{0, 0}, 3, 4, 5, 6, 7, 8, 9)
+				c.Eliminate(Cell{0, 4}, 2, 4, 5, 6, 7, 8, 9)
+				c.Eliminate(Cell{4, 0}, 1, 4, 5, 6, 7, 8, 9)
+			},
+			find: (*hinter).xyWing,
+			want: Deduction{Technique: XYWing, Value: 3, Position: TopLeft, Eliminations: []Cell{{4, 4}}},
+		},
+		{
+			name: "simple colouring",
+			setup: func(c *Candidates) {
+				// 1 alternates along (0, 0), (0, 4), (5, 4) and (3, 3), so (3, 0) sees both colours
+				onlyIn(c, 1, topLeftRow(0), Cell{0, 0}, Cell{0, 4})
+				onlyIn(c, 1, topLeftColumn(4), Cell{0, 4}, Cell{5, 4})
+				var box []Cell
+				for y := 3; y < 6; y++ {
+					for x := 3; x < 6; x++ {
+						box = append(box, Cell{y, x})
+					}
+				}
+				onlyIn(c, 1, box, Cell{5, 4}, Cell{3, 3})
+			},
+			find: (*hinter).simpleColouring,
+			want: Deduction{Technique: SimpleColouring, Value: 1, Position: TopLeft, Eliminations: []Cell{{3, 0}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var samurai SamuraiSudoku
+			samurai.SetGrid(NewSamuraiGrid())
+			c, err := NewCandidates(&samurai)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.setup(c)
+			got, err := tc.find(c.hinter())
+			if err != nil || got == nil {
+				t.Fatalf("want a deduction, got %v, %v", got, err)
+			}
+			if got.Explanation == "" {
+				t.Fatalf("want an explanation")
+			}
+			got.Explanation = ""
+			if !reflect.DeepEqual(*got, tc.want) {
+				t.Fatalf("want %+v, got %+v", tc.want, *got)
+			}
+
+			if err := c.Apply(got); err != nil {
+				t.Fatal(err)
+			}
+			for _, d := range got.Eliminations {
+				for _, n := range append(got.Digits, got.Value) {
+					if n != 0 && c.Has(d, n) {
+						t.Fatalf("want %d ruled out of %v", n, d)
+					}
+				}
+			}
+		})
+	}
+}
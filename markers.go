@@ -0,0 +1,289 @@
+package sudoku
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MarkerKind is the relation a marker between two orthogonally adjacent cells asks their digits to have
+type MarkerKind int
+
+const (
+	WhiteDot MarkerKind = iota // Kropki white dot: the digits are consecutive
+	BlackDot                   // Kropki black dot: one digit is twice the other
+	XSum                       // X: the digits add up to 10
+	VSum                       // V: the digits add up to 5
+)
+
+var markerNames = [...]string{"white", "black", "x", "v"}
+
+func (k MarkerKind) String() string {
+	if k < 0 || int(k) >= len(markerNames) {
+		return fmt.Sprintf("MarkerKind(%d)", int(k))
+	}
+	return markerNames[k]
+}
+
+//ParseMarkerKind returns the kind of marker named name: white, black, x or v, in either case
+func ParseMarkerKind(name string) (MarkerKind, error) {
+	for k, n := range markerNames {
+		if strings.EqualFold(strings.TrimSpace(name), n) {
+			return MarkerKind(k), nil
+		}
+	}
+	return 0, fmt.Errorf("sudoku: unknown marker %q", name)
+}
+
+//MarshalText writes the kind's name, so that markers are written as {"kind": "white", ...} in JSON
+func (k MarkerKind) MarshalText() ([]byte, error) {
+	if k < 0 || int(k) >= len(markerNames) {
+		return nil, fmt.Errorf("sudoku: unknown marker kind %d", int(k))
+	}
+	return []byte(k.String()), nil
+}
+
+//UnmarshalText parses a kind's name, see ParseMarkerKind
+func (k *MarkerKind) UnmarshalText(text []byte) error {
+	kind, err := ParseMarkerKind(string(text))
+	if err != nil {
+		return err
+	}
+	*k = kind
+	return nil
+}
+
+//holds tells if digits a and b have the relation k asks for
+func (k MarkerKind) holds(a int, b int) bool {
+	switch k {
+	case WhiteDot:
+		return a-b == 1 || b-a == 1
+	case BlackDot:
+		return a == 2*b || b == 2*a
+	case XSum:
+		return a+b == 10
+	case VSum:
+		return a+b == 5
+	}
+	return false
+}
+
+//related returns the digits from 1 to size having the relation k with num, as a bitmask
+func (k MarkerKind) related(num int, size int) uint32 {
+	var mask uint32
+	for n := 1; n <= size; n++ {
+		if k.holds(num, n) {
+			mask |= 1 << n
+		}
+	}
+	return mask
+}
+
+// Marker is a relation between the digits of two orthogonally adjacent cells of the whole grid, as in Kropki or XV sudoku.
+// Like cages, markers may join cells of different sub-sudokus
+type Marker struct {
+	Kind  MarkerKind `json:"kind"`
+	Cells [2]Cell    `json:"cells"`
+}
+
+// MarkerError is returned by Validate for adjacent cells whose digits break the relation of the marker between them,
+// or have the relation of a negative constraint's kind of marker without one between them
+type MarkerError struct {
+	Kind     MarkerKind
+	Cells    [2]Cell
+	Negative bool // There is no marker between the cells, yet their digits have the relation of Kind
+}
+
+func (e *MarkerError) Error() string {
+	a, b := formatCell(e.Cells[0]), formatCell(e.Cells[1])
+	if e.Negative {
+		return fmt.Sprintf("sudoku: the digits of %s and %s match a %s marker, but there is none between them", a, b, e.Kind)
+	}
+	return fmt.Sprintf("sudoku: the digits of %s and %s don't match the %s marker between them", a, b, e.Kind)
+}
+
+//sides returns the cells sharing a side with c, some of which may be off the grid
+func sides(c Cell) [4]Cell {
+	return [4]Cell{{c.Row - 1, c.Column}, {c.Row, c.Column - 1}, {c.Row, c.Column + 1}, {c.Row + 1, c.Column}}
+}
+
+//adjacent tells if cells a and b share a side
+func adjacent(a Cell, b Cell) bool {
+	for _, c := range sides(a) {
+		if c == b {
+			return true
+		}
+	}
+	return false
+}
+
+//checkMarkers returns an error wrapping ErrInvalidLayout if a marker has cells outside layout's sub-sudokus or not sharing a side,
+//is of an unknown kind, or is between the same cells as another, and if a negative constraint's kind is unknown or given twice
+func checkMarkers(markers []Marker, negative []MarkerKind, layout *Layout) error {
+	seen := make(map[[2]Cell]int)
+	for i, m := range markers {
+		if _, err := m.Kind.MarshalText(); err != nil {
+			return fmt.Errorf("%w: marker %d: %v", ErrInvalidLayout, i+1, err)
+		}
+		a, b := m.Cells[0], m.Cells[1]
+		for _, c := range m.Cells {
+			if !layout.Contains(c.Row, c.Column) {
+				return fmt.Errorf("%w: cell (%d, %d) of marker %d isn't in a sub-sudoku of %s", ErrInvalidLayout, c.Row+1, c.Column+1, i+1, layout)
+			}
+		}
+		if !adjacent(a, b) {
+			return fmt.Errorf("%w: the cells of marker %d don't share a side", ErrInvalidLayout, i+1)
+		}
+		for _, pair := range [][2]Cell{{a, b}, {b, a}} {
+			if j, ok := seen[pair]; ok {
+				return fmt.Errorf("%w: markers %d and %d are between the same cells", ErrInvalidLayout, j+1, i+1)
+			}
+		}
+		seen[m.Cells] = i
+	}
+	kinds := make(map[MarkerKind]bool)
+	for _, k := range negative {
+		if _, err := k.MarshalText(); err != nil {
+			return fmt.Errorf("%w: negative constraint: %v", ErrInvalidLayout, err)
+		}
+		if kinds[k] {
+			return fmt.Errorf("%w: negative constraint on %s markers given twice", ErrInvalidLayout, k)
+		}
+		kinds[k] = true
+	}
+	return nil
+}
+
+//markerIndex returns the markers every marked cell is in
+func markerIndex(markers []Marker) map[Cell][]int {
+	index := make(map[Cell][]int)
+	for i, m := range markers {
+		for _, c := range m.Cells {
+			index[c] = append(index[c], i)
+		}
+	}
+	return index
+}
+
+//markerBetween returns the index of the marker between cells a and b, looked up by cell in index, -1 if there is none
+func markerBetween(markers []Marker, index map[Cell][]int, a Cell, b Cell) int {
+	for _, i := range index[a] {
+		if m := markers[i]; m.Cells[0] == b || m.Cells[1] == b {
+			return i
+		}
+	}
+	return -1
+}
+
+//validateMarkers checks that the filled cells of grid follow the relations of the variant's markers, and that adjacent cells
+//without a marker between them don't have the relation of a kind of marker with a negative constraint.
+//It returns a *MarkerError for the first pair of cells that doesn't
+func validateMarkers(grid Grid, variant *Variant) error {
+	for _, m := range variant.Markers {
+		a, b := grid[m.Cells[0].Row][m.Cells[0].Column], grid[m.Cells[1].Row][m.Cells[1].Column]
+		if a > 0 && b > 0 && !m.Kind.holds(a, b) {
+			return &MarkerError{Kind: m.Kind, Cells: m.Cells}
+		}
+	}
+	if len(variant.Negative) == 0 {
+		return nil
+	}
+	index := markerIndex(variant.Markers)
+	for y, row := range grid {
+		for x, num := range row {
+			if num <= 0 {
+				continue
+			}
+			// every pair of cells once, from the cell above or to the left
+			c := Cell{y, x}
+			for _, d := range []Cell{{y, x + 1}, {y + 1, x}} {
+				if d.Row >= len(grid) || d.Column >= len(grid[d.Row]) || grid[d.Row][d.Column] <= 0 {
+					continue
+				}
+				if markerBetween(variant.Markers, index, c, d) >= 0 {
+					continue
+				}
+				for _, k := range variant.Negative {
+					if k.holds(num, grid[d.Row][d.Column]) {
+						return &MarkerError{Kind: k, Cells: [2]Cell{c, d}, Negative: true}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+//possibleMarkers checks if the canvas cell at row y and column x of grid can be filled with n given the markers next to it,
+//listed by cell in index, and the negative constraints of variant
+func possibleMarkers(grid Grid, y int, x int, n int, variant *Variant, index map[Cell][]int) bool {
+	c := Cell{y, x}
+	for _, d := range sides(c) {
+		if d.Row < 0 || d.Row >= len(grid) || d.Column < 0 || d.Column >= len(grid[d.Row]) {
+			continue
+		}
+		num := grid[d.Row][d.Column]
+		if num <= 0 {
+			continue
+		}
+		if i := markerBetween(variant.Markers, index, c, d); i >= 0 {
+			if !variant.Markers[i].Kind.holds(n, num) {
+				return false
+			}
+			continue
+		}
+		for _, k := range variant.Negative {
+			if k.holds(n, num) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+//formatMarker writes a marker for a header line, its kind followed by its cells counted from 1, e.g. "white r1c1 r1c2"
+func formatMarker(m Marker) string {
+	return fmt.Sprintf("%s %s %s", m.Kind, formatCell(m.Cells[0]), formatCell(m.Cells[1]))
+}
+
+//parseMarker parses a header line's marker, as written by formatMarker
+func parseMarker(value string) (Marker, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 3 {
+		return Marker{}, fmt.Errorf("marker %q isn't a kind and two cells", value)
+	}
+	kind, err := ParseMarkerKind(fields[0])
+	if err != nil {
+		return Marker{}, err
+	}
+	m := Marker{Kind: kind}
+	for i, field := range fields[1:] {
+		c, ok := parseCellName(field)
+		if !ok {
+			return Marker{}, fmt.Errorf("bad cell %q in marker %q", field, value)
+		}
+		m.Cells[i] = c
+	}
+	return m, nil
+}
+
+//formatMarkerKinds writes kinds for a header line, e.g. "white, black"
+func formatMarkerKinds(kinds []MarkerKind) string {
+	names := make([]string, len(kinds))
+	for i, k := range kinds {
+		names[i] = k.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+//parseMarkerKinds parses a header line's comma separated kinds of markers
+func parseMarkerKinds(value string) ([]MarkerKind, error) {
+	var kinds []MarkerKind
+	for _, name := range strings.Split(value, ",") {
+		k, err := ParseMarkerKind(name)
+		if err != nil {
+			return nil, err
+		}
+		kinds = append(kinds, k)
+	}
+	return kinds, nil
+}
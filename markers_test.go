@@ -0,0 +1,174 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMarkerKinds(t *testing.T) {
+	testCases := []struct {
+		kind    MarkerKind
+		related []int // Digits related to 4
+	}{
+		{WhiteDot, []int{3, 5}},
+		{BlackDot, []int{2, 8}},
+		{XSum, []int{6}},
+		{VSum, []int{1}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.kind.String(), func(t *testing.T) {
+			var want uint32
+			for _, n := range tc.related {
+				want |= 1 << n
+			}
+			if got := tc.kind.related(4, 9); got != want {
+				t.Fatalf("want %b, got %b", want, got)
+			}
+			text, err := json.Marshal(tc.kind)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got MarkerKind
+			if err := json.Unmarshal(text, &got); err != nil || got != tc.kind {
+				t.Fatalf("want %s read back from %s, got %v, %v", tc.kind, text, got, err)
+			}
+		})
+	}
+	if _, err := ParseMarkerKind("grey"); err == nil {
+		t.Fatalf("want an error for an unknown marker")
+	}
+	if _, err := json.Marshal(MarkerKind(9)); err == nil {
+		t.Fatalf("want an error writing an unknown marker")
+	}
+}
+
+//markerPuzzle sudoku.txt with the middle rows of the Centre sub-sudoku cleared, and a marker of the first of kinds whose
+//relation holds between every pair of adjacent cells of the grid, kinds being negative constraints
+func markerPuzzle(t *testing.T, kinds ...MarkerKind) (Grid, *Variant) {
+	puzzle, solution := centreRowsPuzzle(t, 15)
+	variant := &Variant{Negative: kinds}
+	for y, row := range solution {
+		for x, num := range row {
+			if num <= 0 {
+				continue
+			}
+			for _, d := range []Cell{{y, x + 1}, {y + 1, x}} {
+				if !Samurai.Contains(d.Row, d.Column) {
+					continue
+				}
+				for _, k := range kinds {
+					if k.holds(num, solution[d.Row][d.Column]) {
+						variant.Markers = append(variant.Markers, Marker{Kind: k, Cells: [2]Cell{{y, x}, d}})
+						break
+					}
+				}
+			}
+		}
+	}
+	return puzzle, variant
+}
+
+func TestMarkers(t *testing.T) {
+	for _, kinds := range [][]MarkerKind{{WhiteDot, BlackDot}, {XSum, VSum}} {
+		puzzle, variant := markerPuzzle(t, kinds...)
+		t.Run(formatMarkerKinds(kinds), func(t *testing.T) {
+			checkVariantSolves(t, puzzle, variant, nil)
+		})
+	}
+}
+
+func TestMarkerValidation(t *testing.T) {
+	grid := NewSamuraiGrid()
+	grid[0][0], grid[0][1] = 3, 4
+	variant := &Variant{Markers: []Marker{{Kind: BlackDot, Cells: [2]Cell{{1, 0}, {0, 0}}}}, Negative: []MarkerKind{WhiteDot}}
+
+	var samurai SamuraiSudoku
+	if err := samurai.SetVariant(variant); err != nil {
+		t.Fatal(err)
+	}
+	samurai.SetGrid(grid)
+	var markerErr *MarkerError
+	if err := samurai.Validate(); !errors.As(err, &markerErr) || !markerErr.Negative || markerErr.Kind != WhiteDot {
+		t.Fatalf("want consecutive digits without a white dot to break the negative constraint, got %v", err)
+	}
+
+	grid[0][1] = 0
+	if possible(samurai.GetSubSudoku(TopLeft), 1, 0, 7, TopLeft, &samurai) {
+		t.Fatalf("want 7 impossible next to 3 across a black dot")
+	}
+	if !possible(samurai.GetSubSudoku(TopLeft), 1, 0, 6, TopLeft, &samurai) {
+		t.Fatalf("want 6 possible next to 3 across a black dot")
+	}
+	if possible(samurai.GetSubSudoku(TopLeft), 0, 1, 2, TopLeft, &samurai) {
+		t.Fatalf("want 2 impossible next to 3 without a white dot")
+	}
+
+	grid[1][0] = 5
+	if err := samurai.Validate(); !errors.As(err, &markerErr) || markerErr.Negative || markerErr.Kind != BlackDot {
+		t.Fatalf("want 3 and 5 to break the black dot, got %v", err)
+	}
+}
+
+func TestMarkerErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		markers  []Marker
+		negative []MarkerKind
+	}{
+		{"cells not sharing a side", []Marker{{Kind: WhiteDot, Cells: [2]Cell{{0, 0}, {1, 1}}}}, nil},
+		{"cell in a gap", []Marker{{Kind: XSum, Cells: [2]Cell{{0, 8}, {0, 9}}}}, nil},
+		{"same cells twice", []Marker{{Kind: XSum, Cells: [2]Cell{{0, 0}, {0, 1}}}, {Kind: VSum, Cells: [2]Cell{{0, 1}, {0, 0}}}}, nil},
+		{"unknown kind", []Marker{{Kind: MarkerKind(7), Cells: [2]Cell{{0, 0}, {0, 1}}}}, nil},
+		{"negative constraint twice", nil, []MarkerKind{VSum, VSum}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var samurai SamuraiSudoku
+			if err := samurai.SetVariant(&Variant{Markers: tc.markers, Negative: tc.negative}); !errors.Is(err, ErrInvalidLayout) {
+				t.Fatalf("want ErrInvalidLayout, got %v", err)
+			}
+		})
+	}
+
+	if err := newTestSamurai().SetVariant(&Variant{Markers: []Marker{{Kind: WhiteDot, Cells: [2]Cell{{0, 0}, {0, 1}}}}}); err != nil {
+		t.Fatalf("want a marker between the given 1 and 6 accepted until the puzzle is validated, got %v", err)
+	}
+}
+
+func TestParseMarker(t *testing.T) {
+	want := Marker{Kind: XSum, Cells: [2]Cell{{0, 0}, {1, 0}}}
+	if got := formatMarker(want); got != "x r1c1 r2c1" {
+		t.Fatalf("want the marker written as \"x r1c1 r2c1\", got %q", got)
+	}
+	if got, err := parseMarker(" X R1C1  r2c1 "); err != nil || got != want {
+		t.Fatalf("want %+v, got %+v, %v", want, got, err)
+	}
+	for _, value := range []string{"x r1c1", "grey r1c1 r1c2", "x r1c1 r1c", "x r1c1 r1c2 r1c3"} {
+		if _, err := parseMarker(value); err == nil {
+			t.Fatalf("%q: want an error", value)
+		}
+	}
+	if got, err := parseMarkerKinds("white, Black"); err != nil || len(got) != 2 || got[0] != WhiteDot || got[1] != BlackDot {
+		t.Fatalf("want white and black, got %v, %v", got, err)
+	}
+}
+
+func TestWriteImageMarkers(t *testing.T) {
+	samurai := emptyTestSamurai()
+	if err := samurai.SetVariant(&Variant{Markers: []Marker{
+		{Kind: BlackDot, Cells: [2]Cell{{0, 0}, {0, 1}}},
+		{Kind: WhiteDot, Cells: [2]Cell{{1, 0}, {2, 0}}},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	img := pictureImage(t, samurai)
+
+	// the black dot sits on the side between the first two cells, at (0.5, 1), the white one below the second row, at (2, 0.5)
+	if !hasColour(img, 0.45, 1.05, 0.55, 1.1, imageMarkColour) {
+		t.Fatalf("want a black dot between the first two cells")
+	}
+	if !hasColour(img, 2.03, 0.45, 2.07, 0.55, imagePaperColour) || !hasColour(img, 2.12, 0.45, 2.17, 0.55, imageMarkColour) {
+		t.Fatalf("want a white dot with a dark outline between the second and third rows")
+	}
+}
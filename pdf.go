@@ -0,0 +1,270 @@
+package sudoku
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Widths of the characters from space to tilde of the standard Helvetica fonts, in thousandths of the font size
+var (
+	helveticaWidths = [...]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [...]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// helveticaCapHeight is the height of the capitals and digits of the Helvetica fonts, in thousandths of the font size
+const helveticaCapHeight = 718
+
+// pdfDocument A PDF document being drawn page by page. Its text is set in the standard Helvetica fonts, which PDF readers
+// provide, so that no font needs embedding
+type pdfDocument struct {
+	title         string
+	width, height float64 // Of the pages, in points
+	pages         []*pdfPage
+}
+
+// pdfPage The content stream of a page of a pdfDocument, drawn in points from the top left corner of the page
+type pdfPage struct {
+	b      bytes.Buffer
+	height float64
+}
+
+//newPage adds a blank page to the document and returns it
+func (d *pdfDocument) newPage() *pdfPage {
+	p := &pdfPage{height: d.height}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+//write writes the document to w, its pages' content compressed
+func (d *pdfDocument) write(w io.Writer) error {
+	var b bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	// the page and content objects of page i come after the catalog, the page tree, the two fonts and the information
+	const firstPage = 6
+	b.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title %s /Producer (samurai-sudoku-go) >>", pdfString(d.title)))
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfNumber(d.width), pdfNumber(d.height), firstPage+2*i+1))
+		var content bytes.Buffer
+		z := zlib.NewWriter(&content)
+		if _, err := z.Write(page.b.Bytes()); err != nil {
+			return err
+		}
+		if err := z.Close(); err != nil {
+			return err
+		}
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", content.Len(), content.Bytes()))
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+//path adds the path through points, from the top left corner of the page, closed if asked for
+func (p *pdfPage) path(points []point, closed bool) {
+	for i, pt := range points {
+		op := "l"
+		if i == 0 {
+			op = "m"
+		}
+		fmt.Fprintf(&p.b, "%s %s %s\n", pdfNumber(pt.x), pdfNumber(p.height-pt.y), op)
+	}
+	if closed {
+		p.b.WriteString("h\n")
+	}
+}
+
+//circlePath adds the path of a circle, as four Bézier curves
+func (p *pdfPage) circlePath(centre point, r float64) {
+	// the control points of a quarter circle are this far along the tangents at its ends
+	k := r * 4 * (math.Sqrt2 - 1) / 3
+	x, y := centre.x, p.height-centre.y
+	fmt.Fprintf(&p.b, "%s %s m\n", pdfNumber(x+r), pdfNumber(y))
+	for _, q := range [4][6]float64{
+		{x + r, y + k, x + k, y + r, x, y + r},
+		{x - k, y + r, x - r, y + k, x - r, y},
+		{x - r, y - k, x - k, y - r, x, y - r},
+		{x + k, y - r, x + r, y - k, x + r, y},
+	} {
+		fmt.Fprintf(&p.b, "%s %s %s %s %s %s c\n", pdfNumber(q[0]), pdfNumber(q[1]), pdfNumber(q[2]), pdfNumber(q[3]), pdfNumber(q[4]), pdfNumber(q[5]))
+	}
+	p.b.WriteString("h\n")
+}
+
+//fill sets the colour shapes are filled with
+func (p *pdfPage) fill(c color.RGBA) {
+	fmt.Fprintf(&p.b, "%s rg\n", pdfColour(c))
+}
+
+//stroke sets the colour and width of the lines stroked, dashed every dash points unless dash is 0
+func (p *pdfPage) stroke(c color.RGBA, width float64, dash float64) {
+	fmt.Fprintf(&p.b, "%s RG %s w 1 J 1 j ", pdfColour(c), pdfNumber(width))
+	if dash > 0 {
+		fmt.Fprintf(&p.b, "[%s] 0 d\n", pdfNumber(dash))
+	} else {
+		p.b.WriteString("[] 0 d\n")
+	}
+}
+
+//text writes text with its baseline starting at the given point, or centred on it
+func (p *pdfPage) text(at point, text string, size float64, bold bool, c color.RGBA, centred bool) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	x := at.x
+	if centred {
+		x -= textWidth(text, size, bold) / 2
+	}
+	p.fill(c)
+	fmt.Fprintf(&p.b, "BT /%s %s Tf %s %s Td %s Tj ET\n", font, pdfNumber(size), pdfNumber(x), pdfNumber(p.height-at.y), pdfString(text))
+}
+
+//textWidth returns the width of text set in Helvetica, or Helvetica Bold, at size points.
+//Characters outside ASCII are counted as wide as a digit
+func textWidth(text string, size float64, bold bool) float64 {
+	widths := helveticaWidths[:]
+	if bold {
+		widths = helveticaBoldWidths[:]
+	}
+	total := 0
+	for _, r := range text {
+		if ' ' <= r && r <= '~' {
+			total += widths[r-' ']
+		} else {
+			total += widths['0'-' ']
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// pdfPen draws a puzzle's picture on a page, its canvas' top left corner at left,top and scale points to a cell
+type pdfPen struct {
+	page      *pdfPage
+	left, top float64
+	scale     float64
+}
+
+//at returns where pt is on the page
+func (p *pdfPen) at(pt point) point {
+	return point{p.left + (pt.x+imageMargin)*p.scale, p.top + (pt.y+imageMargin)*p.scale}
+}
+
+func (p *pdfPen) points(points []point) []point {
+	at := make([]point, len(points))
+	for i, pt := range points {
+		at[i] = p.at(pt)
+	}
+	return at
+}
+
+func (p *pdfPen) polygon(points []point, c color.RGBA) {
+	p.page.fill(c)
+	p.page.path(p.points(points), true)
+	p.page.b.WriteString("f\n")
+}
+
+func (p *pdfPen) polyline(points []point, width float64, c color.RGBA, dashed bool) {
+	var dash float64
+	if dashed {
+		dash = p.scale / 12
+	}
+	p.page.stroke(c, width*p.scale, dash)
+	p.page.path(p.points(points), false)
+	p.page.b.WriteString("S\n")
+}
+
+func (p *pdfPen) disc(centre point, r float64, c color.RGBA) {
+	p.page.fill(c)
+	p.page.circlePath(p.at(centre), r*p.scale)
+	p.page.b.WriteString("f\n")
+}
+
+func (p *pdfPen) circle(centre point, r float64, width float64, c color.RGBA) {
+	p.page.stroke(c, width*p.scale, 0)
+	p.page.circlePath(p.at(centre), r*p.scale)
+	p.page.b.WriteString("S\n")
+}
+
+func (p *pdfPen) text(centre point, text string, size float64, bold bool, c color.RGBA) {
+	// the baseline sits half the height of a digit below the middle
+	at := p.at(centre)
+	at.y += size * p.scale * helveticaCapHeight / 2000
+	p.page.text(at, text, size*p.scale, bold, c, true)
+}
+
+// winAnsiPunctuation holds the characters WinAnsi puts where Latin-1 has its C1 controls, such as dashes and curly quotes
+var winAnsiPunctuation = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, '‰': 0x89, '‹': 0x8b,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99, '›': 0x9b,
+}
+
+//pdfString writes s as a PDF string in the WinAnsi encoding of the fonts, characters it lacks replaced by question marks
+func pdfString(s string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case ' ' <= r && r <= '~' || 0xa0 <= r && r <= 0xff:
+			// Latin-1 matches WinAnsi from space on, but for its C1 controls
+			b.WriteByte(byte(r))
+		case winAnsiPunctuation[r] != 0:
+			b.WriteByte(winAnsiPunctuation[r])
+		default:
+			b.WriteByte('?')
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+//pdfNumber writes a length in points to a hundredth
+func pdfNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+func pdfColour(c color.RGBA) string {
+	return fmt.Sprintf("%s %s %s", pdfNumber(float64(c.R)/255), pdfNumber(float64(c.G)/255), pdfNumber(float64(c.B)/255))
+}
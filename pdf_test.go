@@ -0,0 +1,45 @@
+package sudoku
+
+import (
+	"testing"
+)
+
+func TestPDFString(t *testing.T) {
+	testCases := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"ascii", "Puzzle 1", "(Puzzle 1)"},
+		{"escaped", `a (b) \ c`, `(a \(b\) \\ c)`},
+		{"latin-1", "café", "(caf\xe9)"},
+		{"punctuation", "“1–2”", "(\x931\x962\x94)"},
+		{"missing", "数独", "(??)"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := pdfString(tc.s); got != tc.want {
+				t.Fatalf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	testCases := []struct {
+		text string
+		bold bool
+		want float64
+	}{
+		{"9", false, 5.56},
+		{"Wi", false, 11.66},
+		{"Wi", true, 12.22},
+		{"Puzzle", true, 31.12},
+		{"é", false, 5.56},
+	}
+	for _, tc := range testCases {
+		if got := textWidth(tc.text, 10, tc.bold); pdfNumber(got) != pdfNumber(tc.want) {
+			t.Errorf("want %q %v points wide, got %v", tc.text, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package sudoku
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// pngPen draws a puzzle's picture on an image, anti-aliased, scale pixels to a cell
+type pngPen struct {
+	img   *image.RGBA
+	scale float64
+	faces map[pngFace]font.Face // Every face text is written in, made up front so that fonts failing to make one are reported
+}
+
+// pngFace A font at one of the text sizes of a picture, in cells, as faces are kept by
+type pngFace struct {
+	bold bool
+	size float64
+}
+
+func newPNGPen(width float64, height float64, scale float64, options ImageOptions) (*pngPen, error) {
+	regular, bold := goregular.TTF, gobold.TTF
+	if options.Font != nil {
+		regular, bold = options.Font, options.Font
+	}
+	if options.BoldFont != nil {
+		bold = options.BoldFont
+	}
+	p := &pngPen{
+		img:   image.NewRGBA(image.Rect(0, 0, int(math.Ceil(width*scale)), int(math.Ceil(height*scale)))),
+		scale: scale,
+		faces: make(map[pngFace]font.Face),
+	}
+	for _, f := range []struct {
+		data []byte
+		bold bool
+		name string
+	}{{regular, false, "font"}, {bold, true, "bold font"}} {
+		parsed, err := opentype.Parse(f.data)
+		if err != nil {
+			return nil, fmt.Errorf("sudoku: %s: %w", f.name, err)
+		}
+		for _, size := range []float64{digitTextSize, cageTextSize} {
+			face, err := opentype.NewFace(parsed, &opentype.FaceOptions{Size: size * scale, DPI: 72, Hinting: font.HintingNone})
+			if err != nil {
+				return nil, fmt.Errorf("sudoku: %s: %w", f.name, err)
+			}
+			p.faces[pngFace{f.bold, size}] = face
+		}
+	}
+	return p, nil
+}
+
+//encode writes the picture as a PNG image to w
+func (p *pngPen) encode(w io.Writer) error {
+	return png.Encode(w, p.img)
+}
+
+//pixel returns where pt is on the image
+func (p *pngPen) pixel(pt point) (float64, float64) {
+	return (pt.x + imageMargin) * p.scale, (pt.y + imageMargin) * p.scale
+}
+
+//fill fills the shapes made of the closed paths through the points of every polygon, with the non-zero winding rule
+func (p *pngPen) fill(polygons [][]point, c color.RGBA) {
+	bounds := image.Rectangle{}
+	for _, polygon := range polygons {
+		for _, pt := range polygon {
+			x, y := p.pixel(pt)
+			r := image.Rect(int(math.Floor(x)), int(math.Floor(y)), int(math.Ceil(x))+1, int(math.Ceil(y))+1)
+			if bounds.Empty() {
+				bounds = r
+			}
+			bounds = bounds.Union(r)
+		}
+	}
+	bounds = bounds.Intersect(p.img.Bounds())
+	if bounds.Empty() {
+		return
+	}
+	// the rasterizer only covers the shapes, as one covering the whole image would be cleared for every shape
+	z := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	for _, polygon := range polygons {
+		for i, pt := range polygon {
+			x, y := p.pixel(pt)
+			x, y = x-float64(bounds.Min.X), y-float64(bounds.Min.Y)
+			if i == 0 {
+				z.MoveTo(float32(x), float32(y))
+			} else {
+				z.LineTo(float32(x), float32(y))
+			}
+		}
+		z.ClosePath()
+	}
+	z.Draw(p.img, bounds, image.NewUniform(c), image.Point{})
+}
+
+func (p *pngPen) polygon(points []point, c color.RGBA) {
+	p.fill([][]point{points}, c)
+}
+
+func (p *pngPen) polyline(points []point, width float64, c color.RGBA, dashed bool) {
+	if dashed {
+		const dash = 1.0 / 12
+		for i := 1; i < len(points); i++ {
+			a, b := points[i-1], points[i]
+			length := math.Hypot(b.x-a.x, b.y-a.y)
+			for d := 0.0; d < length; d += 2 * dash {
+				end := math.Min(d+dash, length)
+				p.polyline([]point{
+					{a.x + (b.x-a.x)*d/length, a.y + (b.y-a.y)*d/length},
+					{a.x + (b.x-a.x)*end/length, a.y + (b.y-a.y)*end/length},
+				}, width, c, false)
+			}
+		}
+		return
+	}
+
+	// a rectangle along every segment and a disc on every point, all turning the same way so that they add up
+	var polygons [][]point
+	r := width / 2
+	for i, pt := range points {
+		polygons = append(polygons, circlePoints(pt, r))
+		if i == 0 {
+			continue
+		}
+		a := points[i-1]
+		length := math.Hypot(pt.x-a.x, pt.y-a.y)
+		if length == 0 {
+			continue
+		}
+		nx, ny := (a.y-pt.y)/length*r, (pt.x-a.x)/length*r
+		polygons = append(polygons, []point{{a.x + nx, a.y + ny}, {pt.x + nx, pt.y + ny}, {pt.x - nx, pt.y - ny}, {a.x - nx, a.y - ny}})
+	}
+	p.fill(polygons, c)
+}
+
+func (p *pngPen) disc(centre point, r float64, c color.RGBA) {
+	p.polygon(circlePoints(centre, r), c)
+}
+
+func (p *pngPen) circle(centre point, r float64, width float64, c color.RGBA) {
+	points := circlePoints(centre, r)
+	p.polyline(append(points, points[0]), width, c, false)
+}
+
+func (p *pngPen) text(centre point, text string, size float64, bold bool, c color.RGBA) {
+	face, ok := p.faces[pngFace{bold, size}]
+	if !ok {
+		panic(fmt.Sprintf("sudoku: no face for text %v cells high", size))
+	}
+	// the middle of the glyphs' bounds goes on centre
+	bounds, _ := font.BoundString(face, text)
+	x, y := p.pixel(centre)
+	d := font.Drawer{Dst: p.img, Src: image.NewUniform(c), Face: face}
+	d.Dot = fixed.Point26_6{
+		X: fixed.Int26_6(x*64) - (bounds.Min.X+bounds.Max.X)/2,
+		Y: fixed.Int26_6(y*64) - (bounds.Min.Y+bounds.Max.Y)/2,
+	}
+	d.DrawString(text)
+}
+
+//circlePoints returns the points of a polygon close to the circle, turning the same way as the rectangles of polyline
+func circlePoints(centre point, r float64) []point {
+	const sides = 32
+	points := make([]point, sides)
+	for i := range points {
+		angle := -2 * math.Pi * float64(i) / sides
+		points[i] = point{centre.x + r*math.Cos(angle), centre.y + r*math.Sin(angle)}
+	}
+	return points
+}
@@ -0,0 +1,218 @@
+package sudoku
+
+import (
+	"strings"
+)
+
+// RenderOptions configures how Render draws a puzzle as text
+type RenderOptions struct {
+	ASCII      bool        // Draw lines with - | + between boxes and = # around sub-sudokus, rather than box-drawing characters
+	Colour     bool        // Colour the digits of every sub-sudoku with ANSI escape codes, those of the overlaps in magenta
+	Givens     Grid        // Grid the puzzle was given as, whose digits are drawn bold and the others faint, with ANSI escape codes
+	Candidates *Candidates // Candidates of the puzzle, drawn in every empty cell as a mini-grid shaped like a box
+}
+
+// Characters lines are drawn with, for the corners by the sides they go to: 1 up, 2 right, 4 down and 8 left
+type lineChars struct {
+	horizontal, vertical [GridBorder + 1]string
+	light, heavy         [16]string
+}
+
+var unicodeLines = lineChars{
+	horizontal: [...]string{" ", " ", "─", "━"},
+	vertical:   [...]string{" ", " ", "│", "┃"},
+	light:      [...]string{" ", "╵", "╶", "└", "╷", "│", "┌", "├", "╴", "┘", "─", "┴", "┐", "┤", "┬", "┼"},
+	heavy:      [...]string{" ", "╹", "╺", "┗", "╻", "┃", "┏", "┣", "╸", "┛", "━", "┻", "┓", "┫", "┳", "╋"},
+}
+
+var asciiLines = lineChars{
+	horizontal: [...]string{" ", " ", "-", "="},
+	vertical:   [...]string{" ", " ", "|", "#"},
+	light:      [...]string{" ", "|", "-", "+", "|", "|", "+", "+", "-", "+", "-", "+", "+", "+", "+", "+"},
+	heavy:      [...]string{" ", "#", "=", "+", "#", "#", "+", "+", "=", "+", "=", "+", "+", "+", "+", "+"},
+}
+
+// Colours of the digits of the sub-sudokus, in the order of the layout's SubGrids, and of the overlaps between them
+var (
+	positionColours = []string{"31", "32", "33", "34", "36", "91", "92", "93", "94", "96"}
+	overlapColour   = "35"
+)
+
+//Render draws grid as a samurai sudoku, see SamuraiSudoku.Render. Grids not shaped like one are written as String does
+func (g Grid) Render(options RenderOptions) string {
+	var samurai SamuraiSudoku
+	samurai.SetGrid(g)
+	return samurai.Render(options)
+}
+
+//Render draws the puzzle's grid as text, with lines between its boxes, or jigsaw regions, and heavier ones around its
+//sub-sudokus, so that the cells shared by overlapping sub-sudokus are framed. Empty cells are dots and gaps are left blank.
+//Grids not shaped like the puzzle's layout are written as Layout.Format does
+func (s *SamuraiSudoku) Render(options RenderOptions) string {
+	layout := s.Layout()
+	grid := s.Grid()
+	if checkShape(grid, layout) != nil {
+		return layout.Format(grid)
+	}
+	r := renderer{options: options, layout: layout, grid: grid, borders: s.Borders(), chars: unicodeLines, height: 1, width: 1}
+	if options.ASCII {
+		r.chars = asciiLines
+	}
+	if options.Candidates != nil {
+		r.height, r.width = layout.box.Rows, layout.box.Columns
+	}
+	return r.render()
+}
+
+// renderer draws a grid for Render, cells being height lines high and width characters wide
+type renderer struct {
+	options       RenderOptions
+	layout        *Layout
+	grid          Grid
+	borders       Borders
+	chars         lineChars
+	height, width int
+}
+
+func (r *renderer) render() string {
+	rows, _ := r.layout.Size()
+	// lines are only given room where the canvas has some
+	hasLine, hasColumn := r.borders.Lines()
+
+	var b strings.Builder
+	writeLine := func(text string) {
+		b.WriteString(strings.TrimRight(text, " "))
+		b.WriteString("\n")
+	}
+	for y := 0; y <= rows; y++ {
+		switch {
+		case hasLine[y]:
+			writeLine(r.rule(y, hasColumn))
+		case y > 0 && y < rows && r.height > 1:
+			// a blank line between rows of mini-grids, crossed by the lines between columns
+			writeLine(r.row(y-1, -1, hasColumn))
+		}
+		if y == rows {
+			break
+		}
+		for line := 0; line < r.height; line++ {
+			writeLine(r.row(y, line, hasColumn))
+		}
+	}
+	return b.String()
+}
+
+//rule returns the line drawn above row y
+func (r *renderer) rule(y int, hasColumn []bool) string {
+	columns := len(hasColumn) - 1
+	var b strings.Builder
+	for x := 0; x <= columns; x++ {
+		if hasColumn[x] {
+			if x > 0 {
+				b.WriteString(r.chars.horizontal[r.borders.Top[y][x-1]])
+			}
+			b.WriteString(r.corner(y, x))
+		}
+		if x < columns {
+			b.WriteString(strings.Repeat(r.chars.horizontal[r.borders.Top[y][x]], r.width+1))
+		}
+	}
+	return b.String()
+}
+
+//corner returns the character drawn where the lines above row y and left of column x meet, heavy if any of them is
+func (r *renderer) corner(y int, x int) string {
+	mask, heavy := r.borders.Corner(y, x)
+	if heavy {
+		return r.chars.heavy[mask]
+	}
+	return r.chars.light[mask]
+}
+
+//row returns the given line of the cells of row y, blank cells for line -1
+func (r *renderer) row(y int, line int, hasColumn []bool) string {
+	columns := len(hasColumn) - 1
+	var b strings.Builder
+	for x := 0; x <= columns; x++ {
+		if hasColumn[x] {
+			if x > 0 {
+				b.WriteString(" ")
+			}
+			b.WriteString(r.chars.vertical[r.borders.Left[y][x]])
+		}
+		if x == columns {
+			break
+		}
+		b.WriteString(" ")
+		if line < 0 {
+			b.WriteString(strings.Repeat(" ", r.width))
+		} else {
+			b.WriteString(r.cell(y, x, line))
+		}
+	}
+	return b.String()
+}
+
+//cell returns the given line of cell y,x
+func (r *renderer) cell(y int, x int, line int) string {
+	num := r.grid[y][x]
+	symbols := r.layout.symbols
+	if num == -1 {
+		return strings.Repeat(" ", r.width)
+	}
+	if r.options.Candidates == nil {
+		if num == 0 {
+			return "."
+		}
+		return r.digit(y, x, symbol(symbols, num))
+	}
+
+	var b strings.Builder
+	for column := 0; column < r.width; column++ {
+		n := line*r.width + column + 1
+		switch {
+		case num > 0 && line == r.height/2 && column == r.width/2:
+			b.WriteString(r.digit(y, x, symbol(symbols, num)))
+		case num > 0:
+			b.WriteString(" ")
+		case r.options.Candidates.Has(Cell{y, x}, n):
+			b.WriteString(symbol(symbols, n))
+		default:
+			b.WriteString(".")
+		}
+	}
+	return b.String()
+}
+
+//digit returns the digit of cell y,x written as text, styled as the options ask
+func (r *renderer) digit(y int, x int, text string) string {
+	var codes []string
+	if r.options.Givens != nil {
+		if given := r.options.Givens; y < len(given) && x < len(given[y]) && given[y][x] > 0 {
+			codes = append(codes, "1")
+		} else {
+			codes = append(codes, "2")
+		}
+	}
+	if r.options.Colour {
+		codes = append(codes, r.colour(y, x))
+	}
+	if codes == nil {
+		return text
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m" + text + "\x1b[0m"
+}
+
+//colour returns the colour of the digits of the sub-sudoku cell y,x is in, or of the overlaps if it is in several
+func (r *renderer) colour(y int, x int) string {
+	placements := r.layout.placements(y, x)
+	if len(placements) > 1 {
+		return overlapColour
+	}
+	for i, g := range r.layout.grids {
+		if g.Position == placements[0].position {
+			return positionColours[i%len(positionColours)]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package sudoku
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	twin4, err := ParseLayout("twin-4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var samurai SamuraiSudoku
+	samurai.SetLayout(twin4)
+	grid := twin4.NewGrid()
+	grid[0][0], grid[3][3] = 1, 4
+	samurai.SetGrid(grid)
+
+	want := strings.Join([]string{
+		"+=====+=====+",
+		"# 1 . | . . #",
+		"# . . | . . #",
+		"+-----+=====+=====+",
+		"# . . # . . # . . #",
+		"# . . # . 4 # . . #",
+		"+=====+=====+-----+",
+		"      # . . | . . #",
+		"      # . . | . . #",
+		"      +=====+=====+",
+		"",
+	}, "\n")
+	if got := samurai.Render(RenderOptions{ASCII: true}); got != want {
+		t.Fatalf("want:\n%s\ngot:\n%s", want, got)
+	}
+
+	lines := strings.Split(SamuraiGridFromFile("sudoku.txt").Render(RenderOptions{}), "\n")
+	if len(lines) != 21+8+1 {
+		t.Fatalf("want 21 rows and 8 lines between them, got %d lines", len(lines)-1)
+	}
+	for i, want := range []string{"┏━━━━━━━┳━━━━━━━┳━━━━━━━┓       ┏━━━━━━━┳━━━━━━━┳━━━━━━━┓", "┃ . . 5 │ 7 . . │ . 2 . ┃       ┃ . . 9 │ 6 . . │ . 2 . ┃"} {
+		if lines[i] != want {
+			t.Fatalf("want line %d to be %q, got %q", i+1, want, lines[i])
+		}
+	}
+	// the overlap of the top left and centre sub-sudokus is framed by heavy lines
+	if want := "┣───────┼───────╋━━━━━━━╋━━━━━━━╋━━━━━━━╋───────┼───────┫"; lines[8] != want {
+		t.Fatalf("want line 9 to be %q, got %q", want, lines[8])
+	}
+}
+
+func TestRenderCandidates(t *testing.T) {
+	twin4, err := ParseLayout("twin-4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var samurai SamuraiSudoku
+	samurai.SetLayout(twin4)
+	grid := twin4.NewGrid()
+	grid[0][0] = 1
+	samurai.SetGrid(grid)
+	candidates, err := NewCandidates(&samurai)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(samurai.Render(RenderOptions{ASCII: true, Candidates: candidates}), "\n")
+	// the filled cell holds its digit in its middle, a blank line crossed by the lines between boxes parts rows of cells
+	for i, want := range []string{"+=======+=======+", "#    .2 | .2 .2 #", "#  1 34 | 34 34 #", "#       |       #", "# .2 .2 | 12 12 #"} {
+		if lines[i] != want {
+			t.Fatalf("want line %d to be %q, got %q", i+1, want, lines[i])
+		}
+	}
+}
+
+func TestRenderStyles(t *testing.T) {
+	puzzle := newTestSamurai()
+	solution, err := GlobalSolveSamuraiSudokuContext(context.Background(), newTestSamurai())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := solution.Render(RenderOptions{Colour: true, Givens: puzzle.Grid()})
+	for _, want := range []string{
+		"┃ \x1b[2;31m1\x1b[0m \x1b[2;31m6\x1b[0m \x1b[1;31m5\x1b[0m │", // the top left sub-sudoku in red, its givens bold and the others faint
+		"\x1b[1;32m9\x1b[0m",   // the top right sub-sudoku in green
+		"┃ \x1b[1;35m7\x1b[0m", // the overlaps in magenta, from r7c7
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("want %q in:\n%s", want, got)
+		}
+	}
+	if plain := solution.Render(RenderOptions{}); strings.Contains(plain, "\x1b") {
+		t.Fatalf("want no escape codes unless asked")
+	}
+}
+
+func TestRenderMalformed(t *testing.T) {
+	grid := Grid{{1, 2}, {2, 1}}
+	if got := grid.Render(RenderOptions{}); got != grid.String() {
+		t.Fatalf("want a malformed grid written as String does, got:\n%s", got)
+	}
+}
@@ -0,0 +1,160 @@
+package sudoku
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Restriction limits the digits some cells of the whole grid may hold, such as the even and odd cells of parity sudoku
+type Restriction struct {
+	Cells  []Cell `json:"cells"`
+	Digits []int  `json:"digits"` // Digits the cells may hold
+}
+
+// RestrictionError is returned by Validate for a cell holding a digit its restriction doesn't allow
+type RestrictionError struct {
+	Index int // Index of the restriction in the Variant
+	Cell  Cell
+	Num   int
+}
+
+func (e *RestrictionError) Error() string {
+	return fmt.Sprintf("sudoku: %s holds %d, which restriction %d doesn't allow", formatCell(e.Cell), e.Num, e.Index+1)
+}
+
+//EvenCells returns a restriction of cells to the even digits from 1 to digits
+func EvenCells(digits int, cells ...Cell) Restriction {
+	return parityCells(2, digits, cells)
+}
+
+//OddCells returns a restriction of cells to the odd digits from 1 to digits
+func OddCells(digits int, cells ...Cell) Restriction {
+	return parityCells(1, digits, cells)
+}
+
+func parityCells(first int, digits int, cells []Cell) Restriction {
+	r := Restriction{Cells: cells}
+	for n := first; n <= digits; n += 2 {
+		r.Digits = append(r.Digits, n)
+	}
+	return r
+}
+
+//mask returns the digits the restriction allows, as a bitmask
+func (r Restriction) mask() uint32 {
+	return digitMask(r.Digits)
+}
+
+//checkRestrictions returns an error wrapping ErrInvalidLayout if a restriction has no cell, a cell outside layout's sub-sudokus,
+//no digit, or a digit the layout's sub-sudokus don't hold
+func checkRestrictions(restrictions []Restriction, layout *Layout) error {
+	for i, r := range restrictions {
+		if len(r.Cells) == 0 || len(r.Digits) == 0 {
+			return fmt.Errorf("%w: restriction %d has no cells or no digits", ErrInvalidLayout, i+1)
+		}
+		for _, c := range r.Cells {
+			if !layout.Contains(c.Row, c.Column) {
+				return fmt.Errorf("%w: cell (%d, %d) of restriction %d isn't in a sub-sudoku of %s", ErrInvalidLayout, c.Row+1, c.Column+1, i+1, layout)
+			}
+		}
+		for _, n := range r.Digits {
+			if n < 1 || n > layout.size {
+				return fmt.Errorf("%w: digit %d of restriction %d isn't between 1 and %d", ErrInvalidLayout, n, i+1, layout.size)
+			}
+		}
+	}
+	return nil
+}
+
+//restrictionMasks returns the digits every restricted cell may hold, as a bitmask, cells in several restrictions holding
+//only the digits all of them allow
+func restrictionMasks(restrictions []Restriction) map[Cell]uint32 {
+	masks := make(map[Cell]uint32)
+	for _, r := range restrictions {
+		mask := r.mask()
+		for _, c := range r.Cells {
+			if m, ok := masks[c]; ok {
+				masks[c] = m & mask
+			} else {
+				masks[c] = mask
+			}
+		}
+	}
+	return masks
+}
+
+//validateRestrictions checks that the filled cells of grid hold digits their restrictions allow.
+//It returns a *RestrictionError for the first cell that doesn't
+func validateRestrictions(grid Grid, restrictions []Restriction) error {
+	for i, r := range restrictions {
+		mask := r.mask()
+		for _, c := range r.Cells {
+			if num := grid[c.Row][c.Column]; num > 0 && mask&(1<<num) == 0 {
+				return &RestrictionError{Index: i, Cell: c, Num: num}
+			}
+		}
+	}
+	return nil
+}
+
+//possibleRestrictions checks if the canvas cell at row y and column x can hold n, given the digits restricted cells may hold in masks
+func possibleRestrictions(y int, x int, n int, masks map[Cell]uint32) bool {
+	mask, ok := masks[Cell{y, x}]
+	return !ok || mask&(1<<n) != 0
+}
+
+//formatRestriction writes a restriction for a header line in sub-sudokus holding digits from 1 to digits, written with symbols:
+//"even: " or "odd: " followed by its cells for the even or odd digits, "digits: " followed by a word of its digits and its cells
+//otherwise, e.g. "digits: 123 r1c1 r1c2"
+func formatRestriction(r Restriction, symbols string) string {
+	digits := len(symbols)
+	for _, parity := range []Restriction{EvenCells(digits), OddCells(digits)} {
+		if r.mask() == parity.mask() {
+			name := "even"
+			if parity.Digits[0] == 1 {
+				name = "odd"
+			}
+			return name + ": " + formatCells(r.Cells)
+		}
+	}
+	var word strings.Builder
+	for n := 1; n <= digits; n++ {
+		if r.mask()&(1<<n) != 0 {
+			word.WriteString(symbol(symbols, n))
+		}
+	}
+	return "digits: " + word.String() + " " + formatCells(r.Cells)
+}
+
+//parseRestriction parses the value of a header line for the restriction named key, even, odd or digits,
+//in sub-sudokus holding digits from 1 to digits written with symbols
+func parseRestriction(key string, value string, symbols string) (Restriction, error) {
+	var r Restriction
+	switch key {
+	case "even":
+		r = EvenCells(len(symbols))
+	case "odd":
+		r = OddCells(len(symbols))
+	case "digits":
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			return Restriction{}, fmt.Errorf("restriction %q has no digits", value)
+		}
+		for _, char := range fields[0] {
+			n, ok := parseSymbol(symbols, char)
+			if !ok {
+				return Restriction{}, fmt.Errorf("bad digit %q in restriction %q", char, value)
+			}
+			r.Digits = append(r.Digits, n)
+		}
+		value = strings.Join(fields[1:], " ")
+	default:
+		return Restriction{}, fmt.Errorf("unknown restriction %q", key)
+	}
+	cells, err := parseCells(value)
+	if err != nil {
+		return Restriction{}, err
+	}
+	r.Cells = cells
+	return r, nil
+}
@@ -0,0 +1,145 @@
+package sudoku
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParityCells(t *testing.T) {
+	if got := EvenCells(9, Cell{0, 0}).Digits; !reflect.DeepEqual(got, []int{2, 4, 6, 8}) {
+		t.Fatalf("want the even digits up to 9, got %v", got)
+	}
+	if got := OddCells(6, Cell{0, 0}).Digits; !reflect.DeepEqual(got, []int{1, 3, 5}) {
+		t.Fatalf("want the odd digits up to 6, got %v", got)
+	}
+}
+
+//parityPuzzle sudoku.txt with the middle left box of the Centre sub-sudoku cleared. Cells of its first and last rows are
+//marked even or odd, those of the row between them may hold their digit or the one after it
+func parityPuzzle(t *testing.T) (Grid, *Variant) {
+	puzzle, solution := centreRowsPuzzle(t, 9)
+	even, odd := EvenCells(9), OddCells(9)
+	variant := &Variant{}
+	for y := 9; y < 12; y++ {
+		for x := 6; x < 9; x++ {
+			switch num := solution[y][x]; {
+			case y == 10:
+				variant.Restrictions = append(variant.Restrictions, Restriction{Cells: []Cell{{y, x}}, Digits: []int{num, num%9 + 1}})
+			case num%2 == 0:
+				even.Cells = append(even.Cells, Cell{y, x})
+			default:
+				odd.Cells = append(odd.Cells, Cell{y, x})
+			}
+		}
+	}
+	variant.Restrictions = append(variant.Restrictions, even, odd)
+	return puzzle, variant
+}
+
+func TestRestrictions(t *testing.T) {
+	puzzle, variant := parityPuzzle(t)
+	checkVariantSolves(t, puzzle, variant, nil)
+}
+
+func TestRestrictionValidation(t *testing.T) {
+	grid := NewSamuraiGrid()
+	grid[0][0] = 3
+	variant := &Variant{Restrictions: []Restriction{
+		{Cells: []Cell{{0, 1}, {0, 2}}, Digits: []int{1, 2, 3}},
+		EvenCells(9, Cell{0, 2}),
+	}}
+
+	var samurai SamuraiSudoku
+	if err := samurai.SetVariant(variant); err != nil {
+		t.Fatal(err)
+	}
+	samurai.SetGrid(grid)
+	if !possible(samurai.GetSubSudoku(TopLeft), 0, 1, 1, TopLeft, &samurai) {
+		t.Fatalf("want 1 possible in a cell holding 1, 2 or 3")
+	}
+	if possible(samurai.GetSubSudoku(TopLeft), 0, 1, 4, TopLeft, &samurai) {
+		t.Fatalf("want 4 impossible in a cell holding 1, 2 or 3")
+	}
+	if possible(samurai.GetSubSudoku(TopLeft), 0, 2, 1, TopLeft, &samurai) {
+		t.Fatalf("want 1 impossible in a cell holding 1, 2 or 3 and even")
+	}
+
+	grid[0][2] = 1
+	var restrictionErr *RestrictionError
+	if err := samurai.Validate(); !errors.As(err, &restrictionErr) || *restrictionErr != (RestrictionError{Index: 1, Cell: Cell{0, 2}, Num: 1}) {
+		t.Fatalf("want 1 in an even cell, got %v", err)
+	}
+	if n, err := samurai.CountSolutions(context.Background(), 1); !errors.Is(err, ErrUnsolvable) || n != 0 {
+		t.Fatalf("want no solution and %v, got %d, %v", ErrUnsolvable, n, err)
+	}
+}
+
+func TestRestrictionErrors(t *testing.T) {
+	testCases := []struct {
+		name        string
+		restriction Restriction
+	}{
+		{"no cells", Restriction{Digits: []int{1}}},
+		{"no digits", Restriction{Cells: []Cell{{0, 0}}}},
+		{"cell in a gap", EvenCells(9, Cell{0, 9})},
+		{"digit out of range", Restriction{Cells: []Cell{{0, 0}}, Digits: []int{10}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var samurai SamuraiSudoku
+			if err := samurai.SetVariant(&Variant{Restrictions: []Restriction{tc.restriction}}); !errors.Is(err, ErrInvalidLayout) {
+				t.Fatalf("want ErrInvalidLayout, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseRestriction(t *testing.T) {
+	hex := symbolsFor(16)
+	testCases := []struct {
+		restriction Restriction
+		symbols     string
+		line        string
+	}{
+		{EvenCells(9, Cell{0, 0}, Cell{1, 1}), digitSymbols, "even: r1c1 r2c2"},
+		{OddCells(9, Cell{0, 0}), digitSymbols, "odd: r1c1"},
+		{Restriction{Cells: []Cell{{2, 3}}, Digits: []int{1, 5, 9}}, digitSymbols, "digits: 159 r3c4"},
+		{EvenCells(16, Cell{0, 0}), hex, "even: r1c1"},
+		{Restriction{Cells: []Cell{{0, 0}}, Digits: []int{9, 10, 16}}, hex, "digits: 9AG r1c1"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.line, func(t *testing.T) {
+			if got := formatRestriction(tc.restriction, tc.symbols); got != tc.line {
+				t.Fatalf("want %q, got %q", tc.line, got)
+			}
+			parts := strings.SplitN(tc.line, ":", 2)
+			if got, err := parseRestriction(parts[0], parts[1], tc.symbols); err != nil || !reflect.DeepEqual(got, tc.restriction) {
+				t.Fatalf("want %+v, got %+v, %v", tc.restriction, got, err)
+			}
+		})
+	}
+	for _, value := range []string{"", "1x3 r1c1", "13 r1c1 x"} {
+		if _, err := parseRestriction("digits", value, digitSymbols); err == nil {
+			t.Fatalf("%q: want an error", value)
+		}
+	}
+}
+
+func TestWriteImageRestrictions(t *testing.T) {
+	samurai := emptyTestSamurai()
+	if err := samurai.SetVariant(&Variant{Restrictions: []Restriction{EvenCells(9, Cell{0, 0}), OddCells(9, Cell{0, 1})}}); err != nil {
+		t.Fatal(err)
+	}
+	img := pictureImage(t, samurai)
+
+	// the even cell's square reaches its inner corners, the odd cell's disc doesn't
+	if !hasColour(img, 0.27, 0.27, 0.28, 0.28, imageShapeColour) || !hasColour(img, 0.5, 0.5, 0.5, 0.5, imageShapeColour) {
+		t.Fatalf("want a square in the even cell")
+	}
+	if hasColour(img, 0.27, 1.27, 0.28, 1.28, imageShapeColour) || !hasColour(img, 0.5, 1.5, 0.5, 1.5, imageShapeColour) {
+		t.Fatalf("want a disc in the odd cell")
+	}
+}
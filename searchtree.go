@@ -0,0 +1,131 @@
+package sudoku
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// SearchNode A single cell assignment tried by the backtracking solver
+type SearchNode struct {
+	Position Position // Position of the sudoku the assignment was done in
+	Row      int
+	Column   int
+	Value    int
+	Success  bool // Whether the assignment is part of the solution, false if it was retracted
+	Depth    int  // Number of assignments above this one, the root has depth 0
+	Children []*SearchNode
+
+	id int
+}
+
+//succeed marks the assignment as part of the solution, nodes that weren't recorded are ignored
+func (n *SearchNode) succeed() {
+	if n != nil {
+		n.Success = true
+	}
+}
+
+// SearchTreeOptions limits how much of the search is recorded, zero values mean no limit
+type SearchTreeOptions struct {
+	MaxDepth int // Assignments deeper than MaxDepth are not recorded
+	MaxNodes int // Assignments are no longer recorded once the tree holds MaxNodes nodes
+}
+
+// SearchTree The tree of assignments tried by the backtracking solver.
+// Every sub-sudoku searched hangs its first assignments off Root, which holds no assignment itself
+type SearchTree struct {
+	Root      *SearchNode
+	Truncated bool // Whether some assignments weren't recorded because of the options' limits
+
+	options SearchTreeOptions
+	nodes   int
+}
+
+//RecordSearchTree makes the backtracking solvers build their search tree from now on, within options' limits
+func (s *SamuraiSudoku) RecordSearchTree(options SearchTreeOptions) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.searchTree = &SearchTree{options: options}
+	s.searchTree.reset()
+}
+
+//SearchTree returns the search tree of the last solving attempt, nil if RecordSearchTree wasn't called
+func (s *SamuraiSudoku) SearchTree() *SearchTree {
+	return s.searchTree
+}
+
+func (s *SamuraiSudoku) searchRoot() *SearchNode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.searchTree == nil {
+		return nil
+	}
+	return s.searchTree.Root
+}
+
+//recordSearchNode adds an assignment under parent, should be called while holding s.mu.
+//It returns nil when the tree isn't being recorded or the assignment is beyond the limits
+func (s *SamuraiSudoku) recordSearchNode(parent *SearchNode, position Position, y int, x int, n int) *SearchNode {
+	t := s.searchTree
+	if t == nil || parent == nil {
+		return nil
+	}
+	if (t.options.MaxDepth > 0 && parent.Depth >= t.options.MaxDepth) ||
+		(t.options.MaxNodes > 0 && t.nodes >= t.options.MaxNodes) {
+		t.Truncated = true
+		return nil
+	}
+	node := &SearchNode{
+		Position: position,
+		Row:      y,
+		Column:   x,
+		Value:    n,
+		Depth:    parent.Depth + 1,
+		id:       t.nodes,
+	}
+	t.nodes++
+	parent.Children = append(parent.Children, node)
+	return node
+}
+
+func (t *SearchTree) reset() {
+	if t == nil {
+		return
+	}
+	t.Root = &SearchNode{}
+	t.Truncated = false
+	t.nodes = 1
+}
+
+//Len returns the number of nodes in the tree, including the root
+func (t *SearchTree) Len() int {
+	return t.nodes
+}
+
+//WriteDOT writes the tree to w in Graphviz DOT format, successful assignments are drawn in green
+func (t *SearchTree) WriteDOT(w io.Writer) error {
+	buf := bytes.Buffer{}
+	fmt.Fprintf(&buf, "digraph search {\n")
+	fmt.Fprintf(&buf, "\tnode [shape=box, fontname=\"Helvetica\"];\n")
+	fmt.Fprintf(&buf, "\tn0 [label=\"start\", shape=ellipse];\n")
+
+	var walk func(node *SearchNode)
+	walk = func(node *SearchNode) {
+		for _, child := range node.Children {
+			colour := "red"
+			if child.Success {
+				colour = "darkgreen"
+			}
+			fmt.Fprintf(&buf, "\tn%d [label=\"%s\\n(%d, %d) = %d\", color=%s];\n",
+				child.id, child.Position, child.Row, child.Column, child.Value, colour)
+			fmt.Fprintf(&buf, "\tn%d -> n%d;\n", node.id, child.id)
+			walk(child)
+		}
+	}
+	walk(t.Root)
+
+	fmt.Fprintf(&buf, "}\n")
+	_, err := buf.WriteTo(w)
+	return err
+}
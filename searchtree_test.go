@@ -0,0 +1,99 @@
+package sudoku
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRecordSearchTree(t *testing.T) {
+	samurai := newTestSamurai()
+	empty := 0
+	for _, row := range samurai.Grid() {
+		for _, num := range row {
+			if num == 0 {
+				empty++
+			}
+		}
+	}
+
+	samurai.RecordSearchTree(SearchTreeOptions{})
+	SolveSamuraiSudoku(samurai)
+	tree := samurai.SearchTree()
+
+	successes := 0
+	var walk func(node *SearchNode)
+	walk = func(node *SearchNode) {
+		for _, child := range node.Children {
+			if child.Depth != node.Depth+1 {
+				t.Fatalf("want depth %d, got %d", node.Depth+1, child.Depth)
+			}
+			if child.Success {
+				successes++
+			}
+			walk(child)
+		}
+	}
+	walk(tree.Root)
+
+	if successes != empty {
+		t.Fatalf("want %d successful assignments, got %d", empty, successes)
+	}
+	if tree.Truncated {
+		t.Fatalf("unlimited tree shouldn't be truncated")
+	}
+}
+
+func TestRecordSearchTreeLimits(t *testing.T) {
+	testCases := []struct {
+		name    string
+		options SearchTreeOptions
+	}{
+		{"max depth", SearchTreeOptions{MaxDepth: 3}},
+		{"max nodes", SearchTreeOptions{MaxNodes: 50}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			samurai := newTestSamurai()
+			samurai.RecordSearchTree(tc.options)
+			SolveSamuraiSudoku(samurai)
+			tree := samurai.SearchTree()
+
+			if !tree.Truncated {
+				t.Fatalf("want truncated tree")
+			}
+			if tc.options.MaxNodes > 0 && tree.Len() != tc.options.MaxNodes {
+				t.Fatalf("want %d nodes, got %d", tc.options.MaxNodes, tree.Len())
+			}
+			var walk func(node *SearchNode)
+			walk = func(node *SearchNode) {
+				if tc.options.MaxDepth > 0 && node.Depth > tc.options.MaxDepth {
+					t.Fatalf("node at depth %d beyond max depth %d", node.Depth, tc.options.MaxDepth)
+				}
+				for _, child := range node.Children {
+					walk(child)
+				}
+			}
+			walk(tree.Root)
+		})
+	}
+}
+
+func TestSearchTree_WriteDOT(t *testing.T) {
+	samurai := newTestSamurai()
+	samurai.RecordSearchTree(SearchTreeOptions{MaxNodes: 10})
+	SolveSamuraiSudoku(samurai)
+
+	var buf bytes.Buffer
+	if err := samurai.SearchTree().WriteDOT(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dot := buf.String()
+	if !strings.HasPrefix(dot, "digraph search {") || !strings.HasSuffix(dot, "}\n") {
+		t.Fatalf("not a DOT digraph:\n%s", dot)
+	}
+	if got := strings.Count(dot, "->"); got != 9 {
+		t.Fatalf("want 9 edges, got %d in\n%s", got, dot)
+	}
+}
@@ -0,0 +1,481 @@
+// Package server serves samurai sudoku solving over HTTP, exchanging grids as JSON.
+//
+// Every endpoint takes a POST request with a JSON body and answers with JSON:
+//
+//	POST /solve     {"grid": [[...]], "solver": "global"} -> {"grid": [[...]]}
+//	POST /validate  {"grid": [[...]]}                     -> {"valid": true, "solutions": 1}
+//	POST /hint      {"grid": [[...]]}                     -> {"technique": "naked single", "row": 0, "column": 0, "value": 1, ...}
+//	POST /generate  {"seed": 1, "minClues": 0}            -> {"grid": [[...]]}
+//
+// Grids are arrays of 21 rows of 21 cells, with -1 for the gaps between sub-sudokus and 0 for empty cells.
+// Other layouts than samurai, such as "twin" or "windmill", are picked with a "layout" field next to the grid or seed,
+// and larger or smaller sub-sudokus with a size after the layout's name, such as "samurai-16" for digits 1 to 16.
+// Sub-sudokus whose diagonals must hold every digit once are listed in a "diagonals" field, e.g. ["centre"],
+// those whose windows must hold every digit once, as in hyper sudoku, in a "windows" field,
+// killer cages in a "cages" field, e.g. [{"sum": 15, "cells": [{"row": 0, "column": 0}, {"row": 0, "column": 1}]}],
+// sub-sudokus with irregular regions in place of boxes in a "jigsaw" field, e.g. [{"position": "centre", "regions": [[1, 1, ...], ...]}],
+// regions being numbered from 1 cell by cell, Kropki dots and XV sums between adjacent cells in a "markers" field,
+// e.g. [{"kind": "white", "cells": [{"row": 0, "column": 0}, {"row": 0, "column": 1}]}], with "black", "x" and "v" as other kinds,
+// the kinds of markers given wherever their relation holds in a "negative" field, e.g. ["white", "black"],
+// thermometers, arrows and German whispers lines in "thermos", "arrows" and "whispers" fields, each line a list of cells
+// from a thermometer's bulb or an arrow's circle, e.g. [[{"row": 0, "column": 0}, {"row": 1, "column": 1}]],
+// and cells that may only hold some digits, such as even or odd cells, in a "restrictions" field,
+// e.g. [{"cells": [{"row": 0, "column": 0}], "digits": [2, 4, 6, 8]}].
+// Hints are the simplest logical deduction left: the cell a digit goes in for naked and hidden singles,
+// the cells it or the "digits" of pairs and triples can't go in, as "eliminations", for the other techniques,
+// with the sub-sudoku and unit it was found in and an "explanation" in English.
+// Failed requests are answered with {"error": {"code": "...", "message": "..."}}.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"time"
+
+	sudoku "github.com/alielbashir/samurai-sudoku-go"
+)
+
+// Options configures the handler returned by New
+type Options struct {
+	Timeout       time.Duration // Longest time spent on a request, 10 seconds by default
+	MaxConcurrent int           // Most requests worked on at once, the number of CPUs by default
+	MaxBodyBytes  int64         // Largest request body accepted, 1MB by default
+}
+
+const (
+	defaultTimeout      = 10 * time.Second
+	defaultMaxBodyBytes = 1 << 20
+)
+
+// statusClientClosedRequest is the status nginx logs requests whose client went away with, as no standard one fits
+const statusClientClosedRequest = 499
+
+// Error codes
+const (
+	CodeBadRequest       = "bad_request"        // The body isn't valid JSON, or misses fields
+	CodeInvalidGrid      = "invalid_grid"       // The grid isn't shaped like its layout
+	CodeConflict         = "conflict"           // The grid breaks the sudoku rules, those of the variant included
+	CodeUnsolvable       = "unsolvable"         // The grid has no solution
+	CodeNotUnique        = "not_unique"         // The grid has more than one solution
+	CodeUnknownSolver    = "unknown_solver"     // The solver asked for doesn't exist
+	CodeUnknownLayout    = "unknown_layout"     // The layout asked for doesn't exist
+	CodeTimeout          = "timeout"            // The request took longer than the timeout
+	CodeCanceled         = "canceled"           // The client went away before the request was answered
+	CodeBusy             = "busy"               // Too many requests are being worked on
+	CodeNotFound         = "not_found"          // No endpoint at this path
+	CodeMethodNotAllowed = "method_not_allowed" // Endpoints only accept POST
+	CodeSolved           = "solved"             // The grid has no empty cell to give a hint for
+	CodeNoHint           = "no_hint"            // No simple deduction makes progress on the grid
+	CodeInternal         = "internal"           // Something unexpected went wrong
+)
+
+type handler struct {
+	options Options
+	slots   chan struct{} // Holds a value per request being worked on
+	mux     *http.ServeMux
+}
+
+//New returns a handler serving the solving endpoints
+func New(options Options) http.Handler {
+	if options.Timeout <= 0 {
+		options.Timeout = defaultTimeout
+	}
+	if options.MaxConcurrent <= 0 {
+		options.MaxConcurrent = runtime.NumCPU()
+	}
+	if options.MaxBodyBytes <= 0 {
+		options.MaxBodyBytes = defaultMaxBodyBytes
+	}
+
+	h := &handler{
+		options: options,
+		slots:   make(chan struct{}, options.MaxConcurrent),
+		mux:     http.NewServeMux(),
+	}
+	h.handle("/solve", h.solve)
+	h.handle("/validate", h.validate)
+	h.handle("/hint", h.hint)
+	h.handle("/generate", h.generate)
+	h.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, &apiError{http.StatusNotFound, CodeNotFound, fmt.Sprintf("no endpoint at %s", r.URL.Path)})
+	})
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// endpoint handles a request within its timeout, returning the response body or an error
+type endpoint func(ctx context.Context, r *http.Request) (interface{}, error)
+
+//handle registers endpoint at path, making sure it only runs for POST requests, within the concurrency limit and timeout
+func (h *handler) handle(path string, endpoint endpoint) {
+	h.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeError(w, &apiError{http.StatusMethodNotAllowed, CodeMethodNotAllowed, "only POST is allowed"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), h.options.Timeout)
+		defer cancel()
+
+		select {
+		case h.slots <- struct{}{}:
+			defer func() { <-h.slots }()
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.Canceled) {
+				writeError(w, ctx.Err())
+				return
+			}
+			writeError(w, &apiError{http.StatusServiceUnavailable, CodeBusy, "too many requests are being worked on"})
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, h.options.MaxBodyBytes)
+		response, err := endpoint(ctx, r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, response)
+	})
+}
+
+type gridRequest struct {
+	Grid   sudoku.Grid `json:"grid"`
+	Solver string      `json:"solver,omitempty"`
+	Layout string      `json:"layout,omitempty"`
+	variantRequest
+}
+
+// variantRequest The rules a request adds to those of its layout's sub-sudokus, see sudoku.Variant
+type variantRequest struct {
+	Diagonals    []string             `json:"diagonals,omitempty"`
+	Windows      []string             `json:"windows,omitempty"`
+	Cages        []sudoku.Cage        `json:"cages,omitempty"`
+	Jigsaw       []jigsaw             `json:"jigsaw,omitempty"`
+	Markers      []sudoku.Marker      `json:"markers,omitempty"`
+	Negative     []sudoku.MarkerKind  `json:"negative,omitempty"`
+	Thermos      []sudoku.Thermo      `json:"thermos,omitempty"`
+	Arrows       []sudoku.Arrow       `json:"arrows,omitempty"`
+	Whispers     []sudoku.Whispers    `json:"whispers,omitempty"`
+	Restrictions []sudoku.Restriction `json:"restrictions,omitempty"`
+}
+
+// jigsaw The irregular regions of a sub-sudoku, numbered from 1 cell by cell
+type jigsaw struct {
+	Position string      `json:"position"`
+	Regions  sudoku.Grid `json:"regions"`
+}
+
+type gridResponse struct {
+	Grid sudoku.Grid `json:"grid"`
+}
+
+type validateResponse struct {
+	Valid     bool      `json:"valid"`
+	Solutions int       `json:"solutions"` // Number of solutions, counting up to 2
+	Error     *apiError `json:"error,omitempty"`
+}
+
+type hintResponse struct {
+	Technique    string        `json:"technique"`
+	Row          int           `json:"row"`    // Row of the cell Value goes in, for singles
+	Column       int           `json:"column"` // Column of the cell Value goes in, for singles
+	Value        int           `json:"value"`
+	Digits       []int         `json:"digits,omitempty"`       // Digits ruled out of Eliminations in place of Value, for pairs and triples
+	Eliminations []sudoku.Cell `json:"eliminations,omitempty"` // Cells Value or Digits can't go in, for techniques other than singles
+	Position     string        `json:"position"`
+	Unit         string        `json:"unit,omitempty"`
+	Index        int           `json:"index"`
+	Overlap      string        `json:"overlap,omitempty"` // Sub-sudoku overlapping Position the deduction relies on
+	Explanation  string        `json:"explanation"`
+}
+
+type generateRequest struct {
+	Seed     int64  `json:"seed"`
+	MinClues int    `json:"minClues"`
+	Layout   string `json:"layout,omitempty"`
+	variantRequest
+}
+
+//readGrid decodes a grid request and checks its grid is shaped like its layout and follows the rules
+func readGrid(r *http.Request) (gridRequest, *sudoku.SamuraiSudoku, error) {
+	request, samurai, err := readPuzzle(r)
+	if err != nil {
+		return request, nil, err
+	}
+	if err := samurai.Validate(); err != nil {
+		return request, nil, toAPIError(err)
+	}
+	return request, samurai, nil
+}
+
+//readPuzzle decodes a grid request into the puzzle it asks about, without checking its grid
+func readPuzzle(r *http.Request) (gridRequest, *sudoku.SamuraiSudoku, error) {
+	var request gridRequest
+	if err := decode(r, &request); err != nil {
+		return request, nil, err
+	}
+	if request.Grid == nil {
+		return request, nil, &apiError{http.StatusBadRequest, CodeBadRequest, "missing grid"}
+	}
+	layout, err := parseLayout(request.Layout)
+	if err != nil {
+		return request, nil, err
+	}
+	samurai := &sudoku.SamuraiSudoku{}
+	samurai.SetLayout(layout)
+	if err := setVariant(samurai, request.variantRequest); err != nil {
+		return request, nil, err
+	}
+	samurai.SetGrid(request.Grid)
+	return request, samurai, nil
+}
+
+//setVariant sets the variant asked for by a request on samurai, whose layout must already be set
+func setVariant(samurai *sudoku.SamuraiSudoku, request variantRequest) error {
+	variant := &sudoku.Variant{Cages: request.Cages, Markers: request.Markers, Negative: request.Negative, Restrictions: request.Restrictions}
+	var err error
+	if variant.Diagonals, err = parsePositions(request.Diagonals); err != nil {
+		return err
+	}
+	if variant.Windows, err = parsePositions(request.Windows); err != nil {
+		return err
+	}
+	for _, j := range request.Jigsaw {
+		position, err := sudoku.ParsePosition(j.Position)
+		if err != nil {
+			return &apiError{http.StatusBadRequest, CodeBadRequest, err.Error()}
+		}
+		variant.Jigsaws = append(variant.Jigsaws, sudoku.Jigsaw{Position: position, Regions: j.Regions})
+	}
+	for _, t := range request.Thermos {
+		variant.Constraints = append(variant.Constraints, t)
+	}
+	for _, a := range request.Arrows {
+		variant.Constraints = append(variant.Constraints, a)
+	}
+	for _, w := range request.Whispers {
+		variant.Constraints = append(variant.Constraints, w)
+	}
+	if variant.IsZero() {
+		return nil
+	}
+	if err := samurai.SetVariant(variant); err != nil {
+		return &apiError{http.StatusBadRequest, CodeBadRequest, err.Error()}
+	}
+	return nil
+}
+
+//parsePositions parses the sub-sudoku names of a request
+func parsePositions(names []string) ([]sudoku.Position, error) {
+	var positions []sudoku.Position
+	for _, name := range names {
+		position, err := sudoku.ParsePosition(name)
+		if err != nil {
+			return nil, &apiError{http.StatusBadRequest, CodeBadRequest, err.Error()}
+		}
+		positions = append(positions, position)
+	}
+	return positions, nil
+}
+
+//parseLayout returns the layout named name, samurai if name is empty
+func parseLayout(name string) (*sudoku.Layout, error) {
+	if name == "" {
+		return sudoku.Samurai, nil
+	}
+	layout, err := sudoku.ParseLayout(name)
+	if err != nil {
+		return nil, &apiError{http.StatusBadRequest, CodeUnknownLayout, err.Error()}
+	}
+	return layout, nil
+}
+
+func (h *handler) solve(ctx context.Context, r *http.Request) (interface{}, error) {
+	request, samurai, err := readGrid(r)
+	if err != nil {
+		return nil, err
+	}
+	if request.Solver == "" {
+		request.Solver = "global"
+	}
+	solve, ok := sudoku.Solvers[request.Solver]
+	if !ok {
+		return nil, &apiError{http.StatusBadRequest, CodeUnknownSolver, fmt.Sprintf("unknown solver %q", request.Solver)}
+	}
+
+	grid, err := solve(ctx, samurai)
+	if err != nil {
+		return nil, toAPIError(err)
+	}
+	return gridResponse{grid}, nil
+}
+
+func (h *handler) validate(ctx context.Context, r *http.Request) (interface{}, error) {
+	_, samurai, err := readPuzzle(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := samurai.Validate(); err != nil {
+		apiErr := toAPIError(err)
+		if apiErr.Code == CodeInvalidGrid {
+			return nil, apiErr
+		}
+		return validateResponse{Error: apiErr}, nil
+	}
+
+	count, err := samurai.CountSolutions(ctx, 2)
+	if err != nil {
+		return nil, toAPIError(err)
+	}
+	response := validateResponse{Valid: count == 1, Solutions: count}
+	switch count {
+	case 0:
+		response.Error = toAPIError(sudoku.ErrUnsolvable)
+	case 2:
+		response.Error = &apiError{http.StatusUnprocessableEntity, CodeNotUnique, "the grid has more than one solution"}
+	}
+	return response, nil
+}
+
+//hint returns the simplest logical deduction that can be made on a grid with a unique solution
+func (h *handler) hint(ctx context.Context, r *http.Request) (interface{}, error) {
+	request, samurai, err := readGrid(r)
+	if err != nil {
+		return nil, err
+	}
+	count, err := samurai.CountSolutions(ctx, 2)
+	if err != nil {
+		return nil, toAPIError(err)
+	}
+	switch count {
+	case 0:
+		return nil, toAPIError(sudoku.ErrUnsolvable)
+	case 2:
+		return nil, &apiError{http.StatusUnprocessableEntity, CodeNotUnique, "the grid has more than one solution"}
+	}
+
+	samurai.SetGrid(copyGrid(request.Grid))
+	d, err := sudoku.Hint(samurai)
+	if err != nil {
+		return nil, toAPIError(err)
+	}
+	response := hintResponse{
+		Technique:    d.Technique.String(),
+		Row:          d.Cell.Row,
+		Column:       d.Cell.Column,
+		Value:        d.Value,
+		Digits:       d.Digits,
+		Eliminations: d.Eliminations,
+		Position:     d.Position.String(),
+		Unit:         d.Unit,
+		Index:        d.Index,
+		Explanation:  d.Explanation,
+	}
+	if d.Overlap != 0 {
+		response.Overlap = d.Overlap.String()
+	}
+	return response, nil
+}
+
+func (h *handler) generate(ctx context.Context, r *http.Request) (interface{}, error) {
+	var request generateRequest
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	layout, err := parseLayout(request.Layout)
+	if err != nil {
+		return nil, err
+	}
+	samurai := &sudoku.SamuraiSudoku{}
+	samurai.SetLayout(layout)
+	if err := setVariant(samurai, request.variantRequest); err != nil {
+		return nil, err
+	}
+	options := sudoku.GenerateOptions{Seed: request.Seed, MinClues: request.MinClues, Layout: layout, Variant: samurai.Variant()}
+	grid, err := sudoku.GenerateSamuraiSudokuContext(ctx, options)
+	if err != nil {
+		return nil, toAPIError(err)
+	}
+	return gridResponse{grid}, nil
+}
+
+func copyGrid(grid sudoku.Grid) sudoku.Grid {
+	c := make(sudoku.Grid, len(grid))
+	for i := range grid {
+		c[i] = append([]int(nil), grid[i]...)
+	}
+	return c
+}
+
+//decode decodes the JSON body of r into v, rejecting unknown fields
+func decode(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return &apiError{http.StatusBadRequest, CodeBadRequest, fmt.Sprintf("malformed request body: %v", err)}
+	}
+	return nil
+}
+
+// apiError An error sent back to the client, with the HTTP status it is sent with
+type apiError struct {
+	status  int
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string {
+	return e.Message
+}
+
+//toAPIError maps errors returned by the sudoku package to the error sent back to the client
+func toAPIError(err error) *apiError {
+	var apiErr *apiError
+	var conflict *sudoku.ConflictError
+	var cage *sudoku.CageError
+	var marker *sudoku.MarkerError
+	var constraint *sudoku.ConstraintError
+	var restriction *sudoku.RestrictionError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr
+	case errors.As(err, &conflict), errors.As(err, &cage), errors.As(err, &marker), errors.As(err, &constraint),
+		errors.As(err, &restriction):
+		return &apiError{http.StatusUnprocessableEntity, CodeConflict, err.Error()}
+	case errors.Is(err, sudoku.ErrInvalidGrid):
+		return &apiError{http.StatusBadRequest, CodeInvalidGrid, err.Error()}
+	case errors.Is(err, sudoku.ErrUnsolvable):
+		return &apiError{http.StatusUnprocessableEntity, CodeUnsolvable, err.Error()}
+	case errors.Is(err, sudoku.ErrSolved):
+		return &apiError{http.StatusUnprocessableEntity, CodeSolved, "the grid is already solved"}
+	case errors.Is(err, sudoku.ErrNoHint):
+		return &apiError{http.StatusUnprocessableEntity, CodeNoHint, err.Error()}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &apiError{http.StatusGatewayTimeout, CodeTimeout, "the request took too long"}
+	case errors.Is(err, context.Canceled):
+		return &apiError{statusClientClosedRequest, CodeCanceled, "the request was canceled"}
+	}
+	return &apiError{http.StatusInternalServerError, CodeInternal, err.Error()}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	apiErr := toAPIError(err)
+	writeJSON(w, apiErr.status, struct {
+		Error *apiError `json:"error"`
+	}{apiErr})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,528 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	sudoku "github.com/alielbashir/samurai-sudoku-go"
+)
+
+func testGrid(t *testing.T) sudoku.Grid {
+	f, err := os.Open("../sudoku.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	grid, err := sudoku.ReadSamuraiGrid(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return grid
+}
+
+//unsolvableGrid returns the test grid with a wrong digit that doesn't break any rule yet added
+func unsolvableGrid(t *testing.T) sudoku.Grid {
+	var samurai sudoku.SamuraiSudoku
+	samurai.SetGrid(testGrid(t))
+	solution := sudoku.GlobalSolveSamuraiSudoku(&samurai)
+
+	grid := testGrid(t)
+	for y, row := range grid {
+		for x, num := range row {
+			if num != 0 {
+				continue
+			}
+			for n := 1; n < 10; n++ {
+				if n == solution[y][x] {
+					continue
+				}
+				grid[y][x] = n
+				if sudoku.Validate(grid) == nil {
+					return grid
+				}
+			}
+			grid[y][x] = 0
+		}
+	}
+	t.Fatal("no wrong digit fits the test grid")
+	return nil
+}
+
+func gridBody(t *testing.T, grid sudoku.Grid, solver string) string {
+	body, err := json.Marshal(gridRequest{Grid: grid, Solver: solver})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func post(handler http.Handler, path string, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	return recorder
+}
+
+func errorCode(t *testing.T, recorder *httptest.ResponseRecorder) string {
+	var response struct {
+		Error apiError `json:"error"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("response isn't JSON: %v", err)
+	}
+	return response.Error.Code
+}
+
+func TestSolve(t *testing.T) {
+	handler := New(Options{})
+	recorder := post(handler, "/solve", gridBody(t, testGrid(t), "sequential"))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body)
+	}
+	var response gridResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("response isn't JSON: %v", err)
+	}
+	if err := sudoku.Validate(response.Grid); err != nil {
+		t.Fatalf("invalid solution: %v", err)
+	}
+	for _, row := range response.Grid {
+		for _, num := range row {
+			if num == 0 {
+				t.Fatalf("solution has empty cells:\n%v", response.Grid)
+			}
+		}
+	}
+}
+
+func TestSolveAtOnce(t *testing.T) {
+	// requests are worked on at once, whichever solver they pick, which the race detector checks when it is on.
+	// The concurrent solvers retry from scratch until they get through, which takes long with it
+	handler := New(Options{Timeout: 5 * time.Minute, MaxConcurrent: 4})
+	solvers := []string{"global", "sequential", "concurrent", "double"}
+	codes := make(chan int, len(solvers))
+	for i := 0; i < cap(codes); i++ {
+		body := gridBody(t, testGrid(t), solvers[i%len(solvers)])
+		go func() {
+			codes <- post(handler, "/solve", body).Code
+		}()
+	}
+	for i := 0; i < cap(codes); i++ {
+		if code := <-codes; code != http.StatusOK {
+			t.Fatalf("want status %d, got %d", http.StatusOK, code)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	conflict := testGrid(t)
+	conflict[0][0] = 5
+	unsolvable := unsolvableGrid(t)
+
+	testCases := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"malformed json", "/solve", "{", http.StatusBadRequest, CodeBadRequest},
+		{"unknown field", "/solve", `{"puzzle": []}`, http.StatusBadRequest, CodeBadRequest},
+		{"missing grid", "/solve", `{}`, http.StatusBadRequest, CodeBadRequest},
+		{"invalid grid", "/solve", `{"grid": [[1, 2, 3]]}`, http.StatusBadRequest, CodeInvalidGrid},
+		{"conflict", "/solve", gridBody(t, conflict, ""), http.StatusUnprocessableEntity, CodeConflict},
+		{"unknown solver", "/solve", gridBody(t, testGrid(t), "magic"), http.StatusBadRequest, CodeUnknownSolver},
+		{"unsolvable", "/solve", gridBody(t, unsolvable, ""), http.StatusUnprocessableEntity, CodeUnsolvable},
+		{"hint conflict", "/hint", gridBody(t, conflict, ""), http.StatusUnprocessableEntity, CodeConflict},
+		{"not found", "/frobnicate", "{}", http.StatusNotFound, CodeNotFound},
+		{"unknown layout", "/solve", `{"grid": [[0]], "layout": "pinwheel"}`, http.StatusBadRequest, CodeUnknownLayout},
+		{"wrong layout", "/solve", `{"grid": [[0]], "layout": "twin"}`, http.StatusBadRequest, CodeInvalidGrid},
+		{"unknown diagonal", "/solve", `{"grid": [[0]], "diagonals": ["middle"]}`, http.StatusBadRequest, CodeBadRequest},
+		{"unknown jigsaw position", "/solve", `{"grid": [[0]], "jigsaw": [{"position": "middle", "regions": []}]}`, http.StatusBadRequest, CodeBadRequest},
+		{"bad jigsaw regions", "/generate", `{"jigsaw": [{"position": "centre", "regions": [[1]]}]}`, http.StatusBadRequest, CodeBadRequest},
+		{"unknown window", "/generate", `{"windows": ["middle"]}`, http.StatusBadRequest, CodeBadRequest},
+		{"window outside the layout", "/generate", `{"layout": "twin", "windows": ["centre"]}`, http.StatusBadRequest, CodeBadRequest},
+		{"diagonal outside the layout", "/generate", `{"layout": "twin", "diagonals": ["centre"]}`, http.StatusBadRequest, CodeBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := post(New(Options{}), tc.path, tc.body)
+			if recorder.Code != tc.status {
+				t.Fatalf("want status %d, got %d: %s", tc.status, recorder.Code, recorder.Body)
+			}
+			if got := errorCode(t, recorder); got != tc.code {
+				t.Fatalf("want error code %q, got %q", tc.code, got)
+			}
+		})
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	New(Options{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/solve", nil))
+
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("want status %d, got %d", http.StatusMethodNotAllowed, recorder.Code)
+	}
+	if got := recorder.Header().Get("Allow"); got != http.MethodPost {
+		t.Fatalf("want Allow: POST, got %q", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	conflict := testGrid(t)
+	conflict[0][0] = 5
+	empty := sudoku.NewSamuraiGrid()
+
+	testCases := []struct {
+		name      string
+		grid      sudoku.Grid
+		valid     bool
+		solutions int
+		code      string
+	}{
+		{"unique", testGrid(t), true, 1, ""},
+		{"conflict", conflict, false, 0, CodeConflict},
+		{"not unique", empty, false, 2, CodeNotUnique},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := post(New(Options{}), "/validate", gridBody(t, tc.grid, ""))
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("want status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body)
+			}
+			var response validateResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+				t.Fatalf("response isn't JSON: %v", err)
+			}
+			code := ""
+			if response.Error != nil {
+				code = response.Error.Code
+			}
+			if response.Valid != tc.valid || response.Solutions != tc.solutions || code != tc.code {
+				t.Fatalf("want valid %v, %d solutions, code %q, got %+v", tc.valid, tc.solutions, tc.code, response)
+			}
+		})
+	}
+}
+
+func TestHint(t *testing.T) {
+	var samurai sudoku.SamuraiSudoku
+	samurai.SetGrid(testGrid(t))
+	solution, err := sudoku.GlobalSolveSamuraiSudokuContext(context.Background(), &samurai)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := post(New(Options{}), "/hint", gridBody(t, testGrid(t), ""))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body)
+	}
+	var response hintResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("response isn't JSON: %v", err)
+	}
+	if response.Technique != "naked single" || response.Explanation == "" {
+		t.Fatalf("want an explained naked single, got %+v", response)
+	}
+	if want := solution[response.Row][response.Column]; response.Value != want {
+		t.Fatalf("want %d in (%d, %d) as in the solution, got %d", want, response.Row, response.Column, response.Value)
+	}
+
+	recorder = post(New(Options{}), "/hint", gridBody(t, solution, ""))
+	if got := errorCode(t, recorder); recorder.Code != http.StatusUnprocessableEntity || got != CodeSolved {
+		t.Fatalf("want error code %q for a solved grid, got %d %q", CodeSolved, recorder.Code, got)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	recorder := post(New(Options{}), "/generate", `{"seed": 7, "minClues": 300}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body)
+	}
+	var response gridResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("response isn't JSON: %v", err)
+	}
+	if got := sudoku.CountSolutions(response.Grid, 2); got != 1 {
+		t.Fatalf("want a unique solution, got %d", got)
+	}
+}
+
+func TestGenerateUnsatisfiableVariant(t *testing.T) {
+	// two cells of the same row can't both hold 1
+	recorder := post(New(Options{}), "/generate", `{"seed": 1, "restrictions": [{"cells": [{"row": 0, "column": 0}, {"row": 0, "column": 1}], "digits": [1]}]}`)
+	if got := errorCode(t, recorder); recorder.Code != http.StatusUnprocessableEntity || got != CodeUnsolvable {
+		t.Fatalf("want error code %q, got %d %q", CodeUnsolvable, recorder.Code, got)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	// the concurrent solver keeps retrying unsolvable grids until it times out
+	handler := New(Options{Timeout: 50 * time.Millisecond})
+	recorder := post(handler, "/solve", gridBody(t, unsolvableGrid(t), "concurrent"))
+
+	if recorder.Code != http.StatusGatewayTimeout {
+		t.Fatalf("want status %d, got %d: %s", http.StatusGatewayTimeout, recorder.Code, recorder.Body)
+	}
+	if got := errorCode(t, recorder); got != CodeTimeout {
+		t.Fatalf("want error code %q, got %q", CodeTimeout, got)
+	}
+}
+
+func TestCanceled(t *testing.T) {
+	// the client is gone before the request is worked on, or while the concurrent solver retries the unsolvable grid
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := httptest.NewRequest(http.MethodPost, "/solve", strings.NewReader(gridBody(t, unsolvableGrid(t), "concurrent"))).WithContext(ctx)
+	recorder := httptest.NewRecorder()
+	New(Options{}).ServeHTTP(recorder, r)
+
+	if recorder.Code != statusClientClosedRequest {
+		t.Fatalf("want status %d, got %d: %s", statusClientClosedRequest, recorder.Code, recorder.Body)
+	}
+	if got := errorCode(t, recorder); got != CodeCanceled {
+		t.Fatalf("want error code %q, got %q", CodeCanceled, got)
+	}
+}
+
+func TestBusy(t *testing.T) {
+	h := New(Options{Timeout: 50 * time.Millisecond, MaxConcurrent: 1}).(*handler)
+	h.slots <- struct{}{}
+	defer func() { <-h.slots }()
+
+	recorder := post(h, "/solve", gridBody(t, testGrid(t), ""))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("want status %d, got %d: %s", http.StatusServiceUnavailable, recorder.Code, recorder.Body)
+	}
+	if got := errorCode(t, recorder); got != CodeBusy {
+		t.Fatalf("want error code %q, got %q", CodeBusy, got)
+	}
+}
+
+func TestLayout(t *testing.T) {
+	recorder := post(New(Options{}), "/generate", `{"seed": 7, "layout": "butterfly"}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body)
+	}
+	var generated gridResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&generated); err != nil {
+		t.Fatalf("response isn't JSON: %v", err)
+	}
+
+	body, err := json.Marshal(gridRequest{Grid: generated.Grid, Layout: "butterfly"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder = post(New(Options{}), "/validate", string(body))
+	var response validateResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("response isn't JSON: %v", err)
+	}
+	if !response.Valid || response.Solutions != 1 {
+		t.Fatalf("want a valid butterfly puzzle, got %+v", response)
+	}
+}
+
+func TestDiagonals(t *testing.T) {
+	recorder := post(New(Options{}), "/generate", `{"seed": 7, "minClues": 300, "diagonals": ["centre"]}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body)
+	}
+	var generated gridResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&generated); err != nil {
+		t.Fatalf("response isn't JSON: %v", err)
+	}
+
+	body, err := json.Marshal(gridRequest{Grid: generated.Grid, variantRequest: variantRequest{Diagonals: []string{"centre"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder = post(New(Options{}), "/solve", string(body))
+	var solved gridResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&solved); err != nil {
+		t.Fatalf("response isn't JSON: %v", err)
+	}
+	var samurai sudoku.SamuraiSudoku
+	samurai.SetVariant(&sudoku.Variant{Diagonals: []sudoku.Position{sudoku.Centre}})
+	samurai.SetGrid(solved.Grid)
+	if err := samurai.Validate(); err != nil {
+		t.Fatalf("want the solution to follow the diagonals rule, got %v", err)
+	}
+}
+
+func TestCages(t *testing.T) {
+	cageBody := func(cages ...sudoku.Cage) string {
+		body, err := json.Marshal(gridRequest{Grid: testGrid(t), variantRequest: variantRequest{Cages: cages}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	// the first two cells of the puzzle are 1 and 6 once solved, the next two are given as 5 and 7
+	recorder := post(New(Options{}), "/solve", cageBody(sudoku.Cage{Sum: 7, Cells: []sudoku.Cell{{Row: 0, Column: 0}, {Row: 0, Column: 1}}}))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body)
+	}
+	var solved gridResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&solved); err != nil {
+		t.Fatalf("response isn't JSON: %v", err)
+	}
+	if solved.Grid[0][0]+solved.Grid[0][1] != 7 {
+		t.Fatalf("want the cage to add up to 7, got %d and %d", solved.Grid[0][0], solved.Grid[0][1])
+	}
+
+	testCases := []struct {
+		name   string
+		cage   sudoku.Cage
+		status int
+		code   string
+	}{
+		{"wrong sum", sudoku.Cage{Sum: 11, Cells: []sudoku.Cell{{Row: 0, Column: 2}, {Row: 0, Column: 3}}}, http.StatusUnprocessableEntity, CodeConflict},
+		{"cage in a gap", sudoku.Cage{Sum: 3, Cells: []sudoku.Cell{{Row: 0, Column: 0}, {Row: 0, Column: 9}}}, http.StatusBadRequest, CodeBadRequest},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := post(New(Options{}), "/solve", cageBody(tc.cage))
+			if recorder.Code != tc.status {
+				t.Fatalf("want status %d, got %d: %s", tc.status, recorder.Code, recorder.Body)
+			}
+			if got := errorCode(t, recorder); got != tc.code {
+				t.Fatalf("want error code %q, got %q", tc.code, got)
+			}
+		})
+	}
+}
+
+func TestMarkers(t *testing.T) {
+	markerBody := func(markers []sudoku.Marker, negative ...sudoku.MarkerKind) string {
+		body, err := json.Marshal(gridRequest{Grid: testGrid(t), variantRequest: variantRequest{Markers: markers, Negative: negative}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+	cells := func(row int, column int) [2]sudoku.Cell {
+		return [2]sudoku.Cell{{Row: row, Column: column}, {Row: row, Column: column + 1}}
+	}
+
+	// the second and third cells of the puzzle are 6 once solved and given as 5
+	recorder := post(New(Options{}), "/solve", markerBody([]sudoku.Marker{{Kind: sudoku.WhiteDot, Cells: cells(0, 1)}}))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body)
+	}
+
+	testCases := []struct {
+		name   string
+		body   string
+		status int
+		code   string
+	}{
+		{"broken marker", markerBody([]sudoku.Marker{{Kind: sudoku.VSum, Cells: cells(0, 2)}}), http.StatusUnprocessableEntity, CodeConflict},
+		{"marker in a gap", markerBody([]sudoku.Marker{{Kind: sudoku.XSum, Cells: cells(0, 8)}}), http.StatusBadRequest, CodeBadRequest},
+		{"unknown kind", `{"grid": [], "markers": [{"kind": "grey"}]}`, http.StatusBadRequest, CodeBadRequest},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := post(New(Options{}), "/solve", tc.body)
+			if recorder.Code != tc.status {
+				t.Fatalf("want status %d, got %d: %s", tc.status, recorder.Code, recorder.Body)
+			}
+			if got := errorCode(t, recorder); got != tc.code {
+				t.Fatalf("want error code %q, got %q", tc.code, got)
+			}
+		})
+	}
+}
+
+func TestLines(t *testing.T) {
+	cells := func(cells ...int) []sudoku.Cell {
+		var line []sudoku.Cell
+		for i := 0; i < len(cells); i += 2 {
+			line = append(line, sudoku.Cell{Row: cells[i], Column: cells[i+1]})
+		}
+		return line
+	}
+	lineBody := func(request variantRequest) string {
+		body, err := json.Marshal(gridRequest{Grid: testGrid(t), variantRequest: request})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	// the first four cells of the puzzle are 1 and 6 once solved, then given as 5 and 7
+	recorder := post(New(Options{}), "/solve", lineBody(variantRequest{Thermos: []sudoku.Thermo{cells(0, 0, 0, 1)}}))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body)
+	}
+
+	testCases := []struct {
+		name    string
+		request variantRequest
+		status  int
+		code    string
+	}{
+		{"broken thermometer", variantRequest{Thermos: []sudoku.Thermo{cells(0, 3, 0, 2)}}, http.StatusUnprocessableEntity, CodeConflict},
+		{"broken arrow", variantRequest{Arrows: []sudoku.Arrow{cells(0, 2, 0, 3)}}, http.StatusUnprocessableEntity, CodeConflict},
+		{"whispers in a gap", variantRequest{Whispers: []sudoku.Whispers{cells(0, 8, 0, 9)}}, http.StatusBadRequest, CodeBadRequest},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := post(New(Options{}), "/solve", lineBody(tc.request))
+			if recorder.Code != tc.status {
+				t.Fatalf("want status %d, got %d: %s", tc.status, recorder.Code, recorder.Body)
+			}
+			if got := errorCode(t, recorder); got != tc.code {
+				t.Fatalf("want error code %q, got %q", tc.code, got)
+			}
+		})
+	}
+}
+
+func TestRestrictions(t *testing.T) {
+	restrictionBody := func(restrictions ...sudoku.Restriction) string {
+		body, err := json.Marshal(gridRequest{Grid: testGrid(t), variantRequest: variantRequest{Restrictions: restrictions}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+	first, given := sudoku.Cell{Row: 0, Column: 0}, sudoku.Cell{Row: 0, Column: 2}
+
+	// the first cell of the puzzle is 1 once solved, the third is given as 5
+	recorder := post(New(Options{}), "/solve", restrictionBody(sudoku.OddCells(9, first)))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body)
+	}
+
+	testCases := []struct {
+		name        string
+		restriction sudoku.Restriction
+		status      int
+		code        string
+	}{
+		{"even cell given an odd digit", sudoku.EvenCells(9, given), http.StatusUnprocessableEntity, CodeConflict},
+		{"digit out of range", sudoku.Restriction{Cells: []sudoku.Cell{first}, Digits: []int{0}}, http.StatusBadRequest, CodeBadRequest},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := post(New(Options{}), "/solve", restrictionBody(tc.restriction))
+			if recorder.Code != tc.status {
+				t.Fatalf("want status %d, got %d: %s", tc.status, recorder.Code, recorder.Body)
+			}
+			if got := errorCode(t, recorder); got != tc.code {
+				t.Fatalf("want error code %q, got %q", tc.code, got)
+			}
+		})
+	}
+}
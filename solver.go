@@ -0,0 +1,465 @@
+package sudoku
+
+import (
+	"context"
+	"errors"
+	"math/bits"
+	"math/rand"
+)
+
+// engine searches the whole samurai grid at once, keeping a bitmask of the digits placed in every unit
+type engine struct {
+	grid            Grid
+	layout          *Layout
+	cells           []Cell
+	units           []unit
+	cellUnits       [][]int  // Units each cell belongs to
+	unitCells       [][]int  // Cells of each unit
+	used            []uint32 // Digits placed in each unit, bit n set for digit n
+	size            int      // Number of digits of the layout's sub-sudokus
+	allDigits       uint32   // Bits 1 to size
+	cages           []engineCage
+	cellCages       [][]int            // Cages each cell belongs to
+	relations       [][]engineRelation // Markers and negative constraints between each cell and its neighbours
+	related         [][]uint32         // Digits having the relation of each kind of marker with each digit, as bitmasks
+	constraints     []Constraint
+	cellMasks       []uint32 // Digits each cell may hold given the variant's restrictions, nil if there are none
+	cellConstraints [][]int  // Constraints each cell belongs to
+
+	ctx      context.Context
+	rng      *rand.Rand     // Shuffles the order digits are tried in, nil tries them in increasing order
+	samurai  *SamuraiSudoku // Records the moves made, nil if they aren't recorded
+	steps    int
+	maxSteps int // Give up with errTooManySteps after this many steps, 0 for no limit
+	err      error
+
+	limit     int // Stop searching after finding limit solutions, 0 finds them all
+	solutions int
+	solution  Grid
+}
+
+// engineCage A killer cage, with the digits placed in it so far
+type engineCage struct {
+	cells     []int
+	used      uint32 // Digits placed in the cage, bit n set for digit n
+	remaining int    // Sum the empty cells must add up to
+	empty     int
+}
+
+// engineRelation A marker, or a negative constraint, between a cell and one of its neighbours
+type engineRelation struct {
+	cell     int
+	kind     MarkerKind
+	negative bool // The digits of the cells must not have the relation of kind
+}
+
+// errTooManySteps is returned by a search that ran out of steps
+var errTooManySteps = errors.New("sudoku: search ran out of steps")
+
+//newEngine prepares a search of grid, which is copied and shaped like layout, following the rules of layout and variant.
+//It returns ErrUnsolvable if the grid's digits already conflict
+func newEngine(ctx context.Context, layout *Layout, variant *Variant, grid Grid) (*engine, error) {
+	e := &engine{ctx: ctx, layout: layout, grid: copyGrid(grid), size: layout.size}
+	e.allDigits = uint32(1)<<(e.size+1) - 2
+
+	index := make(map[Cell]int)
+	for y, row := range grid {
+		for x, num := range row {
+			if num == -1 {
+				continue
+			}
+			index[Cell{y, x}] = len(e.cells)
+			e.cells = append(e.cells, Cell{y, x})
+		}
+	}
+	e.cellUnits = make([][]int, len(e.cells))
+
+	units := allUnits(layout, variant)
+	e.units = units
+	e.used = make([]uint32, len(units))
+	e.unitCells = make([][]int, len(units))
+	for u, unit := range units {
+		for _, c := range unit.cells {
+			i := index[c]
+			e.cellUnits[i] = append(e.cellUnits[i], u)
+			e.unitCells[u] = append(e.unitCells[u], i)
+			if num := grid[c.Row][c.Column]; num > 0 {
+				if e.used[u]&(1<<num) != 0 {
+					return nil, ErrUnsolvable
+				}
+				e.used[u] |= 1 << num
+			}
+		}
+	}
+
+	if variant != nil {
+		e.cellCages = make([][]int, len(e.cells))
+		for k, cage := range variant.Cages {
+			ec := engineCage{remaining: cage.Sum}
+			for _, c := range cage.Cells {
+				i := index[c]
+				e.cellCages[i] = append(e.cellCages[i], k)
+				ec.cells = append(ec.cells, i)
+				if num := grid[c.Row][c.Column]; num > 0 {
+					if ec.used&(1<<num) != 0 {
+						return nil, ErrUnsolvable
+					}
+					ec.used |= 1 << num
+					ec.remaining -= num
+				} else {
+					ec.empty++
+				}
+			}
+			if ec.remaining < 0 || (ec.empty == 0 && ec.remaining != 0) {
+				return nil, ErrUnsolvable
+			}
+			e.cages = append(e.cages, ec)
+		}
+		if len(variant.Markers) > 0 || len(variant.Negative) > 0 {
+			if err := e.addRelations(variant, index); err != nil {
+				return nil, err
+			}
+		}
+		if len(variant.Restrictions) > 0 {
+			e.cellMasks = make([]uint32, len(e.cells))
+			for i := range e.cellMasks {
+				e.cellMasks[i] = e.allDigits
+			}
+			for c, mask := range restrictionMasks(variant.Restrictions) {
+				i := index[c]
+				if num := grid[c.Row][c.Column]; num > 0 && mask&(1<<num) == 0 {
+					return nil, ErrUnsolvable
+				}
+				e.cellMasks[i] = mask
+			}
+		}
+		if len(variant.Constraints) > 0 {
+			if validateConstraints(e.grid, variant.Constraints, e.size) != nil {
+				return nil, ErrUnsolvable
+			}
+			e.constraints = variant.Constraints
+			e.cellConstraints = make([][]int, len(e.cells))
+			for c, k := range constraintIndex(variant.Constraints) {
+				i := index[c]
+				e.cellConstraints[i] = k
+			}
+		}
+	}
+	return e, nil
+}
+
+//addRelations sets up the markers and negative constraints of variant between the engine's cells, looked up in index.
+//It returns ErrUnsolvable if the grid's digits already break one
+func (e *engine) addRelations(variant *Variant, index map[Cell]int) error {
+	e.related = make([][]uint32, len(markerNames))
+	for k := range e.related {
+		e.related[k] = make([]uint32, e.size+1)
+		for n := 1; n <= e.size; n++ {
+			e.related[k][n] = MarkerKind(k).related(n, e.size)
+		}
+	}
+
+	e.relations = make([][]engineRelation, len(e.cells))
+	markers := markerIndex(variant.Markers)
+	for i, c := range e.cells {
+		// every pair of cells once, from the cell above or to the left
+		for _, d := range []Cell{{c.Row, c.Column + 1}, {c.Row + 1, c.Column}} {
+			j, ok := index[d]
+			if !ok {
+				continue
+			}
+			kinds, negative := variant.Negative, true
+			if k := markerBetween(variant.Markers, markers, c, d); k >= 0 {
+				kinds, negative = []MarkerKind{variant.Markers[k].Kind}, false
+			}
+			for _, kind := range kinds {
+				e.relations[i] = append(e.relations[i], engineRelation{j, kind, negative})
+				e.relations[j] = append(e.relations[j], engineRelation{i, kind, negative})
+				if a, b := e.grid[c.Row][c.Column], e.grid[d.Row][d.Column]; a > 0 && b > 0 && kind.holds(a, b) == negative {
+					return ErrUnsolvable
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func copyGrid(grid Grid) Grid {
+	c := make(Grid, len(grid))
+	for i := range grid {
+		c[i] = make([]int, len(grid[i]))
+		copy(c[i], grid[i])
+	}
+	return c
+}
+
+//candidates returns the digits that can be placed in the i-th cell, as a bitmask
+func (e *engine) candidates(i int) uint32 {
+	mask := e.allDigits
+	for _, u := range e.cellUnits[i] {
+		mask &^= e.used[u]
+	}
+	if e.cellMasks != nil {
+		mask &= e.cellMasks[i]
+	}
+	if e.cellCages != nil {
+		for _, k := range e.cellCages[i] {
+			cage := &e.cages[k]
+			mask &= cageCandidates(cage.used, cage.empty, cage.remaining, e.size)
+		}
+	}
+	if e.relations != nil {
+		for _, r := range e.relations[i] {
+			c := e.cells[r.cell]
+			num := e.grid[c.Row][c.Column]
+			switch {
+			case num <= 0:
+			case r.negative:
+				mask &^= e.related[r.kind][num]
+			default:
+				mask &= e.related[r.kind][num]
+			}
+		}
+	}
+	if e.cellConstraints != nil && len(e.cellConstraints[i]) > 0 {
+		c := e.cells[i]
+		for n := 1; n <= e.size; n++ {
+			if mask&(1<<n) == 0 {
+				continue
+			}
+			for _, k := range e.cellConstraints[i] {
+				if !e.constraints[k].Possible(e.grid, c, n, e.size) {
+					mask &^= 1 << n
+					break
+				}
+			}
+		}
+	}
+	return mask
+}
+
+func (e *engine) place(i int, n int) {
+	c := e.cells[i]
+	e.grid[c.Row][c.Column] = n
+	for _, u := range e.cellUnits[i] {
+		e.used[u] |= 1 << n
+	}
+	if e.cellCages != nil {
+		for _, k := range e.cellCages[i] {
+			e.cages[k].used |= 1 << n
+			e.cages[k].remaining -= n
+			e.cages[k].empty--
+		}
+	}
+	e.record(c, n)
+}
+
+func (e *engine) remove(i int, n int) {
+	c := e.cells[i]
+	e.grid[c.Row][c.Column] = 0
+	for _, u := range e.cellUnits[i] {
+		e.used[u] &^= 1 << n
+	}
+	if e.cellCages != nil {
+		for _, k := range e.cellCages[i] {
+			e.cages[k].used &^= 1 << n
+			e.cages[k].remaining += n
+			e.cages[k].empty++
+		}
+	}
+	e.record(c, 0)
+}
+
+//record records the move in the samurai sudoku being solved, in the first sub-sudoku the cell is in
+func (e *engine) record(c Cell, n int) {
+	if e.samurai == nil {
+		return
+	}
+	position, y, x, _ := e.layout.locate(c.Row, c.Column)
+	e.samurai.mu.Lock()
+	e.samurai.recordMove(Thread1, position, y, x, n)
+	e.samurai.mu.Unlock()
+}
+
+// choice A digit to try in a cell
+type choice struct {
+	cell int
+	num  int
+}
+
+//search branches on the empty cell with the fewest candidates, or the digit with the fewest places left in a unit,
+//whichever has fewer options, and recurses. It returns true when the search should stop
+func (e *engine) search() bool {
+	if e.steps%1024 == 0 && e.ctx.Err() != nil {
+		e.err = e.ctx.Err()
+		return true
+	}
+	if e.maxSteps > 0 && e.steps >= e.maxSteps {
+		e.err = errTooManySteps
+		return true
+	}
+	e.steps++
+
+	masks := make([]uint32, len(e.cells))
+	best, bestCount := -1, e.size+1
+	for i, c := range e.cells {
+		if e.grid[c.Row][c.Column] != 0 {
+			continue
+		}
+		masks[i] = e.candidates(i)
+		count := bits.OnesCount32(masks[i])
+		if count == 0 {
+			return false
+		}
+		if count < bestCount {
+			best, bestCount = i, count
+		}
+	}
+
+	if best == -1 {
+		e.solutions++
+		if e.solution == nil {
+			e.solution = copyGrid(e.grid)
+		}
+		return e.limit > 0 && e.solutions >= e.limit
+	}
+
+	var choices []choice
+	for n := 1; n <= e.size; n++ {
+		if masks[best]&(1<<n) != 0 {
+			choices = append(choices, choice{best, n})
+		}
+	}
+	if bestCount > 1 {
+		if unitChoices, ok := e.unitChoices(masks, bestCount); !ok {
+			return false
+		} else if unitChoices != nil {
+			choices = unitChoices
+		}
+	}
+	if e.rng != nil {
+		e.rng.Shuffle(len(choices), func(i, j int) {
+			choices[i], choices[j] = choices[j], choices[i]
+		})
+	}
+
+	for _, choice := range choices {
+		e.place(choice.cell, choice.num)
+		if e.search() {
+			return true
+		}
+		e.remove(choice.cell, choice.num)
+	}
+	return false
+}
+
+//unitChoices looks for a digit missing from a unit that fits in fewer than limit of its cells, and returns the cells it fits in.
+//It returns false if a digit missing from a unit fits nowhere in it
+func (e *engine) unitChoices(masks []uint32, limit int) ([]choice, bool) {
+	var best []choice
+	places := make([]int, 0, e.size)
+	for u, used := range e.used {
+		for n := 1; n <= e.size; n++ {
+			if used&(1<<n) != 0 {
+				continue
+			}
+			places = places[:0]
+			for _, i := range e.unitCells[u] {
+				if masks[i]&(1<<n) != 0 {
+					places = append(places, i)
+				}
+			}
+			if len(places) == 0 {
+				return nil, false
+			}
+			if len(places) < limit {
+				limit = len(places)
+				best = best[:0]
+				for _, i := range places {
+					best = append(best, choice{i, n})
+				}
+				if limit == 1 {
+					return best, true
+				}
+			}
+		}
+	}
+	return best, true
+}
+
+//GlobalSolveSamuraiSudoku solves samurai sudoku by backtracking over all of its empty cells at once
+func GlobalSolveSamuraiSudoku(samurai *SamuraiSudoku) Grid {
+	grid, _ := GlobalSolveSamuraiSudokuContext(context.Background(), samurai)
+	return grid
+}
+
+//GlobalSolveSamuraiSudokuContext solves samurai sudoku by backtracking over all of its empty cells at once,
+//always branching where the fewest options are left, and giving up once ctx is done.
+//It returns ErrUnsolvable if the samurai sudoku has no solution
+func GlobalSolveSamuraiSudokuContext(ctx context.Context, samurai *SamuraiSudoku) (Grid, error) {
+	samurai.mu.Lock()
+	samurai.tracker.resetMoves()
+	samurai.mu.Unlock()
+
+	e, err := newEngine(ctx, samurai.Layout(), samurai.variant, samurai.Grid())
+	if err != nil {
+		return samurai.Grid(), err
+	}
+	e.samurai = samurai
+	e.limit = 1
+	e.search()
+	if e.err != nil {
+		return samurai.Grid(), e.err
+	}
+	if e.solution == nil {
+		return samurai.Grid(), ErrUnsolvable
+	}
+
+	samurai.mu.Lock()
+	defer samurai.mu.Unlock()
+	for i, row := range e.solution {
+		copy(samurai.grid[i], row)
+	}
+	return samurai.grid, nil
+}
+
+//CountSolutions returns the number of solutions of the samurai grid, counting at most limit of them if limit > 0
+func CountSolutions(grid Grid, limit int) int {
+	count, _ := CountSolutionsContext(context.Background(), grid, limit)
+	return count
+}
+
+//CountSolutionsContext returns the number of solutions of the samurai grid, counting at most limit of them if limit > 0.
+//It returns ErrUnsolvable if the grid's digits already break a rule, and the solutions counted so far and ctx's error once
+//ctx is done
+func CountSolutionsContext(ctx context.Context, grid Grid, limit int) (int, error) {
+	return countSolutions(ctx, Samurai, nil, grid, limit)
+}
+
+//CountSolutions returns the number of solutions of the puzzle's grid, counting at most limit of them if limit > 0.
+//It returns ErrUnsolvable if the grid's digits already break a rule, and the solutions counted so far and ctx's error once
+//ctx is done
+func (s *SamuraiSudoku) CountSolutions(ctx context.Context, limit int) (int, error) {
+	return countSolutions(ctx, s.Layout(), s.variant, s.Grid(), limit)
+}
+
+func countSolutions(ctx context.Context, layout *Layout, variant *Variant, grid Grid, limit int) (int, error) {
+	e, err := newEngine(ctx, layout, variant, grid)
+	if err != nil {
+		return 0, err
+	}
+	e.limit = limit
+	e.search()
+	return e.solutions, e.err
+}
+
+// SolverFunc solves a samurai sudoku, giving up once ctx is done
+type SolverFunc func(ctx context.Context, samurai *SamuraiSudoku) (Grid, error)
+
+// Solvers holds every samurai sudoku solver by name
+var Solvers = map[string]SolverFunc{
+	"global":     GlobalSolveSamuraiSudokuContext,
+	"sequential": SolveSamuraiSudokuContext,
+	"concurrent": ConcurrentSolveSamuraiSudokuContext,
+	"double":     DoubleThreadSolveSamuraiSudokuContext,
+}
@@ -0,0 +1,104 @@
+package sudoku
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGlobalSolveSamuraiSudoku(t *testing.T) {
+	want := Grid{
+		{1, 6, 5, 7, 9, 8, 4, 2, 3, -1, -1, -1, 7, 3, 9, 6, 4, 8, 1, 2, 5},
+		{4, 9, 2, 5, 6, 3, 8, 1, 7, -1, -1, -1, 1, 4, 8, 7, 5, 2, 6, 3, 9},
+		{3, 8, 7, 2, 1, 4, 9, 5, 6, -1, -1, -1, 5, 6, 2, 3, 9, 1, 7, 4, 8},
+		{9, 4, 6, 3, 5, 2, 1, 7, 8, -1, -1, -1, 9, 7, 3, 5, 8, 6, 4, 1, 2},
+		{8, 7, 3, 6, 4, 1, 5, 9, 2, -1, -1, -1, 2, 5, 1, 4, 3, 9, 8, 6, 7},
+		{2, 5, 1, 8, 7, 9, 3, 6, 4, -1, -1, -1, 4, 8, 6, 1, 2, 7, 5, 9, 3},
+		{5, 3, 8, 9, 2, 6, 7, 4, 1, 8, 2, 3, 6, 9, 5, 8, 1, 3, 2, 7, 4},
+		{6, 1, 9, 4, 3, 7, 2, 8, 5, 9, 6, 7, 3, 1, 4, 2, 7, 5, 9, 8, 6},
+		{7, 2, 4, 1, 8, 5, 6, 3, 9, 5, 1, 4, 8, 2, 7, 9, 6, 4, 3, 5, 1},
+		{-1, -1, -1, -1, -1, -1, 3, 9, 2, 4, 7, 6, 5, 8, 1, -1, -1, -1, -1, -1, -1},
+		{-1, -1, -1, -1, -1, -1, 8, 7, 6, 3, 5, 1, 2, 4, 9, -1, -1, -1, -1, -1, -1},
+		{-1, -1, -1, -1, -1, -1, 5, 1, 4, 2, 9, 8, 7, 3, 6, -1, -1, -1, -1, -1, -1},
+		{9, 1, 8, 5, 3, 6, 4, 2, 7, 6, 3, 9, 1, 5, 8, 9, 4, 3, 7, 6, 2},
+		{6, 2, 3, 7, 4, 9, 1, 5, 8, 7, 4, 2, 9, 6, 3, 1, 2, 7, 8, 5, 4},
+		{5, 4, 7, 1, 2, 8, 9, 6, 3, 1, 8, 5, 4, 7, 2, 6, 5, 8, 1, 3, 9},
+		{3, 9, 6, 4, 5, 1, 7, 8, 2, -1, -1, -1, 3, 4, 1, 5, 7, 2, 9, 8, 6},
+		{2, 5, 1, 3, 8, 7, 6, 4, 9, -1, -1, -1, 6, 8, 5, 4, 1, 9, 3, 2, 7},
+		{8, 7, 4, 6, 9, 2, 3, 1, 5, -1, -1, -1, 7, 2, 9, 8, 3, 6, 5, 4, 1},
+		{1, 8, 5, 9, 7, 4, 2, 3, 6, -1, -1, -1, 2, 1, 6, 7, 8, 5, 4, 9, 3},
+		{4, 3, 9, 2, 6, 5, 8, 7, 1, -1, -1, -1, 5, 3, 4, 2, 9, 1, 6, 7, 8},
+		{7, 6, 2, 8, 1, 3, 5, 9, 4, -1, -1, -1, 8, 9, 7, 3, 6, 4, 2, 1, 5},
+	}
+
+	samurai := newTestSamurai()
+	got := GlobalSolveSamuraiSudoku(samurai)
+
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("want\n%v\ngot\n%v ", want, got)
+	}
+	if len(samurai.trace()) == 0 {
+		t.Fatalf("want moves to be recorded")
+	}
+}
+
+func TestGlobalSolveSamuraiSudokuUnsolvable(t *testing.T) {
+	samurai := newTestSamurai()
+	// the centre's middle box already holds a 6
+	samurai.grid[10][9] = 6
+
+	if _, err := GlobalSolveSamuraiSudokuContext(context.Background(), samurai); !errors.Is(err, ErrUnsolvable) {
+		t.Fatalf("want %v, got %v", ErrUnsolvable, err)
+	}
+}
+
+func TestCountSolutions(t *testing.T) {
+	grid := SamuraiGridFromFile("sudoku.txt")
+	if got := CountSolutions(grid, 0); got != 1 {
+		t.Fatalf("want 1 solution, got %d", got)
+	}
+
+	// emptying the givens of the centre leaves many solutions
+	for y := 9; y < 12; y++ {
+		for x := 6; x < 15; x++ {
+			grid[y][x] = 0
+		}
+	}
+	if got := CountSolutions(grid, 2); got != 2 {
+		t.Fatalf("want the count to stop at 2 solutions, got %d", got)
+	}
+
+	// a second 5 in the top row of the top left sub-sudoku is rejected rather than counted as no solution
+	grid[0][0] = 5
+	if count, err := CountSolutionsContext(context.Background(), grid, 0); count != 0 || !errors.Is(err, ErrUnsolvable) {
+		t.Fatalf("want 0 solutions and %v, got %d and %v", ErrUnsolvable, count, err)
+	}
+}
+
+func TestSolveContextTimeout(t *testing.T) {
+	solvers := []struct {
+		name  string
+		solve func(ctx context.Context, samurai *SamuraiSudoku) (Grid, error)
+	}{
+		{"global", GlobalSolveSamuraiSudokuContext},
+		{"sequential", SolveSamuraiSudokuContext},
+		{"concurrent", ConcurrentSolveSamuraiSudokuContext},
+		{"double thread", DoubleThreadSolveSamuraiSudokuContext},
+	}
+
+	for _, solver := range solvers {
+		t.Run(solver.name, func(t *testing.T) {
+			var samurai SamuraiSudoku
+			samurai.SetGrid(NewSamuraiGrid())
+			ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+			defer cancel()
+			<-ctx.Done()
+
+			if _, err := solver.solve(ctx, &samurai); !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("want %v, got %v", context.DeadlineExceeded, err)
+			}
+		})
+	}
+}
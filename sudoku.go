@@ -2,20 +2,31 @@ package sudoku
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"github.com/wcharczuk/go-chart/v2"
-	"io/ioutil"
+	"io"
 	"log"
 	"math/rand"
 	"os"
-	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 var logger = log.New(os.Stdout, "", 0)
 
+//SetLogOutput sets where the solvers log their progress, os.Stdout by default
+func SetLogOutput(w io.Writer) {
+	logger.SetOutput(w)
+}
+
+// ErrUnsolvable is returned when a solver couldn't find a solution
+var ErrUnsolvable = errors.New("sudoku: no solution found")
+
+// Grid holds the cells of a puzzle's canvas row by row: -1 in the gaps between sub-sudokus, 0 in empty cells, digits from 1 elsewhere.
+// Empty cells that may only hold some digits, such as even ones, are given by the Restrictions of the puzzle's Variant
 type Grid [][]int
 
 //isSolved tells if this sudoku has been solved or not
@@ -39,6 +50,7 @@ type Move struct {
 	column   int
 	num      int // Number inserted
 	time     time.Time
+	layout   *Layout // Layout of the puzzle the Move was done in, Samurai if nil
 }
 
 func (m Move) String() string {
@@ -47,6 +59,51 @@ func (m Move) String() string {
 	return buf.String()
 }
 
+//Thread returns the id of the thread that made the move, the move's Position times 10 plus its ThreadId
+func (m Move) Thread() int {
+	return m.thread
+}
+
+//Position returns the Position of the sub-sudoku the move was made in
+func (m Move) Position() Position {
+	return m.position
+}
+
+//Row returns the row of the move within its sub-sudoku
+func (m Move) Row() int {
+	return m.row
+}
+
+//Column returns the column of the move within its sub-sudoku
+func (m Move) Column() int {
+	return m.column
+}
+
+//Num returns the value placed, 0 if the move took a value back
+func (m Move) Num() int {
+	return m.num
+}
+
+//Time returns when the move was made
+func (m Move) Time() time.Time {
+	return m.time
+}
+
+//GlobalCell returns the row and column of the move within the whole grid of its puzzle
+func (m Move) GlobalCell() (int, int) {
+	return m.globalCell()
+}
+
+//globalCell returns the row and column of the cell this Move was done in, within the whole grid of its puzzle
+func (m Move) globalCell() (int, int) {
+	layout := m.layout
+	if layout == nil {
+		layout = Samurai
+	}
+	y, x, _ := layout.Offset(m.position)
+	return y + m.row, x + m.column
+}
+
 type Tracker struct {
 	moves     []Move
 	startTime time.Time
@@ -59,66 +116,100 @@ func (t *Tracker) resetMoves() {
 
 //SamuraiGridFromFile reads a samurai sudoku grid from a given file
 func SamuraiGridFromFile(filePath string) Grid {
-	const samuraiLength = 21
-	grid := make(Grid, samuraiLength)
-
-	buffer, err := ioutil.ReadFile(filePath)
+	f, err := os.Open(filePath)
 	if err != nil {
 		logger.Printf("File couldn't be read!")
+		return nil
 	}
+	defer f.Close()
 
-	sudokuContents := string(buffer)
-
-	for j, line := range strings.Split(sudokuContents, "\n") {
-		charRow := strings.Split(line, "")
-		intRow := make([]int, samuraiLength, samuraiLength)
-		offset := 0
-		for i := 0; i < samuraiLength; i++ {
-			switch len(charRow) {
-			case 9:
-				if i < 6 || 15 <= i {
-					intRow[i] = -1
-					offset++
-				} else {
-					num, _ := strconv.Atoi(charRow[i-offset])
-
-					intRow[i] = num
-				}
-			case 18:
-				if 9 <= i && i < 12 {
-					intRow[i] = -1
-					offset++
-				} else {
-					num, _ := strconv.Atoi(charRow[i-offset])
-					intRow[i] = num
-				}
-
-			default:
-				num, _ := strconv.Atoi(charRow[i-offset])
-				intRow[i] = num
-			}
-		}
-
-		grid[j] = intRow
+	grid, err := ReadSamuraiGrid(f)
+	if err != nil {
+		logger.Printf("%v", err)
 	}
 	logger.Printf("Read \n%v\n", grid)
 	return grid
 }
 
+//NewSamuraiGrid returns an empty 21*21 samurai sudoku grid, with the gaps between sub-sudokus set to -1
+func NewSamuraiGrid() Grid {
+	return Samurai.NewGrid()
+}
+
 type SamuraiSudoku struct {
-	mu          sync.Mutex
-	grid        Grid
-	initialGrid Grid
-	tracker     Tracker
+	mu               sync.Mutex
+	layout           *Layout         // Placement of the sub-sudokus, Samurai if nil
+	variant          *Variant        // Rules added to the sub-sudokus', none if nil
+	cageIndex        map[Cell][]int  // Cages of the variant every caged cell is in
+	markerIndex      map[Cell][]int  // Markers of the variant every marked cell is in
+	constraintIndex  map[Cell][]int  // Constraints of the variant every cell is in
+	restrictionMasks map[Cell]uint32 // Digits every cell restricted by the variant may hold, as a bitmask
+	grid             Grid
+	initialGrid      Grid
+	tracker          Tracker
+	searchTree       *SearchTree
+	ctx              context.Context // Context of the running solver, nil when not solving
+	observers        []Observer
+}
+
+//Layout returns the layout of the puzzle, Samurai unless another one was set
+func (s *SamuraiSudoku) Layout() *Layout {
+	if s.layout == nil {
+		return Samurai
+	}
+	return s.layout
+}
+
+//SetLayout sets the layout of the puzzle, the grid set afterwards must be shaped like it
+func (s *SamuraiSudoku) SetLayout(layout *Layout) {
+	s.layout = layout
+}
+
+//Variant returns the rules the puzzle adds to those of its sub-sudokus, nil if it adds none
+func (s *SamuraiSudoku) Variant() *Variant {
+	return s.variant
+}
+
+//SetVariant sets the rules the puzzle adds to those of its sub-sudokus, nil for none.
+//It returns an error wrapping ErrInvalidLayout if the variant has rules for sub-sudokus the puzzle's layout doesn't have
+func (s *SamuraiSudoku) SetVariant(variant *Variant) error {
+	if err := variant.check(s.Layout()); err != nil {
+		return err
+	}
+	s.variant = variant
+	s.cageIndex, s.markerIndex, s.constraintIndex, s.restrictionMasks = nil, nil, nil, nil
+	if variant != nil {
+		s.cageIndex = cageIndex(variant.Cages)
+		s.markerIndex = markerIndex(variant.Markers)
+		s.constraintIndex = constraintIndex(variant.Constraints)
+		s.restrictionMasks = restrictionMasks(variant.Restrictions)
+	}
+	return nil
+}
+
+//setContext sets the context the backtracking solvers check for cancellation
+func (s *SamuraiSudoku) setContext(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ctx = ctx
+}
+
+//cancelled tells if the running solver should give up, should be called while holding s.mu
+func (s *SamuraiSudoku) cancelled() bool {
+	return s.ctx != nil && s.ctx.Err() != nil
 }
 
 func (s *SamuraiSudoku) ResetGrid() {
 	s.tracker.resetMoves()
+	s.searchTree.reset()
 	for i, row := range s.initialGrid {
 		for j, num := range row {
 			s.grid[i][j] = num
 		}
 	}
+	for _, observer := range s.observers {
+		observer.Reset(s.grid)
+	}
 }
 
 func (s *SamuraiSudoku) Grid() Grid {
@@ -144,6 +235,14 @@ const (
 	Centre
 	BottomLeft
 	BottomRight
+	Top
+	Bottom
+	Left
+	Right
+	InnerTopLeft
+	InnerTopRight
+	InnerBottomLeft
+	InnerBottomRight
 )
 
 type ThreadId int
@@ -165,19 +264,59 @@ func (p Position) String() string {
 		return "bottom left"
 	case BottomRight:
 		return "bottom right"
+	case Top:
+		return "top"
+	case Bottom:
+		return "bottom"
+	case Left:
+		return "left"
+	case Right:
+		return "right"
+	case InnerTopLeft:
+		return "inner top left"
+	case InnerTopRight:
+		return "inner top right"
+	case InnerBottomLeft:
+		return "inner bottom left"
+	case InnerBottomRight:
+		return "inner bottom right"
 	}
 	return "unknown"
 }
 
+//ParsePosition returns the Position named name, as returned by Position.String, with spaces or dashes between words
+func ParsePosition(name string) (Position, error) {
+	name = strings.ReplaceAll(strings.TrimSpace(name), "-", " ")
+	for p := TopLeft; p <= InnerBottomRight; p++ {
+		if strings.EqualFold(name, p.String()) {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("sudoku: unknown position %q", name)
+}
+
+//String writes the grid a row per line, digits above 9 as letters from A, see Layout.Format for other alphabets
 func (g Grid) String() string {
+	return formatGrid(g, digitSymbols+letterSymbols)
+}
+
+//Format writes grid, shaped like the layout, a row per line with the layout's Symbols, e.g. A-Y for 25x25 sub-sudokus
+func (l *Layout) Format(grid Grid) string {
+	return formatGrid(grid, l.symbols)
+}
+
+func formatGrid(g Grid, symbols string) string {
 	var buf bytes.Buffer
 	var char string
 	for _, row := range g {
 		for _, num := range row {
-			if num == -1 {
+			switch num {
+			case -1:
 				char = " "
-			} else {
-				char = strconv.Itoa(num)
+			case 0:
+				char = "0"
+			default:
+				char = symbol(symbols, num)
 			}
 			_, err := fmt.Fprint(&buf, char, " ")
 			if err != nil {
@@ -192,105 +331,166 @@ func (g Grid) String() string {
 	return buf.String()
 }
 
-//GetSubSudoku returns sub-sudoku for given position, assuming 21*21 samurai sudoku grid
+//GetSubSudoku returns sub-sudoku for given position, sharing its cells with the grid.
+//It returns nil if the puzzle's layout has no sub-sudoku at position
 func (s *SamuraiSudoku) GetSubSudoku(position Position) Grid {
-	var grid = s.grid
-	subSudoku := make(Grid, 9)
-	var tmp Grid
-	switch position {
-	case TopLeft:
-		tmp = grid[0:9]
-		for i := range tmp {
-			subSudoku[i] = tmp[i][0:9]
-		}
-	case TopRight:
-		tmp = grid[0:9]
-		for i := range tmp {
-			subSudoku[i] = tmp[i][12:21]
-		}
-	case Centre:
-		tmp = grid[6:15]
-		for i := range tmp {
-			subSudoku[i] = tmp[i][6:15]
-		}
-
-	case BottomLeft:
-		tmp = grid[12:21]
-		for i := range tmp {
-			subSudoku[i] = tmp[i][0:9]
-		}
-
-	case BottomRight:
-		tmp = grid[12:21]
-		for i := range tmp {
-			subSudoku[i] = tmp[i][12:21]
-		}
+	layout := s.Layout()
+	y0, x0, ok := layout.Offset(position)
+	if !ok {
+		return nil
+	}
+	subSudoku := make(Grid, layout.size)
+	for i := range subSudoku {
+		subSudoku[i] = s.grid[y0+i][x0 : x0+layout.size]
 	}
 	return subSudoku
 }
 
+//subSudokus returns every sub-sudoku of the puzzle's layout with its position, in the layout's order
+func (s *SamuraiSudoku) subSudokus() []struct {
+	position Position
+	sudoku   Grid
+} {
+	var subSudokus []struct {
+		position Position
+		sudoku   Grid
+	}
+	for _, position := range s.Layout().Positions() {
+		subSudokus = append(subSudokus, struct {
+			position Position
+			sudoku   Grid
+		}{position, s.GetSubSudoku(position)})
+	}
+	return subSudokus
+}
+
+// unit A row, column, box, jigsaw region, diagonal or window of a sub-sudoku, whose cells must all hold different digits
+type unit struct {
+	position Position
+	kind     string
+	index    int // Index of the row, column, box, region or window within the sub-sudoku
+	cells    []Cell
+}
+
 func (s *SamuraiSudoku) recordMove(id ThreadId, position Position, y int, x int, n int) {
-	s.tracker.moves = append(s.tracker.moves, Move{
+	move := Move{
 		thread:   int(position)*10 + int(id),
 		position: position,
 		row:      y,
 		column:   x,
 		num:      n,
 		time:     time.Now().Add(time.Now().Sub(s.tracker.startTime)),
-	})
+		layout:   s.layout,
+	}
+	s.tracker.moves = append(s.tracker.moves, move)
+	for _, observer := range s.observers {
+		observer.Moved(move)
+	}
+}
+
+// Observer is told about everything the solvers do to a SamuraiSudoku.
+// Its methods are called while the sudoku is locked, so they hold every solving thread up until they return
+type Observer interface {
+	Moved(move Move) // A value was placed, or taken back if move.Num() is 0
+	Reset(grid Grid) // The grid was reset to its initial values for a new solving attempt
+}
+
+//Observe makes observer be told about every move made from now on, until the returned function is called
+func (s *SamuraiSudoku) Observe(observer Observer) (stop func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observers = append(s.observers, observer)
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, o := range s.observers {
+			if o == observer {
+				s.observers = append(s.observers[:i:i], s.observers[i+1:]...)
+				return
+			}
+		}
+	}
 }
 
 func (s *SamuraiSudoku) moves() bytes.Buffer {
+	buf := bytes.Buffer{}
+	WriteTrace(&buf, s)
+	return buf
+}
+
+//WriteTrace writes the moves recorded while solving samurai to w as CSV
+func WriteTrace(w io.Writer, samurai *SamuraiSudoku) error {
 	buf := bytes.Buffer{}
 	fmt.Fprintf(&buf, "time (microseconds),thread id, position, row, colmun, value\n")
-	for _, move := range s.tracker.moves {
+	for _, move := range samurai.trace() {
 		fmt.Fprintf(&buf, "%s\n", move.String())
 	}
-	return buf
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+//trace returns a copy of the moves recorded so far
+func (s *SamuraiSudoku) trace() []Move {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	moves := make([]Move, len(s.tracker.moves))
+	copy(moves, s.tracker.moves)
+	return moves
 }
 
 //SolveSamuraiSudoku solves 21*21 samurai sudoku
 func SolveSamuraiSudoku(samurai *SamuraiSudoku) Grid {
+	grid, _ := SolveSamuraiSudokuContext(context.Background(), samurai)
+	return grid
+}
+
+//SolveSamuraiSudokuContext solves 21*21 samurai sudoku one sub-sudoku after the other, giving up once ctx is done.
+//It returns ErrUnsolvable if a sub-sudoku couldn't be completed given the ones solved before it
+func SolveSamuraiSudokuContext(ctx context.Context, samurai *SamuraiSudoku) (Grid, error) {
+	samurai.setContext(ctx)
+	defer samurai.setContext(nil)
 
 	// get all subsudokus
-	subSudokus := []struct {
-		position Position
-		sudoku   Grid
-	}{
-		{TopLeft, samurai.GetSubSudoku(TopLeft)},
-		{TopRight, samurai.GetSubSudoku(TopRight)},
-		{Centre, samurai.GetSubSudoku(Centre)},
-		{BottomLeft, samurai.GetSubSudoku(BottomLeft)},
-		{BottomRight, samurai.GetSubSudoku(BottomRight)},
-	}
+	subSudokus := samurai.subSudokus()
 
 	// iterate over the map until all subsudokus are solved
 	for _, subSudoku := range subSudokus {
 		SolveSudoku(subSudoku.sudoku, subSudoku.position, samurai)
 	}
 
-	return samurai.Grid()
+	if err := ctx.Err(); err != nil {
+		return samurai.Grid(), err
+	}
+	if !samurai.Grid().isSolved() {
+		return samurai.Grid(), ErrUnsolvable
+	}
+	return samurai.Grid(), nil
 }
 
 //ConcurrentSolveSamuraiSudoku solves 21*21 samurai sudoku concurrently
 func ConcurrentSolveSamuraiSudoku(samurai *SamuraiSudoku) Grid {
-	rand.Seed(time.Now().UnixNano())
+	grid, _ := ConcurrentSolveSamuraiSudokuContext(context.Background(), samurai)
+
+	moves := samurai.moves()
+	os.WriteFile("sudoku.log", moves.Bytes(), 0666)
+	logger.Printf("attempt %d\n%v\n", TotalSolvingAttempts(), samurai.Grid())
+
+	return grid
+}
+
+//ConcurrentSolveSamuraiSudokuContext solves 21*21 samurai sudoku concurrently, giving up once ctx is done
+func ConcurrentSolveSamuraiSudokuContext(ctx context.Context, samurai *SamuraiSudoku) (Grid, error) {
+	samurai.setContext(ctx)
+	defer samurai.setContext(nil)
+	// a source of its own, as solvers may run at once, for instance for the requests of a server
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
 	// get all subsudokus
 	getSubSudokus := func() []struct {
 		position Position
 		sudoku   Grid
 	} {
-		subSudokus := []struct {
-			position Position
-			sudoku   Grid
-		}{
-			{TopLeft, samurai.GetSubSudoku(TopLeft)},
-			{TopRight, samurai.GetSubSudoku(TopRight)},
-			{Centre, samurai.GetSubSudoku(Centre)},
-			{BottomLeft, samurai.GetSubSudoku(BottomLeft)},
-			{BottomRight, samurai.GetSubSudoku(BottomRight)},
-		}
-		rand.Shuffle(len(subSudokus), func(i, j int) {
+		subSudokus := samurai.subSudokus()
+		rng.Shuffle(len(subSudokus), func(i, j int) {
 			subSudokus[i], subSudokus[j] = subSudokus[j], subSudokus[i]
 		})
 		return subSudokus
@@ -300,41 +500,45 @@ func ConcurrentSolveSamuraiSudoku(samurai *SamuraiSudoku) Grid {
 
 	// iterate over the map until all subsudokus are solved
 	for !samurai.Grid().isSolved() {
+		if err := ctx.Err(); err != nil {
+			return samurai.Grid(), err
+		}
 		samurai.mu.Lock()
 		samurai.ResetGrid()
 		subSudokus := getSubSudokus()
 		// reset samurai grid
 		samurai.mu.Unlock()
 		solvingLoop(samurai, subSudokus, wg)
-		SolvingAttempts++
+		atomic.AddInt64(&solvingAttempts, 1)
 	}
 
-	moves := samurai.moves()
-	os.WriteFile("sudoku.log", moves.Bytes(), 0666)
-	logger.Printf("attempt %d\n%v\n", SolvingAttempts, samurai.Grid())
-
-	return samurai.Grid()
+	return samurai.Grid(), nil
 }
 
 //DoubleThreadSolveSamuraiSudoku solves 21*21 samurai sudoku concurrently
 func DoubleThreadSolveSamuraiSudoku(samurai *SamuraiSudoku) Grid {
-	rand.Seed(time.Now().UnixNano())
+	grid, _ := DoubleThreadSolveSamuraiSudokuContext(context.Background(), samurai)
+
+	moves := samurai.moves()
+	os.WriteFile("sudoku.csv", moves.Bytes(), 0666)
+	logger.Printf("attempt %d\n%v\n", TotalSolvingAttempts(), samurai.Grid())
+
+	return grid
+}
+
+//DoubleThreadSolveSamuraiSudokuContext solves 21*21 samurai sudoku concurrently, giving up once ctx is done
+func DoubleThreadSolveSamuraiSudokuContext(ctx context.Context, samurai *SamuraiSudoku) (Grid, error) {
+	samurai.setContext(ctx)
+	defer samurai.setContext(nil)
+	// a source of its own, as solvers may run at once, for instance for the requests of a server
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
 	// get all subsudokus
 	getSubSudokus := func() []struct {
 		position Position
 		sudoku   Grid
 	} {
-		subSudokus := []struct {
-			position Position
-			sudoku   Grid
-		}{
-			{TopLeft, samurai.GetSubSudoku(TopLeft)},
-			{TopRight, samurai.GetSubSudoku(TopRight)},
-			{Centre, samurai.GetSubSudoku(Centre)},
-			{BottomLeft, samurai.GetSubSudoku(BottomLeft)},
-			{BottomRight, samurai.GetSubSudoku(BottomRight)},
-		}
-		rand.Shuffle(len(subSudokus), func(i, j int) {
+		subSudokus := samurai.subSudokus()
+		rng.Shuffle(len(subSudokus), func(i, j int) {
 			subSudokus[i], subSudokus[j] = subSudokus[j], subSudokus[i]
 		})
 		return subSudokus
@@ -344,23 +548,28 @@ func DoubleThreadSolveSamuraiSudoku(samurai *SamuraiSudoku) Grid {
 
 	// iterate over the map until all subsudokus are solved
 	for !samurai.Grid().isSolved() {
+		if err := ctx.Err(); err != nil {
+			return samurai.Grid(), err
+		}
 		samurai.mu.Lock()
 		samurai.ResetGrid()
 		subSudokus := getSubSudokus()
 		// reset samurai grid
 		samurai.mu.Unlock()
 		doubleSolvingLoop(samurai, subSudokus, wg)
-		SolvingAttempts++
+		atomic.AddInt64(&solvingAttempts, 1)
 	}
 
-	moves := samurai.moves()
-	os.WriteFile("sudoku.csv", moves.Bytes(), 0666)
-	logger.Printf("attempt %d\n%v\n", SolvingAttempts, samurai.Grid())
-
-	return samurai.Grid()
+	return samurai.Grid(), nil
 }
 
-var SolvingAttempts = 0
+// solvingAttempts counts the attempts of the concurrent solvers at filling the grid, updated atomically as solvers may run at once
+var solvingAttempts int64
+
+//TotalSolvingAttempts returns how many times the concurrent solvers have tried filling the grid, across every puzzle they solved
+func TotalSolvingAttempts() int {
+	return int(atomic.LoadInt64(&solvingAttempts))
+}
 
 func solvingLoop(samurai *SamuraiSudoku, subSudokus []struct {
 	position Position
@@ -405,71 +614,68 @@ func doubleSolvingLoop(samurai *SamuraiSudoku, subSudokus []struct {
 
 //possible checks if index y,x in grid position can be filled with n in all subsudokus it's in
 func possible(sudoku Grid, y int, x int, n int, position Position, samuraiSudoku *SamuraiSudoku) bool {
-	var sharedSudoku Grid
-	var yShared, xShared int
-	switch position {
-	case TopLeft:
-		if 6 <= y && y < 9 && 6 <= x && x < 9 {
-			sharedSudoku = samuraiSudoku.GetSubSudoku(Centre)
-			yShared, xShared = y-6, x-6
+	layout, variant := samuraiSudoku.Layout(), samuraiSudoku.variant
+	if !possibleSubSudoku(sudoku, y, x, n, position, layout, variant) {
+		return false
+	}
+	y0, x0, _ := layout.Offset(position)
+	// the cell is shared with every other sub-sudoku overlapping it
+	for _, shared := range layout.placements(y0+y, x0+x) {
+		if shared.position == position {
+			continue
 		}
-	case TopRight:
-		if 6 <= y && y < 9 && 0 <= x && x < 3 {
-			sharedSudoku = samuraiSudoku.GetSubSudoku(Centre)
-			yShared, xShared = y-6, x+6
-		}
-
-	case Centre:
-		if 0 <= y && y < 3 && 0 <= x && x < 3 {
-			sharedSudoku = samuraiSudoku.GetSubSudoku(TopLeft)
-			yShared, xShared = y+6, x+6
-		} else if 0 <= y && y < 3 && 6 <= x && x < 9 {
-			sharedSudoku = samuraiSudoku.GetSubSudoku(TopRight)
-			yShared, xShared = y+6, x-6
-		} else if 6 <= y && y < 9 && 0 <= x && x < 3 {
-			sharedSudoku = samuraiSudoku.GetSubSudoku(BottomLeft)
-			yShared, xShared = y-6, x+6
-		} else if 6 <= y && y < 9 && 6 <= x && x < 9 {
-			sharedSudoku = samuraiSudoku.GetSubSudoku(BottomRight)
-			yShared, xShared = y-6, x-6
+		if !possibleSubSudoku(samuraiSudoku.GetSubSudoku(shared.position), shared.row, shared.column, n, shared.position, layout, variant) {
+			return false
 		}
+	}
+	if variant != nil && !possibleRestrictions(y0+y, x0+x, n, samuraiSudoku.restrictionMasks) {
+		return false
+	}
+	if variant != nil && !possibleCages(samuraiSudoku.grid, y0+y, x0+x, n, variant.Cages, samuraiSudoku.cageIndex, layout.size) {
+		return false
+	}
+	if variant != nil && !possibleMarkers(samuraiSudoku.grid, y0+y, x0+x, n, variant, samuraiSudoku.markerIndex) {
+		return false
+	}
+	if variant != nil && !possibleConstraints(samuraiSudoku.grid, y0+y, x0+x, n, variant.Constraints, samuraiSudoku.constraintIndex, layout.size) {
+		return false
+	}
+	return true
+}
 
-	case BottomLeft:
-		if 0 <= y && y < 3 && 6 <= x && x < 9 {
-			sharedSudoku = samuraiSudoku.GetSubSudoku(Centre)
-			yShared, xShared = y+6, x-6
-		}
-	case BottomRight:
-		if 0 <= y && y < 3 && 0 <= x && x < 3 {
-			sharedSudoku = samuraiSudoku.GetSubSudoku(Centre)
-			yShared, xShared = y+6, x+6
+//possibleSubSudoku checks if sudoku, the sub-sudoku in position, can be filled in position y,x with n
+//given its rows, columns, boxes or jigsaw regions, diagonals and windows
+func possibleSubSudoku(sudoku Grid, y int, x int, n int, position Position, layout *Layout, variant *Variant) bool {
+	if regions := variant.regions(position); regions != nil {
+		if !possibleRegions(sudoku, regions, y, x, n) {
+			return false
 		}
-
+	} else if !possibleSudoku(sudoku, layout.box, y, x, n) {
+		return false
 	}
-	if len(sharedSudoku) == 0 {
-		return possibleSudoku(sudoku, y, x, n)
-	} else {
-		return possibleSudoku(sudoku, y, x, n) && possibleSudoku(sharedSudoku, yShared, xShared, n)
+	if variant.window(position) && !possibleWindows(sudoku, layout.box, y, x, n) {
+		return false
 	}
+	return !variant.diagonal(position) || possibleDiagonals(sudoku, y, x, n)
 }
 
-//possibleSudoku checks if sudoku can be filled in position y,x with n
-func possibleSudoku(sudoku Grid, y int, x int, n int) bool {
-	for i := 0; i < 9; i++ {
+//possibleSudoku checks if sudoku, made of boxes shaped like box, can be filled in position y,x with n
+func possibleSudoku(sudoku Grid, box Box, y int, x int, n int) bool {
+	for i := range sudoku {
 		if sudoku[y][i] == n {
 			return false
 		}
 	}
-	for i := 0; i < 9; i++ {
+	for i := range sudoku {
 		if sudoku[i][x] == n {
 			return false
 		}
 	}
-	x0 := (x / 3) * 3
-	y0 := (y / 3) * 3
+	x0 := (x / box.Columns) * box.Columns
+	y0 := (y / box.Rows) * box.Rows
 
-	for i := 0; i < 3; i++ {
-		for j := 0; j < 3; j++ {
+	for i := 0; i < box.Rows; i++ {
+		for j := 0; j < box.Columns; j++ {
 			if sudoku[y0+i][x0+j] == n {
 				return false
 			}
@@ -481,14 +687,18 @@ func possibleSudoku(sudoku Grid, y int, x int, n int) bool {
 //concurrentSolveSudoku solves 9x9 subsudoku in specified position within samuraiSudoku, concurrently
 func concurrentSolveSudoku(threadId ThreadId, sudoku Grid, position Position, samuraiSudoku *SamuraiSudoku, wg *sync.WaitGroup) Grid {
 	// TODO: fix some sudokus not solving.
-	if sudoku.isSolved() {
+	// the other threads write the cells shared with their sub-sudokus under the lock
+	samuraiSudoku.mu.Lock()
+	solved := sudoku.isSolved()
+	samuraiSudoku.mu.Unlock()
+	if solved {
 		wg.Done()
 		return sudoku
 	}
 	if threadId == Thread1 {
-		reverseBacktrack(threadId, sudoku, position, samuraiSudoku)
+		reverseBacktrack(threadId, sudoku, position, samuraiSudoku, samuraiSudoku.searchRoot())
 	} else {
-		backtrack(threadId, sudoku, position, samuraiSudoku)
+		backtrack(threadId, sudoku, position, samuraiSudoku, samuraiSudoku.searchRoot())
 	}
 
 	wg.Done()
@@ -498,27 +708,34 @@ func concurrentSolveSudoku(threadId ThreadId, sudoku Grid, position Position, sa
 
 //SolveSudoku solves 9x9 subsudoku in position within samuraiSudoku
 func SolveSudoku(sudoku Grid, position Position, samuraiSudoku *SamuraiSudoku) Grid {
-	backtrack(Thread1, sudoku, position, samuraiSudoku)
+	backtrack(Thread1, sudoku, position, samuraiSudoku, samuraiSudoku.searchRoot())
 	return sudoku
 }
 
 //backtrack keeps attempting values recursively until 9x9 sudoku is solved completely
-func backtrack(threadId ThreadId, sudoku Grid, position Position, samuraiSudoku *SamuraiSudoku) bool {
-	for y := 0; y < 9; y++ {
-		for x := 0; x < 9; x++ {
+//parent is the search tree node the attempts are recorded under, nil if the search tree isn't being recorded
+func backtrack(threadId ThreadId, sudoku Grid, position Position, samuraiSudoku *SamuraiSudoku, parent *SearchNode) bool {
+	for y := range sudoku {
+		for x := range sudoku[y] {
 			// if cell is empty
 			//logger.Printf("%s: waiting for lock...", position)
 			samuraiSudoku.mu.Lock()
 			//logger.Printf("%s: locked", position)
+			if samuraiSudoku.cancelled() {
+				samuraiSudoku.mu.Unlock()
+				return false
+			}
 			if sudoku[y][x] == 0 {
-				for n := 1; n < 10; n++ {
+				for n := 1; n <= len(sudoku); n++ {
 					if possible(sudoku, y, x, n, position, samuraiSudoku) {
 						samuraiSudoku.recordMove(threadId, position, y, x, n)
+						node := samuraiSudoku.recordSearchNode(parent, position, y, x, n)
 						sudoku[y][x] = n
 						samuraiSudoku.mu.Unlock()
 						//logger.Printf("%s: set sudoku[%d, %d] = %d", position, y, x, n)
-						if backtrack(threadId, sudoku, position, samuraiSudoku) {
+						if backtrack(threadId, sudoku, position, samuraiSudoku, node) {
 							// should be unlocked here, but could get locked by other threads
+							node.succeed()
 							return true
 						}
 						//logger.Printf("%s: waiting for lock for 0", position)
@@ -543,22 +760,29 @@ func backtrack(threadId ThreadId, sudoku Grid, position Position, samuraiSudoku
 }
 
 //reverseBacktrack keeps attempting values recursively until 9x9 sudoku is solved completely from the bottom
-func reverseBacktrack(threadId ThreadId, sudoku Grid, position Position, samuraiSudoku *SamuraiSudoku) bool {
-	for y := 8; y >= 0; y-- {
-		for x := 8; x >= 0; x-- {
+//parent is the search tree node the attempts are recorded under, nil if the search tree isn't being recorded
+func reverseBacktrack(threadId ThreadId, sudoku Grid, position Position, samuraiSudoku *SamuraiSudoku, parent *SearchNode) bool {
+	for y := len(sudoku) - 1; y >= 0; y-- {
+		for x := len(sudoku[y]) - 1; x >= 0; x-- {
 			// if cell is empty
 			//logger.Printf("%s: waiting for lock...", position)
 			samuraiSudoku.mu.Lock()
 			//logger.Printf("%s: locked", position)
+			if samuraiSudoku.cancelled() {
+				samuraiSudoku.mu.Unlock()
+				return false
+			}
 			if sudoku[y][x] == 0 {
-				for n := 1; n < 10; n++ {
+				for n := 1; n <= len(sudoku); n++ {
 					if possible(sudoku, y, x, n, position, samuraiSudoku) {
 						samuraiSudoku.recordMove(threadId, position, y, x, n)
+						node := samuraiSudoku.recordSearchNode(parent, position, y, x, n)
 						sudoku[y][x] = n
 						samuraiSudoku.mu.Unlock()
 						//logger.Printf("%s: set sudoku[%d, %d] = %d", position, y, x, n)
-						if reverseBacktrack(threadId, sudoku, position, samuraiSudoku) {
+						if reverseBacktrack(threadId, sudoku, position, samuraiSudoku, node) {
 							// should be unlocked here, but could get locked by other threads
+							node.succeed()
 							return true
 						}
 						//logger.Printf("%s: waiting for lock for 0", position)
@@ -581,71 +805,3 @@ func reverseBacktrack(threadId ThreadId, sudoku Grid, position Position, samurai
 	//samuraiSudoku.mu.Unlock()
 	return true
 }
-
-func WriteGraph(samurai *SamuraiSudoku) {
-	stats := samurai.tracker.moves
-
-	var xValues []float64
-	var yValues []float64
-	startingTime := samurai.tracker.moves[0].time
-
-	for i, stat := range stats {
-		xValues = append(xValues, float64(stat.time.Sub(startingTime).Nanoseconds()))
-		yValues = append(yValues, float64(i))
-	}
-
-	timeGraph := chart.Chart{
-		Title: "Time (ms) and number of moves",
-		XAxis: chart.XAxis{
-			ValueFormatter: func(v interface{}) string {
-				return strconv.FormatInt(int64(v.(float64)/1000000), 10)
-			},
-		},
-		Series: []chart.Series{
-			chart.ContinuousSeries{
-				XValues: xValues,
-				YValues: yValues,
-			},
-		},
-	}
-
-	f, _ := os.Create("output.png")
-	defer f.Close()
-	timeGraph.Render(chart.PNG, f)
-
-}
-
-func WriteMultiThreadedGraph(samurai *SamuraiSudoku) {
-	stats := samurai.tracker.moves
-
-	var xValues []float64
-	var yValues []float64
-
-	startingTime := samurai.tracker.moves[0].time
-
-	for i, stat := range stats {
-
-		xValues = append(xValues, float64(stat.time.Sub(startingTime).Nanoseconds()))
-		yValues = append(yValues, float64(i))
-
-	}
-
-	timeGraph := chart.Chart{
-		Title: "Time (ms) and number of moves",
-		XAxis: chart.XAxis{
-			ValueFormatter: func(v interface{}) string {
-				return strconv.FormatInt(int64(v.(float64)/1000000), 10)
-			},
-		},
-		Series: []chart.Series{
-			chart.ContinuousSeries{
-				XValues: xValues,
-				YValues: yValues,
-			},
-		},
-	}
-
-	f, _ := os.Create("output.png")
-	defer f.Close()
-	timeGraph.Render(chart.PNG, f)
-}
@@ -420,3 +420,54 @@ func TestDoubleThreadSolveSamuraiSudoku(t *testing.T) {
 		}
 	}
 }
+
+//newTestSamurai returns a samurai sudoku loaded with the puzzle in sudoku.txt
+func newTestSamurai() *SamuraiSudoku {
+	var samuraiSudoku SamuraiSudoku
+	samuraiSudoku.SetGrid(SamuraiGridFromFile("sudoku.txt"))
+	return &samuraiSudoku
+}
+
+// recorder An Observer keeping everything it is told
+type recorder struct {
+	moves  []Move
+	resets int
+}
+
+func (r *recorder) Moved(move Move) {
+	r.moves = append(r.moves, move)
+}
+
+func (r *recorder) Reset(grid Grid) {
+	r.resets++
+	r.moves = nil
+}
+
+func TestObserve(t *testing.T) {
+	samurai := newTestSamurai()
+	observer := &recorder{}
+	stop := samurai.Observe(observer)
+	ConcurrentSolveSamuraiSudoku(samurai)
+
+	if observer.resets == 0 {
+		t.Fatalf("want the grid reset before solving")
+	}
+	if got, want := len(observer.moves), len(samurai.trace()); got != want {
+		t.Fatalf("want %d moves since the last reset, got %d", want, got)
+	}
+	for i, move := range samurai.trace() {
+		if observer.moves[i] != move {
+			t.Fatalf("move %d: want %v, got %v", i, move, observer.moves[i])
+		}
+	}
+
+	stop()
+	observer.moves, observer.resets = nil, 0
+	samurai.mu.Lock()
+	samurai.ResetGrid()
+	samurai.mu.Unlock()
+	SolveSamuraiSudoku(samurai)
+	if len(observer.moves) != 0 || observer.resets != 0 {
+		t.Fatalf("want nothing told after stopping, got %d moves and %d resets", len(observer.moves), observer.resets)
+	}
+}
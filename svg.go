@@ -0,0 +1,92 @@
+package sudoku
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// svgPen draws a puzzle's picture as SVG elements, scale user units to a cell
+type svgPen struct {
+	b          bytes.Buffer
+	scale      float64
+	fontFamily string
+}
+
+func newSVGPen(width float64, height float64, scale float64, options ImageOptions) *svgPen {
+	p := &svgPen{scale: scale, fontFamily: options.FontFamily}
+	if p.fontFamily == "" {
+		p.fontFamily = "sans-serif"
+	}
+	w, h := p.number(width), p.number(height)
+	fmt.Fprintf(&p.b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n", w, h, w, h)
+	fmt.Fprintf(&p.b, `<g transform="translate(%s %s)" font-family="%s" text-anchor="middle">`+"\n",
+		p.number(imageMargin), p.number(imageMargin), escapeXML(p.fontFamily))
+	return p
+}
+
+//encode writes the picture's SVG document to w
+func (p *svgPen) encode(w io.Writer) error {
+	_, err := io.WriteString(w, p.b.String()+"</g>\n</svg>\n")
+	return err
+}
+
+func (p *svgPen) polygon(points []point, c color.RGBA) {
+	fmt.Fprintf(&p.b, `<polygon points="%s" fill="%s"/>`+"\n", p.points(points), svgColour(c))
+}
+
+func (p *svgPen) polyline(points []point, width float64, c color.RGBA, dashed bool) {
+	var dash string
+	if dashed {
+		dash = fmt.Sprintf(` stroke-dasharray="%s"`, p.number(1.0/12))
+	}
+	fmt.Fprintf(&p.b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="%s" stroke-linecap="round" stroke-linejoin="round"%s/>`+"\n",
+		p.points(points), svgColour(c), p.number(width), dash)
+}
+
+func (p *svgPen) disc(centre point, r float64, c color.RGBA) {
+	fmt.Fprintf(&p.b, `<circle cx="%s" cy="%s" r="%s" fill="%s"/>`+"\n", p.number(centre.x), p.number(centre.y), p.number(r), svgColour(c))
+}
+
+func (p *svgPen) circle(centre point, r float64, width float64, c color.RGBA) {
+	fmt.Fprintf(&p.b, `<circle cx="%s" cy="%s" r="%s" fill="none" stroke="%s" stroke-width="%s"/>`+"\n",
+		p.number(centre.x), p.number(centre.y), p.number(r), svgColour(c), p.number(width))
+}
+
+func (p *svgPen) text(centre point, text string, size float64, bold bool, c color.RGBA) {
+	var weight string
+	if bold {
+		weight = ` font-weight="bold"`
+	}
+	// the baseline sits half the height of a digit, about 0.7 of the font's, below the middle
+	fmt.Fprintf(&p.b, `<text x="%s" y="%s" font-size="%s"%s fill="%s">%s</text>`+"\n",
+		p.number(centre.x), p.number(centre.y+0.35*size), p.number(size), weight, svgColour(c), escapeXML(text))
+}
+
+func (p *svgPen) points(points []point) string {
+	s := make([]string, len(points))
+	for i, pt := range points {
+		s[i] = p.number(pt.x) + "," + p.number(pt.y)
+	}
+	return strings.Join(s, " ")
+}
+
+//number writes a length in cells as user units, to a hundredth
+func (p *svgPen) number(cells float64) string {
+	return strconv.FormatFloat(math.Round(cells*p.scale*100)/100, 'f', -1, 64)
+}
+
+func svgColour(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func escapeXML(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
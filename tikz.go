@@ -0,0 +1,240 @@
+package sudoku
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// TikZOptions configures the TikZ picture WriteTikZ writes
+type TikZOptions struct {
+	CellSize   float64     // Side of a cell in centimetres, defaults to 0.6
+	Solution   Grid        // Solved grid whose digits are written in the puzzle's empty cells, lighter than the givens
+	Candidates *Candidates // Candidates written small in the empty cells the solution doesn't fill, as pencil marks
+	Document   bool        // Wraps the picture in a standalone LaTeX document, ready to compile on its own
+}
+
+const defaultTikZCellSize = 0.6
+
+// Font sizes of the digits and the candidates of a TikZ picture, in cells, the candidates' for a box one cell wide
+const (
+	tikzDigitSize     = 0.6
+	tikzCandidateSize = 0.75
+)
+
+// pointsPerCentimetre converts the font sizes of TikZ pictures, which LaTeX takes in points
+const pointsPerCentimetre = 72.27 / 2.54
+
+var tikzCandidateColour = color.RGBA{R: 0x60, G: 0x60, B: 0x60, A: 0xff}
+
+//WriteTikZ writes samurai as a TikZ picture to w, for LaTeX documents to typeset: the overlaps' shading, a scope for each
+//sub-sudoku with the lines of its cells, boxes, or jigsaw regions, and its frame, the elements of its variant, then a scope for
+//the digits of each sub-sudoku, the givens in bold. Coordinates are canvas cells, y growing down, so that the picture is easily
+//edited, and the styles of its lines and digits can be set anew by the document. Gaps are left blank.
+//It returns ErrInvalidGrid if the puzzle's grid, or the solution, isn't shaped like its layout
+func WriteTikZ(w io.Writer, samurai *SamuraiSudoku, options TikZOptions) error {
+	layout := samurai.Layout()
+	grid := samurai.Grid()
+	if err := checkShape(grid, layout); err != nil {
+		return err
+	}
+	if options.Solution != nil {
+		if err := checkShape(options.Solution, layout); err != nil {
+			return fmt.Errorf("solution: %w", err)
+		}
+	}
+	cellSize := options.CellSize
+	if cellSize <= 0 {
+		cellSize = defaultTikZCellSize
+	}
+
+	p := &tikzPen{cellSize: cellSize}
+	b := &p.b
+	if options.Document {
+		b.WriteString("\\documentclass[tikz, border=2mm]{standalone}\n\\usepackage{lmodern}\n\\begin{document}\n")
+	}
+	box := layout.Box()
+	candidateSize := tikzCandidateSize / float64(box.Rows)
+	if box.Columns > box.Rows {
+		candidateSize = tikzCandidateSize / float64(box.Columns)
+	}
+	fmt.Fprintf(b, "\\begin{tikzpicture}[x=%scm, y=-%scm,\n", tikzNumber(cellSize), tikzNumber(cellSize))
+	for _, style := range []struct{ name, value string }{
+		{"cell line", "line width=" + p.length(cellLineWidth)},
+		{"box line", "line width=" + p.length(boxLineWidth)},
+		{"sub-sudoku line", "line width=" + p.length(gridLineWidth) + ", line join=miter"},
+		{"overlap", "fill=" + tikzColour(imageOverlapColour)},
+		{"given", "font=" + p.font(tikzDigitSize, true)},
+		{"solution", "font=" + p.font(tikzDigitSize, false) + ", text=" + tikzColour(imageSolutionColour)},
+		{"candidate", "font=" + p.font(candidateSize, false) + ", text=" + tikzColour(tikzCandidateColour) + ", inner sep=0pt"},
+	} {
+		fmt.Fprintf(b, "  %s/.style={%s},\n", style.name, style.value)
+	}
+	b.WriteString("]\n")
+
+	if overlaps := layout.Overlaps(); len(overlaps) > 0 {
+		b.WriteString("% cells shared by overlapping sub-sudokus\n")
+		for _, o := range overlaps {
+			fmt.Fprintf(b, "\\fill[overlap] (%d,%d) rectangle ++(%d,%d);\n", o.Column, o.Row, o.Columns, o.Rows)
+		}
+	}
+	drawVariantBelow(p, layout, samurai.Variant())
+
+	n := layout.size
+	for _, g := range layout.SubGrids() {
+		fmt.Fprintf(b, "%% %s sub-sudoku\n\\begin{scope}[shift={(%d,%d)}]\n", g.Position, g.Column, g.Row)
+		fmt.Fprintf(b, "\\draw[cell line] (0,0) grid (%d,%d);\n", n, n)
+		if regions := samurai.Variant().regions(g.Position); regions != nil {
+			writeTikZRegions(b, regions)
+		} else {
+			fmt.Fprintf(b, "\\draw[box line] (0,0) grid[xstep=%d, ystep=%d] (%d,%d);\n", box.Columns, box.Rows, n, n)
+		}
+		fmt.Fprintf(b, "\\draw[sub-sudoku line] (0,0) rectangle (%d,%d);\n", n, n)
+		b.WriteString("\\end{scope}\n")
+	}
+
+	drawVariantAbove(p, layout, samurai.Variant())
+
+	for _, g := range layout.SubGrids() {
+		fmt.Fprintf(b, "%% digits of the %s sub-sudoku\n\\begin{scope}[shift={(%d,%d)}]\n", g.Position, g.Column, g.Row)
+		// the digits of the cells shared with other sub-sudokus are written once, by the first of them
+		for i, row := range samurai.GetSubSudoku(g.Position) {
+			for j, num := range row {
+				y, x := g.Row+i, g.Column+j
+				if position, _, _, _ := layout.locate(y, x); position != g.Position {
+					continue
+				}
+				switch {
+				case num > 0:
+					fmt.Fprintf(b, "\\node[given] at (%d.5,%d.5) {%s};\n", j, i, tikzText(symbol(layout.symbols, num)))
+				case options.Solution != nil && options.Solution[y][x] > 0:
+					fmt.Fprintf(b, "\\node[solution] at (%d.5,%d.5) {%s};\n", j, i, tikzText(symbol(layout.symbols, options.Solution[y][x])))
+				case options.Candidates != nil:
+					for _, digit := range options.Candidates.Digits(Cell{y, x}) {
+						// candidates sit where they would in a box shrunk to the cell
+						at := point{
+							x: float64(j) + (float64((digit-1)%box.Columns)+0.5)/float64(box.Columns),
+							y: float64(i) + (float64((digit-1)/box.Columns)+0.5)/float64(box.Rows),
+						}
+						fmt.Fprintf(b, "\\node[candidate] at %s {%s};\n", p.point(at), tikzText(symbol(layout.symbols, digit)))
+					}
+				}
+			}
+		}
+		b.WriteString("\\end{scope}\n")
+	}
+	b.WriteString("\\end{tikzpicture}\n")
+	if options.Document {
+		b.WriteString("\\end{document}\n")
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+//writeTikZRegions writes the sides between the cells of different jigsaw regions of a sub-sudoku as a single path
+func writeTikZRegions(b *bytes.Buffer, regions Grid) {
+	var segments []string
+	for y, row := range regions {
+		for x, region := range row {
+			if x > 0 && row[x-1] != region {
+				segments = append(segments, fmt.Sprintf("(%d,%d) -- ++(0,1)", x, y))
+			}
+			if y > 0 && regions[y-1][x] != region {
+				segments = append(segments, fmt.Sprintf("(%d,%d) -- ++(1,0)", x, y))
+			}
+		}
+	}
+	if len(segments) > 0 {
+		fmt.Fprintf(b, "\\draw[box line, line cap=rect] %s;\n", strings.Join(segments, " "))
+	}
+}
+
+// tikzPen draws the elements of a puzzle's picture as TikZ paths, in the canvas coordinates the picture is set to,
+// cellSize centimetres to a cell
+type tikzPen struct {
+	b        bytes.Buffer
+	cellSize float64
+}
+
+func (p *tikzPen) polygon(points []point, c color.RGBA) {
+	fmt.Fprintf(&p.b, "\\fill[fill=%s] %s -- cycle;\n", tikzColour(c), p.path(points))
+}
+
+func (p *tikzPen) polyline(points []point, width float64, c color.RGBA, dashed bool) {
+	var dash string
+	if dashed {
+		dash = fmt.Sprintf(", dash pattern=on %s off %s", p.length(1.0/12), p.length(1.0/12))
+	}
+	fmt.Fprintf(&p.b, "\\draw[draw=%s, line width=%s, line cap=round, line join=round%s] %s;\n", tikzColour(c), p.length(width), dash, p.path(points))
+}
+
+func (p *tikzPen) disc(centre point, r float64, c color.RGBA) {
+	fmt.Fprintf(&p.b, "\\fill[fill=%s] %s circle[radius=%s];\n", tikzColour(c), p.point(centre), tikzNumber(r))
+}
+
+func (p *tikzPen) circle(centre point, r float64, width float64, c color.RGBA) {
+	fmt.Fprintf(&p.b, "\\draw[draw=%s, line width=%s] %s circle[radius=%s];\n", tikzColour(c), p.length(width), p.point(centre), tikzNumber(r))
+}
+
+func (p *tikzPen) text(centre point, text string, size float64, bold bool, c color.RGBA) {
+	fmt.Fprintf(&p.b, "\\node[font=%s, text=%s, inner sep=0pt] at %s {%s};\n", p.font(size, bold), tikzColour(c), p.point(centre), tikzText(text))
+}
+
+func (p *tikzPen) point(pt point) string {
+	return "(" + tikzNumber(pt.x) + "," + tikzNumber(pt.y) + ")"
+}
+
+func (p *tikzPen) path(points []point) string {
+	s := make([]string, len(points))
+	for i, pt := range points {
+		s[i] = p.point(pt)
+	}
+	return strings.Join(s, " -- ")
+}
+
+//length writes a length in cells in centimetres
+func (p *tikzPen) length(cells float64) string {
+	return tikzNumber(cells*p.cellSize) + "cm"
+}
+
+//font returns the font switch of text size cells high, in bold if asked for
+func (p *tikzPen) font(size float64, bold bool) string {
+	points := size * p.cellSize * pointsPerCentimetre
+	font := fmt.Sprintf("\\fontsize{%s}{%s}\\selectfont", tikzNumber(points), tikzNumber(points*1.2))
+	if bold {
+		font += "\\bfseries"
+	}
+	return font
+}
+
+//tikzNumber writes a number to a thousandth
+func tikzNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
+
+func tikzColour(c color.RGBA) string {
+	return fmt.Sprintf("{rgb,255:red,%d;green,%d;blue,%d}", c.R, c.G, c.B)
+}
+
+// tikzSpecials holds what LaTeX's special characters are written as in text
+var tikzSpecials = map[rune]string{
+	'\\': "\\textbackslash{}", '{': "\\{", '}': "\\}", '$': "\\$", '&': "\\&", '#': "\\#", '%': "\\%", '_': "\\_",
+	'^': "\\textasciicircum{}", '~': "\\textasciitilde{}",
+}
+
+//tikzText escapes the characters of text LaTeX would take for commands
+func tikzText(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if s, ok := tikzSpecials[r]; ok {
+			b.WriteString(s)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
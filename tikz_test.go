@@ -0,0 +1,120 @@
+package sudoku
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriteTikZ(t *testing.T) {
+	samurai := newTestSamurai()
+	var buf bytes.Buffer
+	if err := WriteTikZ(&buf, samurai, TikZOptions{Solution: testSolution(t)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tikz := buf.String()
+	if !strings.HasPrefix(tikz, "\\begin{tikzpicture}[x=0.6cm, y=-0.6cm,\n") || !strings.HasSuffix(tikz, "\\end{tikzpicture}\n") {
+		t.Fatalf("want a tikzpicture, got %s", tikz)
+	}
+	if begins, ends := strings.Count(tikz, "\\begin{scope}"), strings.Count(tikz, "\\end{scope}"); begins != 10 || ends != 10 {
+		t.Fatalf("want a scope for the lines and one for the digits of each sub-sudoku, got %d and %d", begins, ends)
+	}
+	for _, want := range []string{
+		"given/.style={font=\\fontsize{10.243}{12.292}\\selectfont\\bfseries},",
+		// the top left overlap, and the centre sub-sudoku's lines
+		"\\fill[overlap] (6,6) rectangle ++(3,3);\n",
+		"% centre sub-sudoku\n\\begin{scope}[shift={(6,6)}]\n\\draw[cell line] (0,0) grid (9,9);\n" +
+			"\\draw[box line] (0,0) grid[xstep=3, ystep=3] (9,9);\n\\draw[sub-sudoku line] (0,0) rectangle (9,9);\n\\end{scope}\n",
+		// the solution of the top left cell, and the given of cell (1, 3)
+		"% digits of the top left sub-sudoku\n\\begin{scope}[shift={(0,0)}]\n\\node[solution] at (0.5,0.5) {1};\n\\node[solution] at (1.5,0.5) {",
+		"\\node[given] at (2.5,0.5) {5};\n",
+	} {
+		if !strings.Contains(tikz, want) {
+			t.Errorf("want the picture to contain %q", want)
+		}
+	}
+	// every cell of the canvas but the gaps is written once, those shared by sub-sudokus too
+	if got := strings.Count(tikz, "\\node["); got != 369 {
+		t.Fatalf("want 369 digits, got %d", got)
+	}
+	if document := "\\documentclass"; strings.Contains(tikz, document) {
+		t.Fatalf("want no document around the picture")
+	}
+}
+
+func TestWriteTikZVariant(t *testing.T) {
+	samurai := newTestSamurai()
+	candidates, err := NewCandidates(samurai)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := samurai.SetVariant(&Variant{Jigsaws: []Jigsaw{testJigsaw}, Cages: []Cage{{Sum: 13, Cells: []Cell{{0, 0}, {0, 1}}}}}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteTikZ(&buf, samurai, TikZOptions{CellSize: 1, Candidates: candidates, Document: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tikz := buf.String()
+	if !strings.HasPrefix(tikz, "\\documentclass[tikz, border=2mm]{standalone}\n") || !strings.HasSuffix(tikz, "\\end{tikzpicture}\n\\end{document}\n") {
+		t.Fatalf("want a standalone document, got %s", tikz)
+	}
+	for _, want := range []string{
+		"\\begin{tikzpicture}[x=1cm, y=-1cm,\n",
+		// the centre's regions rather than its boxes, 111123333 being its first row
+		"% centre sub-sudoku\n\\begin{scope}[shift={(6,6)}]\n\\draw[cell line] (0,0) grid (9,9);\n\\draw[box line, line cap=rect] (4,0) -- ++(0,1) (5,0) -- ++(0,1) ",
+		// the cage's dashed outline and sum
+		"dash pattern=on 0.083cm off 0.083cm] (0.125,0.125) -- (1,0.125);\n",
+		"inner sep=0pt] at (0.21,0.165) {13};\n",
+		// the candidates 1, 3, 6 and 8 of the top left cell
+		"\\begin{scope}[shift={(0,0)}]\n\\node[candidate] at (0.167,0.167) {1};\n\\node[candidate] at (0.833,0.167) {3};\n" +
+			"\\node[candidate] at (0.833,0.5) {6};\n\\node[candidate] at (0.5,0.833) {8};\n",
+	} {
+		if !strings.Contains(tikz, want) {
+			t.Errorf("want the picture to contain %q", want)
+		}
+	}
+	if strings.Contains(tikz, "(6,6)}]\n\\draw[cell line] (0,0) grid (9,9);\n\\draw[box line] (0,0) grid") {
+		t.Fatalf("want no boxes drawn in the centre")
+	}
+}
+
+func TestWriteTikZErrors(t *testing.T) {
+	malformed := newTestSamurai()
+	malformed.SetGrid(Grid{{1, 2, 3}})
+
+	testCases := []struct {
+		name    string
+		samurai *SamuraiSudoku
+		options TikZOptions
+	}{
+		{"malformed grid", malformed, TikZOptions{}},
+		{"malformed solution", newTestSamurai(), TikZOptions{Solution: Grid{{1}}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := WriteTikZ(io.Discard, tc.samurai, tc.options); !errors.Is(err, ErrInvalidGrid) {
+				t.Fatalf("want %v, got %v", ErrInvalidGrid, err)
+			}
+		})
+	}
+}
+
+func TestTikZText(t *testing.T) {
+	testCases := []struct {
+		text string
+		want string
+	}{
+		{"12", "12"},
+		{"A", "A"},
+		{"50% & #1_{x}", "50\\% \\& \\#1\\_\\{x\\}"},
+		{"~^\\", "\\textasciitilde{}\\textasciicircum{}\\textbackslash{}"},
+	}
+	for _, tc := range testCases {
+		if got := tikzText(tc.text); got != tc.want {
+			t.Errorf("want %q written as %q, got %q", tc.text, tc.want, got)
+		}
+	}
+}
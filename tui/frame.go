@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	sudoku "github.com/alielbashir/samurai-sudoku-go"
+)
+
+// style The SGR parameters a cell is written with, e.g. "1;31" for bold red, none for plain text
+type style []string
+
+func (s style) String() string {
+	if len(s) == 0 {
+		return ""
+	}
+	return "\x1b[" + strings.Join(s, ";") + "m"
+}
+
+// frame lays a puzzle's canvas out on the screen: every cell is three characters wide and a line high, and the lines between
+// boxes and sub-sudokus are drawn with box-drawing characters, light ones between boxes and heavy ones around sub-sudokus.
+// Lines are only given room on the screen where the canvas has some
+type frame struct {
+	rows, columns int
+	borders       sudoku.Borders
+	hasLine       []bool // Rows of the canvas with a line above them, the last one for the line below the canvas
+	hasColumn     []bool // Columns of the canvas with a line left of them, the last one for the line right of the canvas
+	line          []int  // Screen line of every row of the canvas, from 1
+	column        []int  // Screen column of the first character of every column of the canvas, from 1
+	height        int    // Screen lines the frame takes
+}
+
+func newFrame(samurai *sudoku.SamuraiSudoku) *frame {
+	f := &frame{borders: samurai.Borders()}
+	f.rows, f.columns = samurai.Layout().Size()
+	f.hasLine, f.hasColumn = f.borders.Lines()
+
+	f.line = make([]int, f.rows)
+	line := 1
+	for y := range f.line {
+		if f.hasLine[y] {
+			line++
+		}
+		f.line[y] = line
+		line++
+	}
+	if f.hasLine[f.rows] {
+		line++
+	}
+	f.height = line - 1
+	f.column = make([]int, f.columns)
+	column := 1
+	for x := range f.column {
+		if f.hasColumn[x] {
+			column++
+		}
+		f.column[x] = column
+		column += 3
+	}
+	return f
+}
+
+//draw writes the frame to w at the top left of the screen, each cell written as cell returns it: three characters and their style.
+//Lines end with \r\n, for terminals in raw mode, and clear what was left of the screen line
+func (f *frame) draw(w io.Writer, cell func(y int, x int) (string, style)) error {
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	rule := func(y int) {
+		for x := 0; x <= f.columns; x++ {
+			if f.hasColumn[x] {
+				b.WriteString(f.borders.CornerChar(y, x))
+			}
+			if x < f.columns {
+				b.WriteString(strings.Repeat(f.borders.Top[y][x].Char(false), 3))
+			}
+		}
+		b.WriteString("\x1b[K\r\n")
+	}
+	for y := 0; y < f.rows; y++ {
+		if f.hasLine[y] {
+			rule(y)
+		}
+		for x := 0; x <= f.columns; x++ {
+			if f.hasColumn[x] {
+				b.WriteString(f.borders.Left[y][x].Char(true))
+			}
+			if x < f.columns {
+				text, s := cell(y, x)
+				writeStyled(&b, text, s)
+			}
+		}
+		b.WriteString("\x1b[K\r\n")
+	}
+	if f.hasLine[f.rows] {
+		rule(f.rows)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+//update writes cell y,x over what was drawn there, leaving the terminal's cursor after it
+func (f *frame) update(w io.Writer, y int, x int, text string, s style) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\x1b[%d;%dH", f.line[y], f.column[x])
+	writeStyled(&b, text, s)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeStyled(b *strings.Builder, text string, s style) {
+	if len(s) == 0 {
+		b.WriteString(text)
+		return
+	}
+	b.WriteString(s.String())
+	b.WriteString(text)
+	b.WriteString("\x1b[0m")
+}
@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	sudoku "github.com/alielbashir/samurai-sudoku-go"
+)
+
+// escapes matches the ANSI escape codes written to the terminal
+var escapes = regexp.MustCompile("\x1b\\[[0-9;?]*[A-Za-z]")
+
+//screen returns what out shows once the last drawing starting at the top of the screen is done, without escape codes
+//nor trailing spaces
+func screen(out string) string {
+	if i := strings.LastIndex(out, "\x1b[H"); i >= 0 {
+		out = out[i:]
+	}
+	lines := strings.Split(escapes.ReplaceAllString(out, ""), "\r\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestFrame(t *testing.T) {
+	layout, err := sudoku.ParseLayout("twin-4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var samurai sudoku.SamuraiSudoku
+	samurai.SetLayout(layout)
+	samurai.SetGrid(layout.NewGrid())
+	f := newFrame(&samurai)
+
+	var b strings.Builder
+	err = f.draw(&b, func(y int, x int) (string, style) {
+		if !layout.Contains(y, x) {
+			return "   ", nil
+		}
+		return " " + string(rune('a'+y)) + " ", style{"1"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"┏━━━━━━┳━━━━━━┓",
+		"┃ a  a │ a  a ┃",
+		"┃ b  b │ b  b ┃",
+		"┣──────╋━━━━━━╋━━━━━━┓",
+		"┃ c  c ┃ c  c ┃ c  c ┃",
+		"┃ d  d ┃ d  d ┃ d  d ┃",
+		"┗━━━━━━╋━━━━━━╋──────┫",
+		"       ┃ e  e │ e  e ┃",
+		"       ┃ f  f │ f  f ┃",
+		"       ┗━━━━━━┻━━━━━━┛",
+		"",
+	}, "\n")
+	if got := screen(b.String()); got != want {
+		t.Fatalf("want:\n%s\ngot:\n%s", want, got)
+	}
+	if !strings.Contains(b.String(), "\x1b[1m a \x1b[0m") {
+		t.Fatalf("want the cells styled")
+	}
+	if f.height != 10 {
+		t.Fatalf("want the frame 10 lines high, got %d", f.height)
+	}
+
+	b.Reset()
+	if err := f.update(&b, 4, 5, " x ", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b.String(), "\x1b[8;19H x "; got != want {
+		t.Fatalf("want cell (4, 5) written at %q, got %q", want, got)
+	}
+}
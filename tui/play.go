@@ -0,0 +1,287 @@
+package tui
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	sudoku "github.com/alielbashir/samurai-sudoku-go"
+)
+
+// tick is how often Play redraws the clock when no key is pressed
+var tick = time.Second
+
+// Styles of the cells drawn by Play
+var (
+	givenStyle    = style{"1"}       // Bold
+	entryStyle    = style{"34"}      // Blue
+	conflictStyle = style{"1", "31"} // Bold red
+	mistakeStyle  = style{"4", "31"} // Underlined red
+	markedStyle   = style{"2"}       // Faint, for the empty cells having pencil marks
+	peerStyle     = style{"100"}     // Grey background
+	cursorStyle   = style{"7"}       // Reversed
+)
+
+const (
+	resetScreen   = "\x1b[?1049h\x1b[?25l" // Switches to the alternate screen and hides the cursor
+	restoreScreen = "\x1b[?25h\x1b[?1049l" // Shows the cursor and switches back to the main screen
+)
+
+// player The state of the screen Play draws
+type player struct {
+	game     *sudoku.Game
+	frame    *frame
+	symbols  string
+	cursor   sudoku.Cell
+	marking  bool   // Digits typed toggle pencil marks rather than fill the cell
+	checking bool   // Digits not matching the solution are shown
+	paused   bool   // The clock is stopped and the grid hidden
+	message  string // Shown below the grid until the next key is pressed
+
+	// Worked out for every cell as the grid is drawn
+	grid      sudoku.Grid
+	conflicts map[sudoku.Cell]bool
+	peers     map[sudoku.Cell]bool
+	mistakes  map[sudoku.Cell]bool
+}
+
+//Play plays game in a terminal until q or ctrl-c is pressed, in runs out or ctx is done: it draws the game to out and makes
+//the moves of the keys read from in, as listed in the package documentation. It redraws the clock every second.
+//Play stops the game's clock when it returns, it returns nil once the player quits and the errors reading in and writing out.
+//Keys still being read from in when ctx is done are left unread
+func Play(ctx context.Context, game *sudoku.Game, in io.Reader, out io.Writer) error {
+	defer game.Pause()
+	p := &player{
+		game:    game,
+		frame:   newFrame(game.Puzzle()),
+		symbols: game.Puzzle().Layout().Symbols(),
+	}
+	p.cursor = p.first()
+
+	keys := make(chan string)
+	errs := make(chan error, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		r := bufio.NewReader(in)
+		for {
+			k, err := readKey(r)
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case keys <- k:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	if _, err := io.WriteString(out, resetScreen); err != nil {
+		return err
+	}
+	defer io.WriteString(out, restoreScreen)
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		if err := p.draw(out); err != nil {
+			return err
+		}
+		select {
+		case k := <-keys:
+			if p.handle(k) {
+				return nil
+			}
+		case err := <-errs:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+//first returns the first empty cell of the game's grid in reading order, the first cell if there is none
+func (p *player) first() sudoku.Cell {
+	var first *sudoku.Cell
+	for y, row := range p.game.Grid() {
+		for x, num := range row {
+			if num == 0 {
+				return sudoku.Cell{Row: y, Column: x}
+			}
+			if num > 0 && first == nil {
+				first = &sudoku.Cell{Row: y, Column: x}
+			}
+		}
+	}
+	return *first
+}
+
+//handle makes the move of key k, and tells if the player quits
+func (p *player) handle(k string) bool {
+	p.message = ""
+	if k == "q" || k == keyCtrlC {
+		return true
+	}
+	if p.paused {
+		if k == "p" {
+			p.paused = false
+			p.game.Resume()
+		}
+		return false
+	}
+	switch k {
+	case keyUp, "k":
+		p.move(-1, 0)
+	case keyDown, "j":
+		p.move(1, 0)
+	case keyLeft, "h":
+		p.move(0, -1)
+	case keyRight, "l":
+		p.move(0, 1)
+	case "m":
+		p.marking = !p.marking
+	case "c":
+		p.checking = !p.checking
+	case "p":
+		p.paused = true
+		p.game.Pause()
+	case "u", keyCtrlZ:
+		if !p.game.Undo() {
+			p.message = "nothing to undo"
+		}
+	case "r", keyCtrlR:
+		if !p.game.Redo() {
+			p.message = "nothing to redo"
+		}
+	case "0", " ", "x", keyBackspace, keyDelete:
+		p.report(p.game.Erase(p.cursor))
+	default:
+		n := strings.Index(p.symbols, k) + 1
+		if len(k) != 1 || n == 0 {
+			break
+		}
+		if p.marking {
+			p.report(p.game.Mark(p.cursor, n))
+			break
+		}
+		p.report(p.game.Place(p.cursor, n))
+		if p.game.Solved() {
+			p.message = fmt.Sprintf("solved in %s!", clock(p.game.Elapsed()))
+		}
+	}
+	return false
+}
+
+//report shows err below the grid, if there is one
+func (p *player) report(err error) {
+	if err != nil {
+		p.message = strings.TrimPrefix(err.Error(), "sudoku: ")
+	}
+}
+
+//move moves the cursor dy rows down and dx columns right, on to the next cell that way past the gaps, if there is one
+func (p *player) move(dy int, dx int) {
+	layout := p.game.Puzzle().Layout()
+	rows, columns := layout.Size()
+	for y, x := p.cursor.Row+dy, p.cursor.Column+dx; 0 <= y && y < rows && 0 <= x && x < columns; y, x = y+dy, x+dx {
+		if layout.Contains(y, x) {
+			p.cursor = sudoku.Cell{Row: y, Column: x}
+			return
+		}
+	}
+}
+
+//draw draws the grid to out, followed by the status of the game and a reminder of the keys
+func (p *player) draw(out io.Writer) error {
+	samurai := p.game.Puzzle()
+	p.grid = p.game.Grid()
+	p.conflicts = cellSet(samurai.Conflicts())
+	p.peers = cellSet(samurai.Peers(p.cursor))
+	p.mistakes = nil
+	if p.checking {
+		p.mistakes = cellSet(p.game.Mistakes())
+	}
+
+	w := bufio.NewWriter(out)
+	p.frame.draw(w, p.cell)
+
+	var status []string
+	status = append(status, fmt.Sprintf("r%dc%d", p.cursor.Row+1, p.cursor.Column+1))
+	if p.marking {
+		status = append(status, "pencil marks")
+	} else {
+		status = append(status, "digits")
+	}
+	status = append(status, clock(p.game.Elapsed()))
+	switch {
+	case p.paused:
+		status = append(status, "paused, press p to resume")
+	case p.game.Solved():
+		status = append(status, "solved")
+	case p.checking:
+		status = append(status, fmt.Sprintf("%d mistakes", len(p.mistakes)))
+	}
+	var marks string
+	if digits := p.game.Marks(p.cursor); len(digits) > 0 && !p.paused {
+		symbols := make([]string, len(digits))
+		for i, n := range digits {
+			symbols[i] = p.symbols[n-1 : n]
+		}
+		marks = "marks: " + strings.Join(symbols, " ")
+	}
+	help := fmt.Sprintf("arrows move  %s-%s place  0 erase  m marks  u undo  r redo  c check  p pause  q quit",
+		p.symbols[:1], p.symbols[len(p.symbols)-1:])
+	for _, line := range []string{strings.Join(status, "  "), marks, p.message, help} {
+		fmt.Fprintf(w, "%s\x1b[K\r\n", line)
+	}
+	w.WriteString("\x1b[J")
+	return w.Flush()
+}
+
+//cell returns the text and style of cell y,x
+func (p *player) cell(y int, x int) (string, style) {
+	num := p.grid[y][x]
+	if num == -1 {
+		return "   ", nil
+	}
+	c := sudoku.Cell{Row: y, Column: x}
+	text, s := " . ", style(nil)
+	switch {
+	case p.paused:
+		text = "   "
+	case p.mistakes[c]:
+		text, s = " "+p.symbols[num-1:num]+" ", mistakeStyle
+	case num > 0 && p.conflicts[c]:
+		text, s = " "+p.symbols[num-1:num]+" ", conflictStyle
+	case num > 0 && p.game.Given(c):
+		text, s = " "+p.symbols[num-1:num]+" ", givenStyle
+	case num > 0:
+		text, s = " "+p.symbols[num-1:num]+" ", entryStyle
+	case len(p.game.Marks(c)) > 0:
+		text, s = " + ", markedStyle
+	}
+	switch {
+	case c == p.cursor:
+		s = append(append(style(nil), s...), cursorStyle...)
+	case p.peers[c] && !p.paused:
+		s = append(append(style(nil), s...), peerStyle...)
+	}
+	return text, s
+}
+
+func cellSet(cells []sudoku.Cell) map[sudoku.Cell]bool {
+	set := make(map[sudoku.Cell]bool, len(cells))
+	for _, c := range cells {
+		set[c] = true
+	}
+	return set
+}
@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	sudoku "github.com/alielbashir/samurai-sudoku-go"
+)
+
+func testPuzzle(t *testing.T) *sudoku.SamuraiSudoku {
+	f, err := os.Open("../sudoku.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	grid, err := sudoku.ReadSamuraiGrid(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var samurai sudoku.SamuraiSudoku
+	samurai.SetGrid(grid)
+	return &samurai
+}
+
+//play plays a game of sudoku.txt with the keys given, and returns the game and what was written to the terminal
+func play(t *testing.T, keys string) (*sudoku.Game, string) {
+	game, err := sudoku.NewGame(testPuzzle(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := Play(context.Background(), game, strings.NewReader(keys), &out); err != nil {
+		t.Fatal(err)
+	}
+	return game, out.String()
+}
+
+//lines returns the lines of the last screen drawn holding any of the texts
+func lines(out string, texts ...string) []string {
+	var found []string
+	for _, line := range strings.Split(screen(out), "\n") {
+		for _, text := range texts {
+			if strings.Contains(line, text) {
+				found = append(found, line)
+				break
+			}
+		}
+	}
+	return found
+}
+
+func TestPlay(t *testing.T) {
+	// the first row of the solution starts with 1 6, and the puzzle gives 5 7 after them
+	game, out := play(t, "1l7cu"+"m38q"+"this key is never read")
+
+	if got := game.Grid()[0][:4]; !reflect.DeepEqual(got, []int{1, 0, 5, 7}) {
+		t.Fatalf("want 1 placed and 7 undone, got %v", got)
+	}
+	if got := game.Marks(sudoku.Cell{Row: 0, Column: 1}); !reflect.DeepEqual(got, []int{3, 8}) {
+		t.Fatalf("want pencil marks 3 and 8, got %v", got)
+	}
+	if !strings.HasPrefix(out, resetScreen) || !strings.HasSuffix(out, restoreScreen) {
+		t.Fatalf("want the screen set up and restored")
+	}
+	s := screen(out)
+	for _, want := range []string{"┃ 1  +  5 │ 7 ", "r1c2  pencil marks  00:00  0 mistakes\nmarks: 3 8\n"} {
+		if !strings.Contains(s, want) {
+			t.Fatalf("want %q on the screen, got:\n%s", want, s)
+		}
+	}
+	if !strings.Contains(out, "1 mistakes") {
+		t.Fatalf("want the wrong 7 counted")
+	}
+}
+
+func TestPlayMoves(t *testing.T) {
+	testCases := []struct {
+		name   string
+		keys   string
+		status string // Expected start of the status line
+	}{
+		{"start", "", "r1c1  digits"},
+		{"right", "\x1b[C", "r1c2"},
+		{"vim keys", "jjl", "r3c2"},
+		{"wall", "hkh", "r1c1"},
+		{"over the gap", "llllllll\x1b[C", "r1c13"},
+		{"into the centre", "llllllljjjjjjjjj", "r10c8"},
+		{"out of the centre", "lllllljjjjjjjjjjjjjjjjjjjjjjjj", "r21c7"},
+		{"given", "ll1", "r1c3"},
+		{"pause", "p", "r1c1  digits  00:00  paused"},
+		{"resume", "pp", "r1c1  digits  00:00\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, out := play(t, tc.keys)
+			if got := lines(out, "  digits", "  pencil marks"); len(got) != 1 || !strings.HasPrefix(got[0]+"\n", tc.status) {
+				t.Fatalf("want status %q, got %q", tc.status, got)
+			}
+		})
+	}
+
+	_, out := play(t, "ll1")
+	if got := lines(out, "given"); len(got) != 1 || got[0] != "the cell is given by the puzzle: r1c3" {
+		t.Fatalf("want a given reported, got %q", got)
+	}
+	_, out = play(t, "p")
+	if got := lines(out, "┃"); strings.ContainsAny(got[0], "123456789") {
+		t.Fatalf("want the grid hidden while paused, got %q", got[0])
+	}
+}
+
+func TestPlayStyles(t *testing.T) {
+	// 5 is given in the same row, the one placed is under the cursor
+	_, out := play(t, "5")
+	last := out[strings.LastIndex(out, "\x1b[H"):]
+	if !strings.Contains(last, style{"1", "31", "100"}.String()+" 5 ") || !strings.Contains(last, style{"1", "31", "7"}.String()+" 5 ") {
+		t.Fatalf("want the repeated 5s shown in red")
+	}
+	_, out = play(t, "")
+	last = out[strings.LastIndex(out, "\x1b[H"):]
+	if !strings.Contains(last, style{"1", "100"}.String()+" 5 ") || !strings.Contains(last, cursorStyle.String()+" . ") {
+		t.Fatalf("want the cursor and its peers highlighted")
+	}
+}
+
+func TestReadKey(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("\x1b[A\x1b[B\x1bOC\x1b[D\x1b[3~\x7f\r\x03\x12\x1a\x1bq9A\x1b[15~\x01"))
+	want := []string{keyUp, keyDown, keyRight, keyLeft, keyDelete, keyBackspace, keyEnter, keyCtrlC, keyCtrlR, keyCtrlZ, "", "q", "9", "A", "", ""}
+	for i, w := range want {
+		got, err := readKey(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != w {
+			t.Fatalf("want key %d read as %q, got %q", i, w, got)
+		}
+	}
+	if _, err := readKey(r); err == nil {
+		t.Fatalf("want an error once the keys run out")
+	}
+}
@@ -0,0 +1,116 @@
+// Package tui plays samurai sudoku puzzles in a terminal, and shows them being solved.
+//
+// Play draws a Game with ANSI escape codes, the lines between boxes and around sub-sudokus drawn with box-drawing
+// characters, and makes the moves of the keys pressed:
+//
+//	arrows, h j k l          move the cursor, jumping over the gaps between sub-sudokus
+//	1-9, A-Z                 place the digit written with that symbol, or toggle its pencil mark
+//	0, space, backspace, x   erase the cell
+//	m                        switch between placing digits and pencil marks
+//	u, ctrl-z                undo
+//	r, ctrl-r                redo
+//	c                        show the digits that don't match the solution, or stop showing them
+//	p                        pause the clock and hide the grid, or resume
+//	q, ctrl-c                quit
+//
+// The cells sharing a row, column, box or sub-sudoku unit with the cursor are highlighted, digits found twice in a unit are red,
+// and the pencil marks of the cell under the cursor are listed below the grid.
+//
+// Watch draws a puzzle being solved, writing every digit the solver places or takes back as it happens.
+//
+// Both expect a terminal understanding ANSI escape codes, Play one in raw mode so that keys are read as they are pressed.
+// Lines end with \r\n, as raw mode leaves the cursor where it is on \n
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Keys read by readKey other than the characters typed
+const (
+	keyUp        = "up"
+	keyDown      = "down"
+	keyLeft      = "left"
+	keyRight     = "right"
+	keyBackspace = "backspace"
+	keyDelete    = "delete"
+	keyEnter     = "enter"
+	keyCtrlC     = "ctrl-c"
+	keyCtrlR     = "ctrl-r"
+	keyCtrlZ     = "ctrl-z"
+)
+
+//readKey reads a key pressed from r: the name of a special key, the character typed, or "" for a key it doesn't know
+func readKey(r *bufio.Reader) (string, error) {
+	c, _, err := r.ReadRune()
+	if err != nil {
+		return "", err
+	}
+	switch c {
+	case 0x1b:
+		return readEscape(r)
+	case 0x7f, 0x08:
+		return keyBackspace, nil
+	case '\r', '\n':
+		return keyEnter, nil
+	case 0x03:
+		return keyCtrlC, nil
+	case 0x12:
+		return keyCtrlR, nil
+	case 0x1a:
+		return keyCtrlZ, nil
+	}
+	if c < ' ' {
+		return "", nil
+	}
+	return string(c), nil
+}
+
+//readEscape reads the rest of an escape sequence, after ESC, and returns the key it stands for
+func readEscape(r *bufio.Reader) (string, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	if c != '[' && c != 'O' {
+		// a lone ESC, the byte after it is a key of its own
+		return "", r.UnreadByte()
+	}
+	// parameters, then a final byte from @ to ~
+	var params strings.Builder
+	for {
+		c, err = r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		if '@' <= c && c <= '~' {
+			break
+		}
+		params.WriteByte(c)
+	}
+	switch {
+	case c == 'A':
+		return keyUp, nil
+	case c == 'B':
+		return keyDown, nil
+	case c == 'C':
+		return keyRight, nil
+	case c == 'D':
+		return keyLeft, nil
+	case c == '~' && params.String() == "3":
+		return keyDelete, nil
+	}
+	return "", nil
+}
+
+//clock writes d as minutes and seconds, with hours in front once there are some
+func clock(d time.Duration) string {
+	seconds := int(d / time.Second)
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
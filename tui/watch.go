@@ -0,0 +1,134 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	sudoku "github.com/alielbashir/samurai-sudoku-go"
+)
+
+//Watch solves the puzzle with solve, drawing its grid to out and then every digit the solver places or takes back as it does,
+//pausing delay after each so that the solve can be followed. Digits are coloured by the solver thread placing them.
+//Once the solver is done Watch draws the solution, or the solver's error, under which it leaves the cursor.
+//It returns the solver's error, or the first error writing out, which stops the drawing but not the solve
+func Watch(ctx context.Context, samurai *sudoku.SamuraiSudoku, solve sudoku.SolverFunc, out io.Writer, delay time.Duration) error {
+	w := &watcher{
+		ctx:     ctx,
+		out:     out,
+		frame:   newFrame(samurai),
+		symbols: samurai.Layout().Symbols(),
+		givens:  copyGrid(samurai.Grid()),
+		delay:   delay,
+	}
+	w.write("\x1b[?25l\x1b[2J")
+	w.redraw(w.givens)
+
+	stop := samurai.Observe(w)
+	grid, err := solve(ctx, samurai)
+	stop()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err == nil {
+		w.redraw(grid)
+		w.status(fmt.Sprintf("solved in %d moves", w.moves))
+	} else {
+		w.status(fmt.Sprintf("stopped after %d moves: %v", w.moves, err))
+	}
+	w.write("\r\n\x1b[?25h")
+	if err != nil {
+		return err
+	}
+	return w.err
+}
+
+// watcher draws the moves of a solve as an Observer of the puzzle
+type watcher struct {
+	ctx     context.Context
+	out     io.Writer
+	frame   *frame
+	symbols string
+	givens  sudoku.Grid
+	delay   time.Duration
+
+	mu    sync.Mutex // Held while drawing, as the solve finishing can race with the last move drawn
+	moves int
+	err   error // First error writing out, after which nothing more is written
+}
+
+func (w *watcher) Moved(move sudoku.Move) {
+	w.mu.Lock()
+	y, x := move.GlobalCell()
+	w.moves++
+	if n := move.Num(); n > 0 {
+		w.update(y, x, " "+w.symbols[n-1:n]+" ", threadStyle(move.Thread()))
+	} else {
+		w.update(y, x, " . ", nil)
+	}
+	w.status(fmt.Sprintf("%d moves", w.moves))
+	w.mu.Unlock()
+	if w.delay > 0 {
+		select {
+		case <-time.After(w.delay):
+		case <-w.ctx.Done():
+		}
+	}
+}
+
+func (w *watcher) Reset(grid sudoku.Grid) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.redraw(grid)
+}
+
+//threadStyle returns the colour the digits placed by a solver thread are written in, one of six
+func threadStyle(thread int) style {
+	return style{fmt.Sprint(31 + thread%6)}
+}
+
+//redraw draws grid whole, the givens in bold
+func (w *watcher) redraw(grid sudoku.Grid) {
+	if w.err != nil {
+		return
+	}
+	w.err = w.frame.draw(w.out, func(y int, x int) (string, style) {
+		switch num := grid[y][x]; {
+		case num == -1:
+			return "   ", nil
+		case num == 0:
+			return " . ", nil
+		case w.givens[y][x] > 0:
+			return " " + w.symbols[num-1:num] + " ", givenStyle
+		default:
+			return " " + w.symbols[num-1:num] + " ", nil
+		}
+	})
+}
+
+func (w *watcher) update(y int, x int, text string, s style) {
+	if w.err == nil {
+		w.err = w.frame.update(w.out, y, x, text, s)
+	}
+}
+
+//status writes text on the line below the grid
+func (w *watcher) status(text string) {
+	w.write(fmt.Sprintf("\x1b[%d;1H%s\x1b[K", w.frame.height+1, text))
+}
+
+func (w *watcher) write(s string) {
+	if w.err == nil {
+		_, w.err = io.WriteString(w.out, s)
+	}
+}
+
+func copyGrid(grid sudoku.Grid) sudoku.Grid {
+	c := make(sudoku.Grid, len(grid))
+	for i := range grid {
+		c[i] = append([]int(nil), grid[i]...)
+	}
+	return c
+}
@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	sudoku "github.com/alielbashir/samurai-sudoku-go"
+)
+
+func TestWatch(t *testing.T) {
+	samurai := testPuzzle(t)
+	var out strings.Builder
+	if err := Watch(context.Background(), samurai, sudoku.Solvers["global"], &out, 0); err != nil {
+		t.Fatal(err)
+	}
+	s := screen(out.String())
+	for _, want := range []string{"┃ 1  6  5 │ 7  9  8 │ 4  2  3 ┃", "\nsolved in "} {
+		if !strings.Contains(s, want) {
+			t.Fatalf("want %q on the screen, got:\n%s", want, s)
+		}
+	}
+	// every move writes a cell then the status line, the line under the 29 of the grid
+	if moves := regexp.MustCompile("\x1b\\[[0-9]+;[0-9]+H").FindAllString(out.String(), -1); len(moves) < 2 || moves[1] != "\x1b[30;1H" {
+		t.Fatalf("want the moves drawn as they are made")
+	}
+	if !strings.HasSuffix(out.String(), "\r\n\x1b[?25h") {
+		t.Fatalf("want the cursor shown under the grid once done")
+	}
+}
+
+func TestWatchCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var out strings.Builder
+	err := Watch(ctx, testPuzzle(t), sudoku.Solvers["global"], &out, 0)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want the solve cancelled, got %v", err)
+	}
+	if s := screen(out.String()); !strings.Contains(s, "stopped after") {
+		t.Fatalf("want the error drawn, got:\n%s", s)
+	}
+}
@@ -0,0 +1,158 @@
+package sudoku
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrInvalidGrid is returned for grids that aren't shaped like their layout, or hold values other than digits
+var ErrInvalidGrid = errors.New("sudoku: invalid samurai grid")
+
+// ConflictError is returned by Validate for a digit found more than once in a row, column, box, jigsaw region, diagonal or window
+// of a sub-sudoku, or in a killer cage
+type ConflictError struct {
+	Position Position // Sub-sudoku of the row, column, box, region, diagonal or window, 0 for cages
+	Unit     string   // "row", "column", "box", "region", "diagonal", "window" or "cage"
+	Index    int      // Index of the row, column, box, region or window within the sub-sudoku, 0 for the diagonal going down to the right and 1 for the other, index of the cage in the Variant
+	Num      int
+}
+
+func (e *ConflictError) Error() string {
+	if e.Unit == "cage" {
+		return fmt.Sprintf("sudoku: %d appears more than once in cage %d", e.Num, e.Index+1)
+	}
+	return fmt.Sprintf("sudoku: %d appears more than once in %s %d of the %s sub-sudoku", e.Num, e.Unit, e.Index+1, e.Position)
+}
+
+//Validate checks that grid is shaped like a 21*21 samurai sudoku and that its filled cells don't break any rule.
+//It returns an error wrapping ErrInvalidGrid for malformed grids, and a *ConflictError for repeated digits
+func Validate(grid Grid) error {
+	return ValidateLayout(grid, Samurai)
+}
+
+//Validate checks that the puzzle's grid is shaped like its layout and that its filled cells don't break any rule, its variant's included.
+//It also returns a *RestrictionError for cells holding a digit they may not, a *CageError for killer cages adding up to the wrong sum,
+//a *MarkerError for adjacent cells breaking a marker, and a *ConstraintError for cells breaking a constraint such as a thermometer
+func (s *SamuraiSudoku) Validate() error {
+	return validate(s.Grid(), s.Layout(), s.variant)
+}
+
+//ValidateLayout checks that grid is shaped like layout and that its filled cells don't break any rule.
+//It returns an error wrapping ErrInvalidGrid for malformed grids, and a *ConflictError for repeated digits
+func ValidateLayout(grid Grid, layout *Layout) error {
+	return validate(grid, layout, nil)
+}
+
+func validate(grid Grid, layout *Layout, variant *Variant) error {
+	if err := checkShape(grid, layout); err != nil {
+		return err
+	}
+	for _, u := range allUnits(layout, variant) {
+		seen := 0
+		for _, c := range u.cells {
+			num := grid[c.Row][c.Column]
+			if num == 0 {
+				continue
+			}
+			if seen&(1<<num) != 0 {
+				return &ConflictError{Position: u.position, Unit: u.kind, Index: u.index, Num: num}
+			}
+			seen |= 1 << num
+		}
+	}
+	if variant == nil {
+		return nil
+	}
+	if err := validateRestrictions(grid, variant.Restrictions); err != nil {
+		return err
+	}
+	if err := validateCages(grid, variant.Cages); err != nil {
+		return err
+	}
+	if err := validateMarkers(grid, variant); err != nil {
+		return err
+	}
+	return validateConstraints(grid, variant.Constraints, layout.size)
+}
+
+//checkShape checks that grid has as many rows and columns as layout's canvas, with -1 exactly in the gaps and digits or 0 elsewhere,
+//digits going up to the number of rows of the layout's sub-sudokus
+func checkShape(grid Grid, layout *Layout) error {
+	rows, columns := layout.Size()
+	if len(grid) != rows {
+		return fmt.Errorf("%w: want %d rows, got %d", ErrInvalidGrid, rows, len(grid))
+	}
+	for y, row := range grid {
+		if len(row) != columns {
+			return fmt.Errorf("%w: want %d cells in row %d, got %d", ErrInvalidGrid, columns, y+1, len(row))
+		}
+		for x, num := range row {
+			if layout.Contains(y, x) == (num == -1) {
+				return fmt.Errorf("%w: cell (%d, %d) doesn't match the %s layout", ErrInvalidGrid, y+1, x+1, layout)
+			}
+			if num < -1 || layout.size < num {
+				return fmt.Errorf("%w: cell (%d, %d) holds %d", ErrInvalidGrid, y+1, x+1, num)
+			}
+		}
+	}
+	return nil
+}
+
+//Conflicts returns the filled cells of the puzzle's grid holding a digit found again in one of their rows, columns, boxes,
+//jigsaw regions, diagonals or windows, in reading order
+func (s *SamuraiSudoku) Conflicts() []Cell {
+	grid := s.Grid()
+	conflicting := make(map[Cell]bool)
+	for _, u := range allUnits(s.Layout(), s.variant) {
+		first := make(map[int]Cell)
+		for _, c := range u.cells {
+			num := grid[c.Row][c.Column]
+			if num <= 0 {
+				continue
+			}
+			if d, ok := first[num]; ok {
+				conflicting[d], conflicting[c] = true, true
+			} else {
+				first[num] = c
+			}
+		}
+	}
+	return readingOrder(conflicting)
+}
+
+//Peers returns the cells sharing a row, column, box, jigsaw region, diagonal or window with cell c in any sub-sudoku,
+//in reading order, none for gaps
+func (s *SamuraiSudoku) Peers(c Cell) []Cell {
+	peers := make(map[Cell]bool)
+	for _, u := range allUnits(s.Layout(), s.variant) {
+		for _, d := range u.cells {
+			if d == c {
+				for _, e := range u.cells {
+					peers[e] = true
+				}
+				break
+			}
+		}
+	}
+	delete(peers, c)
+	return readingOrder(peers)
+}
+
+//readingOrder returns the cells of a set row by row, from left to right
+func readingOrder(cells map[Cell]bool) []Cell {
+	if len(cells) == 0 {
+		return nil
+	}
+	list := make([]Cell, 0, len(cells))
+	for c := range cells {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Row != list[j].Row {
+			return list[i].Row < list[j].Row
+		}
+		return list[i].Column < list[j].Column
+	})
+	return list
+}
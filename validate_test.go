@@ -0,0 +1,94 @@
+package sudoku
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(g Grid)
+		want   error
+	}{
+		{"valid", func(g Grid) {}, nil},
+		{"row", func(g Grid) { g[0][0] = 5 }, &ConflictError{Position: TopLeft, Unit: "row", Index: 0, Num: 5}},
+		{"column", func(g Grid) { g[8][0] = 4 }, &ConflictError{Position: TopLeft, Unit: "column", Index: 0, Num: 4}},
+		{"box", func(g Grid) { g[0][0] = 9 }, &ConflictError{Position: TopLeft, Unit: "box", Index: 0, Num: 9}},
+		// the top right corner of the centre is shared with the top right sub-sudoku
+		{"overlap", func(g Grid) { g[6][13] = 6 }, &ConflictError{Position: TopRight, Unit: "row", Index: 6, Num: 6}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			grid := SamuraiGridFromFile("sudoku.txt")
+			tc.modify(grid)
+			err := Validate(grid)
+			if tc.want == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var conflict *ConflictError
+			if !errors.As(err, &conflict) || *conflict != *tc.want.(*ConflictError) {
+				t.Fatalf("want %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateShape(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(g Grid) Grid
+	}{
+		{"missing row", func(g Grid) Grid { return g[1:] }},
+		{"short row", func(g Grid) Grid { g[3] = g[3][1:]; return g }},
+		{"filled gap", func(g Grid) Grid { g[0][10] = 0; return g }},
+		{"missing cell", func(g Grid) Grid { g[0][0] = -1; return g }},
+		{"out of range", func(g Grid) Grid { g[0][0] = 10; return g }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			grid := tc.modify(SamuraiGridFromFile("sudoku.txt"))
+			if err := Validate(grid); !errors.Is(err, ErrInvalidGrid) {
+				t.Fatalf("want %v, got %v", ErrInvalidGrid, err)
+			}
+		})
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	var samurai SamuraiSudoku
+	grid := SamuraiGridFromFile("sudoku.txt")
+	samurai.SetGrid(grid)
+	if got := samurai.Conflicts(); got != nil {
+		t.Fatalf("want no conflicts, got %v", got)
+	}
+
+	// 5 is in the first row at r1c3 and the first column at r7c1, 9 in the first box at r2c2
+	grid[0][0], grid[0][1] = 5, 9
+	want := []Cell{{0, 0}, {0, 1}, {0, 2}, {1, 1}, {6, 0}}
+	if got := samurai.Conflicts(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestPeers(t *testing.T) {
+	var samurai SamuraiSudoku
+	samurai.SetGrid(NewSamuraiGrid())
+	// a corner cell has 8 cells in its row, 8 in its column and 4 more in its box
+	if got := samurai.Peers(Cell{0, 0}); len(got) != 20 || got[0] != (Cell{0, 1}) || got[19] != (Cell{8, 0}) {
+		t.Fatalf("want the 20 peers of r1c1 in reading order, got %v", got)
+	}
+	// a cell of an overlap has the peers of both sub-sudokus, 6 more in the Centre's row and 6 in its column as the box is shared
+	if got := samurai.Peers(Cell{6, 6}); len(got) != 20+12 {
+		t.Fatalf("want 32 peers of r7c7, got %d", len(got))
+	}
+	if got := samurai.Peers(Cell{0, 10}); got != nil {
+		t.Fatalf("want no peers for a gap, got %v", got)
+	}
+}
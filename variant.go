@@ -0,0 +1,223 @@
+package sudoku
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// Variant holds the rules a puzzle adds to those of its layout's sub-sudokus.
+// The zero Variant, like a nil one, adds no rule
+type Variant struct {
+	Diagonals    []Position    // Sub-sudokus whose two main diagonals must hold every digit once, as in Sudoku-X
+	Cages        []Cage        // Killer cages, whose digits must add up to their sum without repeating
+	Jigsaws      []Jigsaw      // Irregular regions replacing the boxes of some sub-sudokus
+	Windows      []Position    // Sub-sudokus whose windows must hold every digit once, as in hyper sudoku or windoku
+	Markers      []Marker      // Relations between the digits of adjacent cells, such as Kropki dots or XV sums
+	Negative     []MarkerKind  // Kinds of markers given wherever their relation holds, adjacent cells without a marker must not have it
+	Constraints  []Constraint  // Further rules on some cells, such as thermometers, arrows and German whispers lines
+	Restrictions []Restriction // Cells that may only hold some digits, such as the even and odd cells of parity sudoku
+}
+
+//IsZero tells if the variant adds no rule
+func (v *Variant) IsZero() bool {
+	return v == nil || len(v.Diagonals) == 0 && len(v.Cages) == 0 && len(v.Jigsaws) == 0 && len(v.Windows) == 0 &&
+		len(v.Markers) == 0 && len(v.Negative) == 0 && len(v.Constraints) == 0 &&
+		len(v.Restrictions) == 0
+}
+
+//diagonal tells if the diagonals of position's sub-sudoku must hold every digit once
+func (v *Variant) diagonal(position Position) bool {
+	return v != nil && hasPosition(v.Diagonals, position)
+}
+
+//window tells if the windows of position's sub-sudoku must hold every digit once
+func (v *Variant) window(position Position) bool {
+	return v != nil && hasPosition(v.Windows, position)
+}
+
+func hasPosition(positions []Position, position Position) bool {
+	for _, p := range positions {
+		if p == position {
+			return true
+		}
+	}
+	return false
+}
+
+//regions returns the jigsaw regions of position's sub-sudoku, nil if it has boxes
+func (v *Variant) regions(position Position) Grid {
+	if v == nil {
+		return nil
+	}
+	for _, j := range v.Jigsaws {
+		if j.Position == position {
+			return j.Regions
+		}
+	}
+	return nil
+}
+
+//check returns an error wrapping ErrInvalidLayout if the variant has rules for sub-sudokus layout doesn't have
+func (v *Variant) check(layout *Layout) error {
+	if v == nil {
+		return nil
+	}
+	if err := checkPositions(v.Diagonals, layout, "diagonals"); err != nil {
+		return err
+	}
+	if err := checkPositions(v.Windows, layout, "windows"); err != nil {
+		return err
+	}
+	if err := checkJigsaws(v.Jigsaws, layout); err != nil {
+		return err
+	}
+	if err := checkMarkers(v.Markers, v.Negative, layout); err != nil {
+		return err
+	}
+	if err := checkConstraints(v.Constraints, layout); err != nil {
+		return err
+	}
+	if err := checkRestrictions(v.Restrictions, layout); err != nil {
+		return err
+	}
+	return checkCages(v.Cages, layout)
+}
+
+//checkPositions returns an error wrapping ErrInvalidLayout if positions, whose sub-sudokus have the rule named rule,
+//holds one layout doesn't have or one twice
+func checkPositions(positions []Position, layout *Layout, rule string) error {
+	seen := make(map[Position]bool)
+	for _, p := range positions {
+		if _, _, ok := layout.Offset(p); !ok {
+			return fmt.Errorf("%w: %s has no %s sub-sudoku for %s", ErrInvalidLayout, layout, p, rule)
+		}
+		if seen[p] {
+			return fmt.Errorf("%w: %s of the %s sub-sudoku given twice", ErrInvalidLayout, rule, p)
+		}
+		seen[p] = true
+	}
+	return nil
+}
+
+//units returns the units the variant adds to layout's: the two diagonals of every sub-sudoku in Diagonals,
+//the one going down to the right first, the windows of every sub-sudoku in Windows, then the regions of every jigsaw
+func (v *Variant) units(layout *Layout) []unit {
+	if v == nil {
+		return nil
+	}
+	var units []unit
+	for _, p := range v.Diagonals {
+		y0, x0, _ := layout.Offset(p)
+		down := unit{position: p, kind: "diagonal", index: 0}
+		up := unit{position: p, kind: "diagonal", index: 1}
+		for i := 0; i < layout.size; i++ {
+			down.cells = append(down.cells, Cell{y0 + i, x0 + i})
+			up.cells = append(up.cells, Cell{y0 + layout.size - 1 - i, x0 + i})
+		}
+		units = append(units, down, up)
+	}
+	for _, p := range v.Windows {
+		y0, x0, _ := layout.Offset(p)
+		for i, w := range windows(layout.box) {
+			window := unit{position: p, kind: "window", index: i}
+			for y := w.Min.Y; y < w.Max.Y; y++ {
+				for x := w.Min.X; x < w.Max.X; x++ {
+					window.cells = append(window.cells, Cell{y0 + y, x0 + x})
+				}
+			}
+			units = append(units, window)
+		}
+	}
+	for _, j := range v.Jigsaws {
+		y0, x0, _ := layout.Offset(j.Position)
+		units = append(units, regionUnits(j, y0, x0)...)
+	}
+	return units
+}
+
+//allUnits returns layout's units followed by the ones variant adds, leaving out the boxes of sub-sudokus with jigsaw regions
+func allUnits(layout *Layout, variant *Variant) []unit {
+	extra := variant.units(layout)
+	if extra == nil {
+		return layout.units
+	}
+	units := make([]unit, 0, len(layout.units)+len(extra))
+	for _, u := range layout.units {
+		if u.kind == "box" && variant.regions(u.position) != nil {
+			continue
+		}
+		units = append(units, u)
+	}
+	return append(units, extra...)
+}
+
+//possibleDiagonals checks if sudoku can be filled in position y,x with n without repeating a digit on the diagonals going through it
+func possibleDiagonals(sudoku Grid, y int, x int, n int) bool {
+	last := len(sudoku) - 1
+	for i := range sudoku {
+		if y == x && sudoku[i][i] == n {
+			return false
+		}
+		if y == last-x && sudoku[last-i][i] == n {
+			return false
+		}
+	}
+	return true
+}
+
+//windows returns the windows of a sub-sudoku made of boxes shaped like box, as rectangles of its cells ordered row by row.
+//Windows are shaped like boxes, one cell in from the sides of the sub-sudoku and one cell apart, e.g. four for 9x9 sub-sudokus
+func windows(box Box) []image.Rectangle {
+	size := box.Size()
+	var rects []image.Rectangle
+	for y := 1; y+box.Rows < size; y += box.Rows + 1 {
+		for x := 1; x+box.Columns < size; x += box.Columns + 1 {
+			rects = append(rects, image.Rect(x, y, x+box.Columns, y+box.Rows))
+		}
+	}
+	return rects
+}
+
+//possibleWindows checks if sudoku, made of boxes shaped like box, can be filled in position y,x with n without repeating a digit
+//in the window it is in, if any
+func possibleWindows(sudoku Grid, box Box, y int, x int, n int) bool {
+	for _, w := range windows(box) {
+		if !image.Pt(x, y).In(w) {
+			continue
+		}
+		for i := w.Min.Y; i < w.Max.Y; i++ {
+			for j := w.Min.X; j < w.Max.X; j++ {
+				if sudoku[i][j] == n {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+//formatPositions writes positions for a header line, with dashes between words, e.g. "top-left, centre"
+func formatPositions(positions []Position) string {
+	names := make([]string, len(positions))
+	for i, p := range positions {
+		names[i] = strings.ReplaceAll(p.String(), " ", "-")
+	}
+	return strings.Join(names, ", ")
+}
+
+//parsePositions parses a header line's comma separated positions, "all" standing for every sub-sudoku of layout
+func parsePositions(value string, layout *Layout) ([]Position, error) {
+	if strings.EqualFold(strings.TrimSpace(value), "all") {
+		return layout.Positions(), nil
+	}
+	var positions []Position
+	for _, name := range strings.Split(value, ",") {
+		p, err := ParsePosition(name)
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, p)
+	}
+	return positions, nil
+}
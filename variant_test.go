@@ -0,0 +1,210 @@
+package sudoku
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+//checkVariantSolves checks that puzzle has a unique solution under variant's rules, then that every solver fills it in
+//following them. check, if not nil, then makes the checks particular to the variant on the solved samurai
+func checkVariantSolves(t *testing.T, puzzle Grid, variant *Variant, check func(t *testing.T, samurai *SamuraiSudoku)) {
+	t.Helper()
+	for name, solve := range Solvers {
+		t.Run(name, func(t *testing.T) {
+			var samurai SamuraiSudoku
+			if err := samurai.SetVariant(variant); err != nil {
+				t.Fatal(err)
+			}
+			samurai.SetGrid(copyGrid(puzzle))
+			if count, err := samurai.CountSolutions(context.Background(), 2); err != nil || count != 1 {
+				t.Fatalf("want a unique solution, got %d, %v", count, err)
+			}
+			solution, err := solve(context.Background(), &samurai)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !solution.isSolved() {
+				t.Fatalf("solution has empty cells:\n%v", solution)
+			}
+			if err := samurai.Validate(); err != nil {
+				t.Fatalf("invalid solution: %v", err)
+			}
+			if check != nil {
+				check(t, &samurai)
+			}
+		})
+	}
+}
+
+//centreRowsPuzzle returns sudoku.txt with the middle rows of the Centre sub-sudoku cleared from column 6 up to x1, along with
+//its solution for variants to draw their rules from
+func centreRowsPuzzle(t *testing.T, x1 int) (Grid, Grid) {
+	solution, err := GlobalSolveSamuraiSudokuContext(context.Background(), newTestSamurai())
+	if err != nil {
+		t.Fatal(err)
+	}
+	puzzle := SamuraiGridFromFile("sudoku.txt")
+	for y := 9; y < 12; y++ {
+		for x := 6; x < x1; x++ {
+			puzzle[y][x] = 0
+		}
+	}
+	return puzzle, solution
+}
+
+func TestDiagonals(t *testing.T) {
+	variant := &Variant{Diagonals: Samurai.Positions()}
+	puzzle, err := GenerateSamuraiSudokuContext(context.Background(), GenerateOptions{Seed: 2026, MinClues: 250, Variant: variant})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkVariantSolves(t, puzzle, variant, func(t *testing.T, samurai *SamuraiSudoku) {
+		for _, p := range variant.Diagonals {
+			sudoku := samurai.GetSubSudoku(p)
+			down, up := make(map[int]bool), make(map[int]bool)
+			for i := range sudoku {
+				down[sudoku[i][i]] = true
+				up[sudoku[8-i][i]] = true
+			}
+			if len(down) != 9 || len(up) != 9 {
+				t.Fatalf("the diagonals of the %s sub-sudoku repeat digits:\n%v", p, sudoku)
+			}
+		}
+	})
+}
+
+func TestDiagonalConflict(t *testing.T) {
+	grid := NewSamuraiGrid()
+	// the centre's top left and bottom right corners, on the same diagonal but in different rows, columns and boxes
+	grid[6][6], grid[14][14] = 5, 5
+	if err := Validate(grid); err != nil {
+		t.Fatalf("want the grid valid without diagonals, got %v", err)
+	}
+
+	var samurai SamuraiSudoku
+	if err := samurai.SetVariant(&Variant{Diagonals: []Position{Centre}}); err != nil {
+		t.Fatal(err)
+	}
+	samurai.SetGrid(grid)
+	var conflict *ConflictError
+	if err := samurai.Validate(); !errors.As(err, &conflict) || conflict.Unit != "diagonal" || conflict.Index != 0 || conflict.Position != Centre {
+		t.Fatalf("want a conflict on the centre's first diagonal, got %v", err)
+	}
+
+	// the overlap with the top left sub-sudoku is on the centre's diagonal but away from the 5 left at its bottom right
+	grid[6][6] = 0
+	if possible(samurai.GetSubSudoku(TopLeft), 7, 7, 5, TopLeft, &samurai) {
+		t.Fatalf("want 5 impossible on the centre's diagonal from the top left sub-sudoku")
+	}
+	if !possible(samurai.GetSubSudoku(TopLeft), 7, 8, 5, TopLeft, &samurai) {
+		t.Fatalf("want 5 possible off the centre's diagonals")
+	}
+}
+
+func TestVariantErrors(t *testing.T) {
+	var samurai SamuraiSudoku
+	samurai.SetLayout(Twin)
+	if err := samurai.SetVariant(&Variant{Diagonals: []Position{Centre}}); !errors.Is(err, ErrInvalidLayout) {
+		t.Fatalf("want ErrInvalidLayout for a sub-sudoku twin doesn't have, got %v", err)
+	}
+	if err := samurai.SetVariant(&Variant{Diagonals: []Position{TopLeft, TopLeft}}); !errors.Is(err, ErrInvalidLayout) {
+		t.Fatalf("want ErrInvalidLayout for diagonals given twice, got %v", err)
+	}
+	if err := samurai.SetVariant(&Variant{Diagonals: []Position{TopLeft}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWindows(t *testing.T) {
+	// random solutions take a while to find with windows on both sides of an overlap
+	variant := &Variant{Windows: []Position{TopLeft, BottomRight}}
+	puzzle, err := GenerateSamuraiSudokuContext(context.Background(), GenerateOptions{Seed: 2026, MinClues: 250, Variant: variant})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkVariantSolves(t, puzzle, variant, func(t *testing.T, samurai *SamuraiSudoku) {
+		for _, p := range variant.Windows {
+			sudoku := samurai.GetSubSudoku(p)
+			for _, corner := range [][2]int{{1, 1}, {1, 5}, {5, 1}, {5, 5}} {
+				seen := make(map[int]bool)
+				for i := 0; i < 9; i++ {
+					seen[sudoku[corner[0]+i/3][corner[1]+i%3]] = true
+				}
+				if len(seen) != 9 {
+					t.Fatalf("the window at %v of the %s sub-sudoku repeats digits:\n%v", corner, p, sudoku)
+				}
+			}
+		}
+	})
+}
+
+func TestWindowShapes(t *testing.T) {
+	testCases := []struct {
+		box   Box
+		count int
+	}{
+		{Box{2, 2}, 1},
+		{Box{2, 3}, 1},
+		{ClassicBox, 4},
+		{Box{4, 4}, 9},
+		{Box{5, 5}, 16},
+	}
+	for _, tc := range testCases {
+		got := windows(tc.box)
+		if len(got) != tc.count {
+			t.Fatalf("%s: want %d windows, got %v", tc.box, tc.count, got)
+		}
+		for _, w := range got {
+			if w.Dy() != tc.box.Rows || w.Dx() != tc.box.Columns || w.Min.X < 1 || w.Min.Y < 1 || w.Max.X >= tc.box.Size() || w.Max.Y >= tc.box.Size() {
+				t.Fatalf("%s: window %v isn't a box one cell inside the sub-sudoku", tc.box, w)
+			}
+		}
+	}
+}
+
+func TestWindowConflict(t *testing.T) {
+	grid := NewSamuraiGrid()
+	// the centre's (1, 1) and (3, 3), in the first window but in different rows, columns and boxes
+	grid[7][7], grid[9][9] = 5, 5
+	if err := Validate(grid); err != nil {
+		t.Fatalf("want the grid valid without windows, got %v", err)
+	}
+
+	var samurai SamuraiSudoku
+	if err := samurai.SetVariant(&Variant{Windows: []Position{Centre}}); err != nil {
+		t.Fatal(err)
+	}
+	samurai.SetGrid(grid)
+	var conflict *ConflictError
+	if err := samurai.Validate(); !errors.As(err, &conflict) || conflict.Unit != "window" || conflict.Index != 0 || conflict.Position != Centre {
+		t.Fatalf("want a conflict in the centre's first window, got %v", err)
+	}
+
+	// from the top left sub-sudoku, the cell is in the centre's window but not in a window of its own
+	grid[9][9] = 0
+	if possible(samurai.GetSubSudoku(Centre), 3, 2, 5, Centre, &samurai) {
+		t.Fatalf("want 5 impossible in the centre's first window")
+	}
+	if !possible(samurai.GetSubSudoku(Centre), 4, 0, 5, Centre, &samurai) {
+		t.Fatalf("want 5 possible outside the windows")
+	}
+	if err := samurai.SetVariant(&Variant{Windows: []Position{Centre, Centre}}); !errors.Is(err, ErrInvalidLayout) {
+		t.Fatalf("want ErrInvalidLayout for windows given twice, got %v", err)
+	}
+}
+
+func TestWriteImageWindows(t *testing.T) {
+	samurai := emptyTestSamurai()
+	if err := samurai.SetVariant(&Variant{Windows: []Position{Centre}}); err != nil {
+		t.Fatal(err)
+	}
+	img := pictureImage(t, samurai)
+
+	// the centre's first window starts at its cell (1, 1), canvas cell (7, 7), and is outlined a twelfth of a cell inside it
+	if !hasColour(img, 7.05, 7.5, 7.12, 7.5, imageWindowColour) {
+		t.Fatalf("want the top of the centre's first window outlined")
+	}
+}